@@ -0,0 +1,429 @@
+package fsmgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Finding is a kind of issue reported by Analyze. Findings do not make a definition invalid, but usually indicate a
+// mistake, so Write can be made to fail on them with Generator.FailOn.
+type Finding int
+
+const (
+	// FindingUnreachableState reports states that no sequence of events can reach from the initial state.
+	FindingUnreachableState Finding = iota + 1
+	// FindingUnusedEvent reports events that can never be triggered, as none of their source states is reachable.
+	FindingUnusedEvent
+	// FindingSinkState reports states that are not final but have no outgoing transition, where the machine deadlocks.
+	FindingSinkState
+	// FindingShadowedEvent reports events from any state that are overridden by a declaration on specific states
+	// that is not marked as an override, and event declarations that never apply, as every state they are declared
+	// from is overridden by a more specific declaration.
+	FindingShadowedEvent
+)
+
+var findingNames = map[Finding]string{
+	FindingUnreachableState: "unreachable_state",
+	FindingUnusedEvent:      "unused_event",
+	FindingSinkState:        "sink_state",
+	FindingShadowedEvent:    "shadowed_event",
+}
+
+func (f Finding) String() string {
+	if name, ok := findingNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseFinding returns the Finding with the supplied name: "unreachable_state", "unused_event", "sink_state" or
+// "shadowed_event".
+func ParseFinding(name string) (Finding, error) {
+	for f, str := range findingNames {
+		if str == name {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown finding %q", name)
+}
+
+// ShadowedEvent describes a declaration of an event that does not apply in some states, because the event is also
+// declared on them and the more specific declaration takes precedence. A declaration from any state is shadowed by the
+// declarations that are not marked with Event.Overrides, and any declaration is shadowed if it never applies, as
+// overrides are expected to replace a declaration in some of its states, not all of them.
+type ShadowedEvent struct {
+	Event string
+	// States are the states the declaration is overridden in, or the states it is declared from if it never applies.
+	States []string
+	// Never is set if the declaration never applies, as the event is declared on every leaf state below its source
+	// states, or on one of their ancestors below them.
+	Never bool
+}
+
+// Report is the result of statically analysing a Generator definition. States and events are listed in declaration
+// order.
+type Report struct {
+	// UnreachableStates are the states that no sequence of events can reach from the initial state.
+	UnreachableStates []string
+	// UnusedEvents are the events that can never be triggered, as none of their source states is reachable.
+	UnusedEvents []string
+	// SinkStates are the leaf states that are not final and that no transition leaves, from the state itself or any of
+	// its ancestors.
+	SinkStates []string
+	// ShadowedEvents are the events from any state that are overridden in specific states without being marked as an
+	// override, and the event declarations that are overridden in every state they are declared from.
+	ShadowedEvents []ShadowedEvent
+	// Components are the strongly connected components of the transition graph, which has an edge from every source
+	// state of an event, and each of its descendants, to each of its targets. States in the same component can each
+	// reach the other.
+	Components [][]string
+}
+
+// Problems returns a problem for every finding of the supplied kinds, or of every kind if none are supplied.
+func (r *Report) Problems(kinds ...Finding) []*Problem {
+	selected := map[Finding]bool{}
+	for _, kind := range kinds {
+		selected[kind] = true
+	}
+	include := func(kind Finding) bool {
+		return len(kinds) == 0 || selected[kind]
+	}
+	problems := []*Problem{}
+	if include(FindingUnreachableState) {
+		for _, state := range r.UnreachableStates {
+			problems = append(problems, &Problem{State: state, Message: "state is unreachable from the initial state"})
+		}
+	}
+	if include(FindingSinkState) {
+		for _, state := range r.SinkStates {
+			problems = append(problems, &Problem{State: state, Message: "state is not final but has no outgoing transitions"})
+		}
+	}
+	if include(FindingUnusedEvent) {
+		for _, event := range r.UnusedEvents {
+			message := "event can never be triggered, as none of its source states is reachable"
+			problems = append(problems, &Problem{Event: event, Message: message})
+		}
+	}
+	if include(FindingShadowedEvent) {
+		for _, shadowed := range r.ShadowedEvents {
+			message := fmt.Sprintf("event from any state is overridden in %s", strings.Join(shadowed.States, ", "))
+			if shadowed.Never {
+				from := "any state"
+				if len(shadowed.States) > 0 {
+					from = strings.Join(shadowed.States, ", ")
+				}
+				message = fmt.Sprintf("declaration from %s never applies, as it is overridden in every state", from)
+			}
+			problems = append(problems, &Problem{Event: shadowed.Event, Message: message})
+		}
+	}
+	return problems
+}
+
+// AnalysisError is returned by Write when Analyze reports a finding of a kind listed in Generator.FailOn.
+type AnalysisError struct {
+	Name     string
+	Problems []*Problem
+}
+
+func (err *AnalysisError) Error() string {
+	lines := make([]string, 0, len(err.Problems)+1)
+	lines = append(lines, fmt.Sprintf("state machine %q failed analysis: %d finding(s)", err.Name, len(err.Problems)))
+	for _, problem := range err.Problems {
+		lines = append(lines, "\t"+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Analyze statically analyses the state machine definition, reporting unreachable states, unused events, sink states,
+// shadowed event declarations and the strongly connected components of the transition graph. The definition should be valid.
+func (gen *Generator) Analyze() *Report {
+	report := &Report{}
+	reachable := gen.reachable()
+	for _, state := range gen.States {
+		if !reachable[state] {
+			report.UnreachableStates = append(report.UnreachableStates, state)
+		}
+	}
+	report.UnusedEvents = gen.unusedEvents(reachable)
+	report.SinkStates = gen.sinkStates()
+	report.ShadowedEvents = gen.shadowedEvents()
+	report.Components = gen.components()
+	return report
+}
+
+// entryClosure adds the supplied state to states along with every state active while it is: its ancestors, and the
+// descendants entered by default along with it.
+func (gen *Generator) entryClosure(states map[string]bool, state string) {
+	for _, ancestor := range gen.ancestors(state) {
+		states[ancestor] = true
+	}
+	var enter func(state string)
+	enter = func(state string) {
+		if states[state] {
+			return
+		}
+		states[state] = true
+		children := gen.Substates[state]
+		if gen.ParallelStates[state] {
+			for _, region := range children {
+				enter(region)
+			}
+		} else if len(children) > 0 {
+			enter(children[0])
+		}
+	}
+	enter(state)
+}
+
+// targets returns every target state of the event, with history pseudo-states replaced by their default states.
+func (gen *Generator) targets(event *Event) []string {
+	defaults := map[string]string{}
+	for _, history := range gen.Histories {
+		defaults[history.Name] = history.Default
+	}
+	targets := []string{}
+	for _, branch := range event.Branches {
+		targets = append(targets, branch.ToState)
+	}
+	if event.ToState != "" {
+		targets = append(targets, event.ToState)
+	}
+	for i, target := range targets {
+		if state, ok := defaults[target]; ok {
+			targets[i] = state
+		}
+	}
+	return targets
+}
+
+// reachable returns the states that may be active after some sequence of events. History pseudo-states only restore
+// states that were reachable before, so they are treated as targeting their default state.
+func (gen *Generator) reachable() map[string]bool {
+	reachable := map[string]bool{}
+	if len(gen.States) == 0 {
+		return reachable
+	}
+	gen.entryClosure(reachable, gen.States[0])
+	for changed := true; changed; {
+		changed = false
+		for _, event := range gen.Events {
+			if !gen.fromReachable(event, reachable) {
+				continue
+			}
+			for _, target := range gen.targets(event) {
+				if !reachable[target] {
+					gen.entryClosure(reachable, target)
+					changed = true
+				}
+			}
+		}
+	}
+	return reachable
+}
+
+// fromReachable returns whether any of the event's source states is reachable, which is always the case for events
+// from any state.
+func (gen *Generator) fromReachable(event *Event, reachable map[string]bool) bool {
+	if len(event.FromStates) == 0 {
+		return true
+	}
+	for _, state := range event.FromStates {
+		if reachable[state] {
+			return true
+		}
+	}
+	return false
+}
+
+// unusedEvents returns the names of the events without a declaration that has a reachable source state.
+func (gen *Generator) unusedEvents(reachable map[string]bool) []string {
+	used := map[string]bool{}
+	names := []string{}
+	for _, event := range gen.Events {
+		if _, ok := used[event.Name]; !ok {
+			names = append(names, event.Name)
+		}
+		used[event.Name] = used[event.Name] || gen.fromReachable(event, reachable)
+	}
+	unused := []string{}
+	for _, name := range names {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	return unused
+}
+
+// sinkStates returns the leaf states that are not final and have no outgoing transition from themselves or any of
+// their ancestors. Internal transitions never leave a state, and no state is a sink if any other event may occur from
+// any state.
+func (gen *Generator) sinkStates() []string {
+	sources := map[string]bool{}
+	for _, event := range gen.Events {
+		if event.InternalTransition {
+			continue
+		}
+		if len(event.FromStates) == 0 {
+			return nil
+		}
+		for _, state := range event.FromStates {
+			sources[state] = true
+		}
+	}
+	sinks := []string{}
+	for _, state := range gen.States {
+		if len(gen.Substates[state]) > 0 || gen.FinalStates[state] || sources[state] {
+			continue
+		}
+		left := false
+		for _, ancestor := range gen.ancestors(state) {
+			left = left || sources[ancestor]
+		}
+		if !left {
+			sinks = append(sinks, state)
+		}
+	}
+	return sinks
+}
+
+// shadowedEvents returns the events declared from any state that are also declared on specific states without being
+// marked as overrides, and the event declarations that no leaf state takes its transition from, as a more specific
+// declaration of the event is found first from each of them.
+func (gen *Generator) shadowedEvents() []ShadowedEvent {
+	shadowed := []ShadowedEvent{}
+	for _, event := range gen.Events {
+		if !gen.applies(event) {
+			shadowed = append(shadowed, ShadowedEvent{Event: event.Name, States: event.FromStates, Never: true})
+			continue
+		}
+		if len(event.FromStates) != 0 {
+			continue
+		}
+		states := []string{}
+		for _, other := range gen.Events {
+			if other.Name == event.Name && !other.Override {
+				states = append(states, other.FromStates...)
+			}
+		}
+		if len(states) > 0 {
+			shadowed = append(shadowed, ShadowedEvent{Event: event.Name, States: states})
+		}
+	}
+	return shadowed
+}
+
+// applies returns whether any leaf state takes its transition for the event from the supplied declaration.
+func (gen *Generator) applies(event *Event) bool {
+	for _, state := range gen.States {
+		if len(gen.Substates[state]) == 0 && gen.declaration(event.Name, state) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// declaration returns the declaration of the named event that the supplied leaf state takes its transition from,
+// looking on the state, then on each of its ancestors, innermost first, and finally on the declarations from any
+// state.
+func (gen *Generator) declaration(name, leaf string) *Event {
+	for _, state := range append([]string{leaf}, gen.ancestors(leaf)...) {
+		for _, event := range gen.Events {
+			if event.Name != name {
+				continue
+			}
+			for _, from := range event.FromStates {
+				if from == state {
+					return event
+				}
+			}
+		}
+	}
+	for _, event := range gen.Events {
+		if event.Name == name && len(event.FromStates) == 0 {
+			return event
+		}
+	}
+	return nil
+}
+
+// components returns the strongly connected components of the transition graph using Tarjan's algorithm. Components
+// are ordered by their first state, and the states of each component are in declaration order.
+func (gen *Generator) components() [][]string {
+	index := map[string]int{}
+	for i, state := range gen.States {
+		index[state] = i
+	}
+	edges := map[string][]string{}
+	for _, event := range gen.Events {
+		from := map[string]bool{}
+		for _, state := range event.FromStates {
+			from[state] = true
+		}
+		for _, state := range gen.States {
+			left := len(event.FromStates) == 0 || from[state]
+			for _, ancestor := range gen.ancestors(state) {
+				left = left || from[ancestor]
+			}
+			if !left {
+				continue
+			}
+			for _, target := range gen.targets(event) {
+				if _, ok := index[target]; ok {
+					edges[state] = append(edges[state], target)
+				}
+			}
+		}
+	}
+
+	components := [][]string{}
+	order := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	var connect func(state string)
+	connect = func(state string) {
+		order[state] = len(order)
+		low[state] = order[state]
+		stack = append(stack, state)
+		onStack[state] = true
+		for _, next := range edges[state] {
+			if _, visited := order[next]; !visited {
+				connect(next)
+				if low[next] < low[state] {
+					low[state] = low[next]
+				}
+			} else if onStack[next] && order[next] < low[state] {
+				low[state] = order[next]
+			}
+		}
+		if low[state] != order[state] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == state {
+				break
+			}
+		}
+		sort.Slice(component, func(i, j int) bool {
+			return index[component[i]] < index[component[j]]
+		})
+		components = append(components, component)
+	}
+	for _, state := range gen.States {
+		if _, visited := order[state]; !visited {
+			connect(state)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return index[components[i][0]] < index[components[j][0]]
+	})
+	return components
+}
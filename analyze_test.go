@@ -0,0 +1,123 @@
+package fsmgen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	gen := New("analyze", testState{}, testEnv{}, "init", "running", "stuck", "orphan", "done")
+	gen.Final("done")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").To("running"))
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("running").To("init"))
+	gen.AddEvent(NewEvent("jam", testEvent{}).From("running").To("stuck"))
+	gen.AddEvent(NewEvent("finish", testEvent{}).From("running").To("done"))
+	gen.AddEvent(NewEvent("adopt", testEvent{}).From("orphan").To("init"))
+	assert.NilError(t, gen.Validate())
+
+	report := gen.Analyze()
+	assert.DeepEqual(t, []string{"orphan"}, report.UnreachableStates)
+	assert.DeepEqual(t, []string{"adopt"}, report.UnusedEvents)
+	assert.DeepEqual(t, []string{"stuck"}, report.SinkStates)
+	assert.DeepEqual(t, []ShadowedEvent{}, report.ShadowedEvents)
+	assert.DeepEqual(t, [][]string{{"init", "running"}, {"stuck"}, {"orphan"}, {"done"}}, report.Components)
+	assert.DeepEqual(t, []*Problem{
+		{State: "stuck", Message: "state is not final but has no outgoing transitions"},
+	}, report.Problems(FindingSinkState))
+}
+
+func TestAnalyzeHierarchy(t *testing.T) {
+	gen := New("analyze", testState{}, testEnv{}, "off", "on", "idle", "busy")
+	gen.AddSubstates("on", "idle", "busy")
+	gen.ShallowHistory("hist", "on", "busy")
+	gen.AddEvent(NewEvent("start", testEvent{}).From("off").To("hist"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("on").To("off"))
+	assert.NilError(t, gen.Validate())
+
+	report := gen.Analyze()
+	assert.DeepEqual(t, []string{"idle"}, report.UnreachableStates)
+	assert.Equal(t, 0, len(report.UnusedEvents))
+	assert.Equal(t, 0, len(report.SinkStates))
+	assert.DeepEqual(t, [][]string{{"off", "busy"}, {"on"}, {"idle"}}, report.Components)
+}
+
+func TestAnalyzeFromAny(t *testing.T) {
+	gen := New("analyze", testState{}, testEnv{}, "init", "running")
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("init"))
+	assert.NilError(t, gen.Validate())
+
+	report := gen.Analyze()
+	assert.DeepEqual(t, []string{"running"}, report.UnreachableStates)
+	assert.Equal(t, 0, len(report.SinkStates))
+}
+
+func TestAnalyzeShadowedEvents(t *testing.T) {
+	gen := New("analyze", testState{}, testEnv{}, "idle", "active", "playing", "paused")
+	gen.AddSubstates("active", "playing", "paused")
+	gen.AddEvent(NewEvent("play", testEvent{}).From("idle").To("active"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).FromAny().To("idle"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("active").Guard("unlocked").To("idle").Overrides())
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("active").To("paused"))
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("playing").To("paused").Overrides())
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("paused").To("playing").Overrides())
+	assert.NilError(t, gen.Validate())
+
+	// Marked overrides that replace a declaration in only some of its states are not findings, but a declaration
+	// overridden in every state it is declared from never applies.
+	report := gen.Analyze()
+	assert.DeepEqual(t, []ShadowedEvent{{Event: "pause", States: []string{"active"}, Never: true}}, report.ShadowedEvents)
+	assert.DeepEqual(t, []*Problem{
+		{Event: "pause", Message: "declaration from active never applies, as it is overridden in every state"},
+	}, report.Problems(FindingShadowedEvent))
+
+	// Declarations overriding an event from any state without being marked are still reported.
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("idle"))
+	gen.AddEvent(NewEvent("reset", testEvent{}).From("playing").To("active"))
+	report = gen.Analyze()
+	assert.DeepEqual(t, []*Problem{
+		{Event: "pause", Message: "declaration from active never applies, as it is overridden in every state"},
+		{Event: "reset", Message: "event from any state is overridden in playing"},
+	}, report.Problems(FindingShadowedEvent))
+}
+
+func TestWriteFailOn(t *testing.T) {
+	dir := t.TempDir()
+	gen := New("analyze", testState{}, testEnv{}, "init", "running", "orphan")
+	gen.Filename = filepath.Join(dir, "analyze.go")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").To("running"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("running").To("init"))
+	gen.FailOn = []Finding{FindingUnusedEvent}
+	assert.NilError(t, gen.Write())
+
+	gen.FailOn = []Finding{FindingUnreachableState, FindingSinkState}
+	assert.NilError(t, os.Remove(gen.Filename))
+	err := gen.Write()
+	var aerr *AnalysisError
+	assert.Assert(t, errors.As(err, &aerr))
+	assert.Error(t, err, `state machine "analyze" failed analysis: 2 finding(s)
+	state "orphan": state is unreachable from the initial state
+	state "orphan": state is not final but has no outgoing transitions`)
+	_, err = os.Stat(gen.Filename)
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestSpecFailOn(t *testing.T) {
+	gen, err := (&Spec{Name: "door", FailOn: []string{"sink_state", "unused_event"}}).Generator()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []Finding{FindingSinkState, FindingUnusedEvent}, gen.FailOn)
+	_, err = (&Spec{Name: "door", FailOn: []string{"typo"}}).Generator()
+	assert.Error(t, err, `unknown finding "typo"`)
+}
+
+func TestAnalyzeInternal(t *testing.T) {
+	gen := New("analyze", testState{}, testEnv{}, "idle", "playing")
+	gen.AddEvent(NewEvent("play", testEvent{}).From("idle").To("playing"))
+	gen.AddEvent(NewEvent("seek", testEvent{}).From("playing").Internal())
+	gen.AddEvent(NewEvent("volume", testEvent{}).FromAny().Internal())
+	assert.NilError(t, gen.Validate())
+	assert.DeepEqual(t, []string{"playing"}, gen.Analyze().SinkStates)
+}
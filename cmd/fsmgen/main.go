@@ -0,0 +1,70 @@
+// Command fsmgen generates a state machine from a YAML or JSON specification, without a hand-written generator program.
+//
+//	//go:generate go run github.com/snikch/go-fsmgen/cmd/fsmgen -spec player.yaml
+//
+// The package name defaults to $GOPACKAGE, as set by go generate, and relative filenames in the spec are resolved
+// against the directory containing the spec. The -o flag overrides the generated file's name, relative to the current
+// directory.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/snikch/go-fsmgen"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("fsmgen: ")
+	specFile := flag.String("spec", "", "path to the YAML or JSON machine specification")
+	output := flag.String("o", "", "output file, overriding the spec's filename")
+	flag.Parse()
+	if *specFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	gen, err := generator(*specFile, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = gen.Write()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generator returns the generator for the spec in specFile, resolving the spec's relative filenames against its
+// directory. A non-empty output replaces the generated file's name, and is used as given.
+func generator(specFile, output string) (*fsmgen.Generator, error) {
+	spec, err := fsmgen.ReadSpec(specFile)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Package == "" {
+		spec.Package = os.Getenv("GOPACKAGE")
+	}
+	gen, err := spec.Generator()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(specFile)
+	gen.Filename = resolve(dir, gen.Filename)
+	gen.DOTFilename = resolve(dir, gen.DOTFilename)
+	gen.MermaidFilename = resolve(dir, gen.MermaidFilename)
+	if output != "" {
+		gen.Filename = output
+	}
+	return gen, nil
+}
+
+// resolve returns filename relative to dir, unless it is empty or already absolute.
+func resolve(dir, filename string) string {
+	if filename == "" || filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(dir, filename)
+}
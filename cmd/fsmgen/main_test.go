@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGeneratorFilenames(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "door.yaml")
+	assert.NilError(t, ioutil.WriteFile(specFile, []byte(`
+name: door
+package: doors
+state: Door
+environment: Env
+states: [closed, open]
+filename: door.go
+dot_filename: door.dot
+events:
+  - name: open
+    type: Open
+    from: [closed]
+    to: open
+`), 0644))
+
+	gen, err := generator(specFile, "")
+	assert.NilError(t, err)
+	assert.Equal(t, filepath.Join(dir, "door.go"), gen.Filename)
+	assert.Equal(t, filepath.Join(dir, "door.dot"), gen.DOTFilename)
+	assert.Equal(t, "", gen.MermaidFilename)
+
+	// The output flag is relative to the current directory, not the spec's.
+	gen, err = generator(specFile, filepath.Join("out", "door.generated.go"))
+	assert.NilError(t, err)
+	assert.Equal(t, filepath.Join("out", "door.generated.go"), gen.Filename)
+	assert.Equal(t, filepath.Join(dir, "door.dot"), gen.DOTFilename)
+}
+
+func TestGeneratorPackage(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "door.json")
+	assert.NilError(t, ioutil.WriteFile(specFile, []byte(`{
+  "name": "door",
+  "state": "Door",
+  "environment": "Env",
+  "states": ["closed", "open"]
+}`), 0644))
+	t.Setenv("GOPACKAGE", "doors")
+	gen, err := generator(specFile, "")
+	assert.NilError(t, err)
+	assert.Equal(t, "doors", gen.PackageName)
+	assert.Equal(t, filepath.Join(filepath.Dir(specFile), "door.generated.go"), gen.Filename)
+
+	_, err = generator(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	assert.ErrorContains(t, err, "missing.yaml")
+}
@@ -0,0 +1,45 @@
+package fsmgen
+
+import "fmt"
+
+// Concurrency defines how a generated machine synchronises access from multiple goroutines.
+type Concurrency int
+
+const (
+	// ConcurrencyNone generates a machine without any synchronisation, which must only be used from one goroutine at a
+	// time.
+	ConcurrencyNone Concurrency = iota
+	// ConcurrencyMutex generates a machine guarded by a mutex. Triggers may be called from any goroutine and are
+	// processed one at a time, while handlers may still trigger events through their machine context.
+	ConcurrencyMutex
+	// ConcurrencyActor generates a machine owned by a single goroutine running its Run method. Events are sent to the
+	// goroutine with Send or SendAndWait, and the typed triggers wait for their event to be processed.
+	ConcurrencyActor
+)
+
+var concurrencyNames = map[Concurrency]string{
+	ConcurrencyNone:  "none",
+	ConcurrencyMutex: "mutex",
+	ConcurrencyActor: "actor",
+}
+
+func (c Concurrency) String() string {
+	if name, ok := concurrencyNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseConcurrency returns the Concurrency with the supplied name: "none", "mutex" or "actor". An empty name is
+// treated as "none".
+func ParseConcurrency(name string) (Concurrency, error) {
+	if name == "" {
+		return ConcurrencyNone, nil
+	}
+	for c, str := range concurrencyNames {
+		if str == name {
+			return c, nil
+		}
+	}
+	return ConcurrencyNone, fmt.Errorf("unknown concurrency mode %q", name)
+}
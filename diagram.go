@@ -0,0 +1,249 @@
+package fsmgen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// label returns the conventional diagram label for the history pseudo-state.
+func (history *History) label() string {
+	if history.Deep {
+		return "H*"
+	}
+	return "H"
+}
+
+// edge is a single transition drawn in a diagram.
+type edge struct {
+	From  string
+	To    string
+	Event string
+	Guard string
+	After time.Duration
+	// Internal is set for internal transitions, which are drawn as a self-transition but exit and enter no states.
+	Internal bool
+}
+
+// Label returns the edge label, which is the event name followed by the guard in brackets if there is one, the delay
+// of a delayed transition, and whether it is an internal transition.
+func (e edge) Label() string {
+	label := e.Event
+	if e.Guard != "" {
+		label += " [" + e.Guard + "]"
+	}
+	if e.After != 0 {
+		label += " after " + e.After.String()
+	}
+	if e.Internal {
+		label += " (internal)"
+	}
+	return label
+}
+
+// rules returns every declared transition, in declaration order, with an edge per source state and per branch of a
+// guarded event. Events without source states have a single edge from the empty state, and internal transitions
+// target the state they are declared on.
+func (gen *Generator) rules() []edge {
+	edges := []edge{}
+	for _, event := range gen.Events {
+		from := event.FromStates
+		if len(from) == 0 {
+			from = []string{""}
+		}
+		for _, state := range from {
+			if event.InternalTransition {
+				edges = append(edges, edge{From: state, To: state, Event: event.Name, Guard: event.ToGuard, After: event.Delay, Internal: true})
+				continue
+			}
+			for _, branch := range event.Branches {
+				edges = append(edges, edge{From: state, To: branch.ToState, Event: event.Name, Guard: branch.Guard, After: event.Delay})
+			}
+			if event.ToState != "" {
+				edges = append(edges, edge{From: state, To: event.ToState, Event: event.Name, Guard: event.ToGuard, After: event.Delay})
+			}
+		}
+	}
+	return edges
+}
+
+// edges returns every transition the machine may take, in declaration order. Events without source states have an edge
+// from every top-level state other than the final states, and guarded events have an edge per branch.
+func (gen *Generator) edges() []edge {
+	edges := []edge{}
+	roots := gen.roots()
+	for _, rule := range gen.rules() {
+		if rule.From != "" {
+			edges = append(edges, rule)
+			continue
+		}
+		for _, state := range roots {
+			if gen.FinalStates[state] {
+				continue
+			}
+			rule.From = state
+			if rule.Internal {
+				rule.To = state
+			}
+			edges = append(edges, rule)
+		}
+	}
+	return edges
+}
+
+// WriteDOT validates the state machine definition and writes it to w as a Graphviz DOT digraph.
+func (gen *Generator) WriteDOT(w io.Writer) error {
+	err := gen.Validate()
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "digraph %s {\n", strconv.Quote(gen.Name))
+	fmt.Fprintln(out, "\trankdir=LR;")
+	if len(gen.Substates) > 0 {
+		fmt.Fprintln(out, "\tcompound=true;")
+	}
+	fmt.Fprintln(out, "\tnode [shape=box, style=rounded];")
+	fmt.Fprintln(out, "\t__initial [shape=point, label=\"\"];")
+	for _, state := range gen.roots() {
+		gen.writeDOTState(out, state, "\t")
+	}
+	fmt.Fprintf(out, "\t%s;\n", gen.dotEdge("", gen.States[0]))
+	for _, e := range gen.edges() {
+		fmt.Fprintf(out, "\t%s;\n", gen.dotEdge(e.From, e.To, "label="+strconv.Quote(e.Label())))
+	}
+	for _, history := range gen.Histories {
+		fmt.Fprintf(out, "\t%s;\n", gen.dotEdge(history.Name, history.Default, "style=dashed"))
+	}
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// writeDOTState writes a state as a node, or a compound state as a cluster containing its children. The regions of a
+// parallel state are drawn as dashed clusters, and final states have a double border.
+func (gen *Generator) writeDOTState(out *bufio.Writer, state, indent string) {
+	children := gen.Substates[state]
+	if len(children) == 0 {
+		if gen.FinalStates[state] {
+			fmt.Fprintf(out, "%s%s [peripheries=2];\n", indent, strconv.Quote(state))
+			return
+		}
+		fmt.Fprintf(out, "%s%s;\n", indent, strconv.Quote(state))
+		return
+	}
+	fmt.Fprintf(out, "%ssubgraph %s {\n", indent, strconv.Quote("cluster_"+state))
+	fmt.Fprintf(out, "%s\tlabel=%s;\n", indent, strconv.Quote(state))
+	if gen.ParallelStates[gen.parents()[state]] {
+		fmt.Fprintf(out, "%s\tstyle=dashed;\n", indent)
+	}
+	for _, child := range children {
+		gen.writeDOTState(out, child, indent+"\t")
+	}
+	for _, history := range gen.Histories {
+		if history.Parent == state {
+			fmt.Fprintf(out, "%s\t%s [shape=circle, label=%s];\n", indent, strconv.Quote(history.Name), strconv.Quote(history.label()))
+		}
+	}
+	fmt.Fprintf(out, "%s}\n", indent)
+}
+
+// dotEdge returns a DOT edge statement between two states with the supplied attributes, leaving from the initial point
+// if the source is empty. Clusters cannot be the end of an edge, so edges to and from compound states are drawn to
+// their initial leaf state and clipped at the cluster's border.
+func (gen *Generator) dotEdge(from, to string, attrs ...string) string {
+	str := "__initial"
+	if from != "" {
+		str = strconv.Quote(gen.initialLeaf(from))
+		if len(gen.Substates[from]) > 0 {
+			attrs = append(attrs, "ltail="+strconv.Quote("cluster_"+from))
+		}
+	}
+	str += " -> " + strconv.Quote(gen.initialLeaf(to))
+	if len(gen.Substates[to]) > 0 {
+		attrs = append(attrs, "lhead="+strconv.Quote("cluster_"+to))
+	}
+	if len(attrs) > 0 {
+		str += " [" + strings.Join(attrs, ", ") + "]"
+	}
+	return str
+}
+
+// mermaidIDPattern matches state names that can be used as Mermaid state ids as they are.
+var mermaidIDPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// mermaidID returns the id used for the supplied state in a Mermaid diagram.
+func mermaidID(state string) string {
+	if mermaidIDPattern.MatchString(state) {
+		return state
+	}
+	return exportedName(state)
+}
+
+// WriteMermaid validates the state machine definition and writes it to w as a Mermaid state diagram.
+func (gen *Generator) WriteMermaid(w io.Writer) error {
+	err := gen.Validate()
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "stateDiagram-v2")
+	for _, state := range gen.States {
+		if id := mermaidID(state); id != state {
+			fmt.Fprintf(out, "\tstate %s as %s\n", strconv.Quote(state), id)
+		}
+	}
+	fmt.Fprintf(out, "\t[*] --> %s\n", mermaidID(gen.States[0]))
+	for _, state := range gen.roots() {
+		if len(gen.Substates[state]) > 0 {
+			gen.writeMermaidState(out, state, "\t")
+		}
+	}
+	for _, e := range gen.edges() {
+		fmt.Fprintf(out, "\t%s --> %s: %s\n", mermaidID(e.From), mermaidID(e.To), e.Label())
+	}
+	for _, history := range gen.Histories {
+		fmt.Fprintf(out, "\t%s --> %s\n", mermaidID(history.Name), mermaidID(history.Default))
+	}
+	for _, state := range gen.roots() {
+		if gen.FinalStates[state] {
+			fmt.Fprintf(out, "\t%s --> [*]\n", mermaidID(state))
+		}
+	}
+	return out.Flush()
+}
+
+// writeMermaidState writes a compound state as a composite state block containing its initial transition, children and
+// the transitions to its end from its final children. The regions of a parallel state have no initial transition and
+// are separated by "--".
+func (gen *Generator) writeMermaidState(out *bufio.Writer, state, indent string) {
+	children := gen.Substates[state]
+	fmt.Fprintf(out, "%sstate %s {\n", indent, mermaidID(state))
+	if !gen.ParallelStates[state] {
+		fmt.Fprintf(out, "%s\t[*] --> %s\n", indent, mermaidID(children[0]))
+	}
+	for i, child := range children {
+		if i > 0 && gen.ParallelStates[state] {
+			fmt.Fprintf(out, "%s\t--\n", indent)
+		}
+		if len(gen.Substates[child]) > 0 {
+			gen.writeMermaidState(out, child, indent+"\t")
+		} else {
+			fmt.Fprintf(out, "%s\t%s\n", indent, mermaidID(child))
+		}
+	}
+	for _, child := range children {
+		if gen.FinalStates[child] {
+			fmt.Fprintf(out, "%s\t%s --> [*]\n", indent, mermaidID(child))
+		}
+	}
+	for _, history := range gen.Histories {
+		if history.Parent == state {
+			fmt.Fprintf(out, "%s\tstate %s as %s\n", indent, strconv.Quote(history.label()), mermaidID(history.Name))
+		}
+	}
+	fmt.Fprintf(out, "%s}\n", indent)
+}
@@ -0,0 +1,221 @@
+package fsmgen
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func newDiagramGenerator() *Generator {
+	gen := New("player", testState{}, testEnv{}, "init", "playing", "paused state")
+	gen.AddEvent(NewEvent("play", testEvent{}).From("init", "paused state").Branch("file_loaded", "playing").To("init"))
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("playing").Guard("pausable").To("paused state"))
+	gen.AddEvent(NewEvent("error", testEvent{}).FromAny().To("init"))
+	return gen
+}
+
+func TestWriteDOT(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.NilError(t, newDiagramGenerator().WriteDOT(out))
+	assert.Equal(t, `digraph "player" {
+	rankdir=LR;
+	node [shape=box, style=rounded];
+	__initial [shape=point, label=""];
+	"init";
+	"playing";
+	"paused state";
+	__initial -> "init";
+	"init" -> "playing" [label="play [file_loaded]"];
+	"init" -> "init" [label="play"];
+	"paused state" -> "playing" [label="play [file_loaded]"];
+	"paused state" -> "init" [label="play"];
+	"playing" -> "paused state" [label="pause [pausable]"];
+	"init" -> "init" [label="error"];
+	"playing" -> "init" [label="error"];
+	"paused state" -> "init" [label="error"];
+}
+`, out.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.NilError(t, newDiagramGenerator().WriteMermaid(out))
+	assert.Equal(t, `stateDiagram-v2
+	state "paused state" as PausedState
+	[*] --> init
+	init --> playing: play [file_loaded]
+	init --> init: play
+	PausedState --> playing: play [file_loaded]
+	PausedState --> init: play
+	playing --> PausedState: pause [pausable]
+	init --> init: error
+	playing --> init: error
+	PausedState --> init: error
+`, out.String())
+}
+
+func TestWriteDiagramInvalid(t *testing.T) {
+	gen := New("empty", testState{}, testEnv{})
+	assert.ErrorContains(t, gen.WriteDOT(&bytes.Buffer{}), "machine has no states")
+	assert.ErrorContains(t, gen.WriteMermaid(&bytes.Buffer{}), "machine has no states")
+}
+
+func newNestedDiagramGenerator() *Generator {
+	gen := New("player", testState{}, testEnv{}, "stopped", "active", "loading", "playing")
+	gen.AddSubstates("active", "loading", "playing")
+	gen.AddEvent(NewEvent("load", testEvent{}).From("stopped").To("active"))
+	gen.AddEvent(NewEvent("loaded", testEvent{}).From("loading").To("playing"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("active").To("stopped"))
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("stopped"))
+	return gen
+}
+
+func TestWriteDOTSubstates(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.NilError(t, newNestedDiagramGenerator().WriteDOT(out))
+	assert.Equal(t, `digraph "player" {
+	rankdir=LR;
+	compound=true;
+	node [shape=box, style=rounded];
+	__initial [shape=point, label=""];
+	"stopped";
+	subgraph "cluster_active" {
+		label="active";
+		"loading";
+		"playing";
+	}
+	__initial -> "stopped";
+	"stopped" -> "loading" [label="load", lhead="cluster_active"];
+	"loading" -> "playing" [label="loaded"];
+	"loading" -> "stopped" [label="stop", ltail="cluster_active"];
+	"stopped" -> "stopped" [label="reset"];
+	"loading" -> "stopped" [label="reset", ltail="cluster_active"];
+}
+`, out.String())
+}
+
+func TestWriteMermaidSubstates(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.NilError(t, newNestedDiagramGenerator().WriteMermaid(out))
+	assert.Equal(t, `stateDiagram-v2
+	[*] --> stopped
+	state active {
+		[*] --> loading
+		loading
+		playing
+	}
+	stopped --> active: load
+	loading --> playing: loaded
+	active --> stopped: stop
+	stopped --> stopped: reset
+	active --> stopped: reset
+`, out.String())
+}
+
+func TestWriteDiagramsRegions(t *testing.T) {
+	gen := New("device", testState{}, testEnv{}, "on", "network", "offline", "playback")
+	gen.AddRegions("on", "network", "playback")
+	gen.AddSubstates("network", "offline")
+	dot := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteDOT(dot))
+	assert.Equal(t, `digraph "device" {
+	rankdir=LR;
+	compound=true;
+	node [shape=box, style=rounded];
+	__initial [shape=point, label=""];
+	subgraph "cluster_on" {
+		label="on";
+		subgraph "cluster_network" {
+			label="network";
+			style=dashed;
+			"offline";
+		}
+		"playback";
+	}
+	__initial -> "offline" [lhead="cluster_on"];
+}
+`, dot.String())
+	mermaid := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteMermaid(mermaid))
+	assert.Equal(t, `stateDiagram-v2
+	[*] --> on
+	state on {
+		state network {
+			[*] --> offline
+			offline
+		}
+		--
+		playback
+	}
+`, mermaid.String())
+}
+
+func TestWriteDiagramsFinalStates(t *testing.T) {
+	gen := New("job", testState{}, testEnv{}, "running", "working", "finished", "done")
+	gen.AddSubstates("running", "working", "finished")
+	gen.Final("finished", "done")
+	gen.AddEvent(NewEvent("complete", testEvent{}).From("running").To("done").OnCompletion())
+	dot := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteDOT(dot))
+	assert.Equal(t, `digraph "job" {
+	rankdir=LR;
+	compound=true;
+	node [shape=box, style=rounded];
+	__initial [shape=point, label=""];
+	subgraph "cluster_running" {
+		label="running";
+		"working";
+		"finished" [peripheries=2];
+	}
+	"done" [peripheries=2];
+	__initial -> "working" [lhead="cluster_running"];
+	"working" -> "done" [label="complete", ltail="cluster_running"];
+}
+`, dot.String())
+	mermaid := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteMermaid(mermaid))
+	assert.Equal(t, `stateDiagram-v2
+	[*] --> running
+	state running {
+		[*] --> working
+		working
+		finished
+		finished --> [*]
+	}
+	running --> done: complete
+	done --> [*]
+`, mermaid.String())
+}
+
+func TestWriteMermaidFinalStatesFromAny(t *testing.T) {
+	gen := New("job", testState{}, testEnv{}, "running", "done", "cancelled")
+	gen.Final("done", "cancelled")
+	gen.AddEvent(NewEvent("finish", testEvent{}).From("running").To("done"))
+	gen.AddEvent(NewEvent("cancel", testEvent{}).To("cancelled"))
+	mermaid := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteMermaid(mermaid))
+	assert.Equal(t, `stateDiagram-v2
+	[*] --> running
+	running --> done: finish
+	running --> cancelled: cancel
+	done --> [*]
+	cancelled --> [*]
+`, mermaid.String())
+}
+
+func TestWriteMermaidInternal(t *testing.T) {
+	gen := New("player", testState{}, testEnv{}, "idle", "playing")
+	gen.AddEvent(NewEvent("play", testEvent{}).From("idle").To("playing"))
+	gen.AddEvent(NewEvent("seek", testEvent{}).From("playing").Internal())
+	gen.AddEvent(NewEvent("volume", testEvent{}).FromAny().Guard("unmuted").Internal())
+	out := &bytes.Buffer{}
+	assert.NilError(t, gen.WriteMermaid(out))
+	assert.Equal(t, `stateDiagram-v2
+	[*] --> idle
+	idle --> playing: play
+	playing --> playing: seek (internal)
+	idle --> idle: volume [unmuted] (internal)
+	playing --> playing: volume [unmuted] (internal)
+`, out.String())
+}
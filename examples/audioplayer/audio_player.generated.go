@@ -1,32 +1,161 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
 
 package main
 
-// Code generated by go-fsmgen DO NOT EDIT.
-
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// AudioPlayerState is a state the AudioPlayerMachine may be in.
+type AudioPlayerState string
+
+const (
+	AudioPlayerStateInit    AudioPlayerState = "init"
+	AudioPlayerStateLoading AudioPlayerState = "loading"
+	AudioPlayerStatePlaying AudioPlayerState = "playing"
+	AudioPlayerStatePaused  AudioPlayerState = "paused"
+)
+
+// String returns the name of the state.
+func (state AudioPlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state AudioPlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseAudioPlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *AudioPlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseAudioPlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseAudioPlayerState returns the AudioPlayerState with the supplied name.
+func ParseAudioPlayerState(str string) (AudioPlayerState, error) {
+	switch AudioPlayerState(str) {
+	case AudioPlayerStateInit, AudioPlayerStateLoading, AudioPlayerStatePlaying, AudioPlayerStatePaused:
+		return AudioPlayerState(str), nil
+	}
+	return "", errors.New("unknown audio_player state: " + str)
+}
+
+// AudioPlayerEvent is an event that may be triggered on the AudioPlayerMachine.
+type AudioPlayerEvent string
+
+const (
+	AudioPlayerEventLoad  AudioPlayerEvent = "load"
+	AudioPlayerEventPlay  AudioPlayerEvent = "play"
+	AudioPlayerEventPause AudioPlayerEvent = "pause"
+	AudioPlayerEventError AudioPlayerEvent = "error"
 )
 
+// String returns the name of the event.
+func (event AudioPlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event AudioPlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseAudioPlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *AudioPlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseAudioPlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseAudioPlayerEvent returns the AudioPlayerEvent with the supplied name.
+func ParseAudioPlayerEvent(str string) (AudioPlayerEvent, error) {
+	switch AudioPlayerEvent(str) {
+	case AudioPlayerEventLoad, AudioPlayerEventPlay, AudioPlayerEventPause, AudioPlayerEventError:
+		return AudioPlayerEvent(str), nil
+	}
+	return "", errors.New("unknown audio_player event: " + str)
+}
+
+// AudioPlayerMachine is the audio_player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
 type AudioPlayerMachine struct {
-	CurrentState string
-	State *AudioPlayerState
+	*fsmruntime.Machine[AudioPlayerState, AudioPlayerEvent, AudioPlayerData]
 
 	env AudioPlayerEnvironment
-	transitions  map[string]map[string]string
 
+	LoadAction  func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventLoad) error
+	PlayAction  func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventPlay) error
+	PauseAction func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventPause) error
+	ErrorAction func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventError) error
 
-	LoadAction func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventLoad) error
-	PlayAction func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventPlay) error
-	PauseAction func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventPause) error
-	ErrorAction func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventError) error
+	OnStateInit    func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnStateLoading func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnStatePlaying func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnStatePaused  func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
 
-	OnStateInit func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error
-	OnStateLoading func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error
-	OnStatePlaying func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error
-	OnStatePaused func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error
+	OnExitInit    func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnExitLoading func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnExitPlaying func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
+	OnExitPaused  func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error
 }
 
+// AudioPlayerTransition describes a transition of the AudioPlayerMachine.
+type AudioPlayerTransition = fsmruntime.Transition[AudioPlayerState, AudioPlayerEvent]
+
+// AudioPlayerSnapshot is a serializable copy of the AudioPlayerMachine's state, which
+// Restore resumes from.
+type AudioPlayerSnapshot = fsmruntime.Snapshot[AudioPlayerState, AudioPlayerEvent, AudioPlayerData]
+
+// AudioPlayerTransitionRule describes a transition declared by the AudioPlayerMachine's definition.
+type AudioPlayerTransitionRule = fsmruntime.TransitionRule[AudioPlayerState, AudioPlayerEvent]
+
+// AudioPlayerListener observes every event processed by a AudioPlayerMachine.
+type AudioPlayerListener = fsmruntime.Listener[AudioPlayerState, AudioPlayerEvent]
+
+// AudioPlayerBaseListener implements AudioPlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type AudioPlayerBaseListener = fsmruntime.BaseListener[AudioPlayerState, AudioPlayerEvent]
+
+// AudioPlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
 type AudioPlayerMachineContext interface {
 	Context() context.Context
 	TriggerLoad(ev EventLoad) error
@@ -36,13 +165,13 @@ type AudioPlayerMachineContext interface {
 }
 
 type audioPlayerMachineContext struct {
-	ctx context.Context
+	ctx     context.Context
 	machine *AudioPlayerMachine
 }
 
 func newAudioPlayerContext(ctx context.Context, machine *AudioPlayerMachine) AudioPlayerMachineContext {
 	return &audioPlayerMachineContext{
-		ctx: ctx,
+		ctx:     ctx,
 		machine: machine,
 	}
 }
@@ -51,143 +180,263 @@ func (ctx audioPlayerMachineContext) Context() context.Context {
 	return ctx.ctx
 }
 
+// TriggerLoad queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx audioPlayerMachineContext) TriggerLoad(ev EventLoad) error {
-	return ctx.machine.TriggerLoad(ctx.ctx, ev)
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoad(ctx.ctx, ev)
+	})
 }
+
+// TriggerPlay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx audioPlayerMachineContext) TriggerPlay(ev EventPlay) error {
-	return ctx.machine.TriggerPlay(ctx.ctx, ev)
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPlay(ctx.ctx, ev)
+	})
 }
+
+// TriggerPause queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx audioPlayerMachineContext) TriggerPause(ev EventPause) error {
-	return ctx.machine.TriggerPause(ctx.ctx, ev)
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPause(ctx.ctx, ev)
+	})
 }
+
+// TriggerError queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx audioPlayerMachineContext) TriggerError(ev EventError) error {
-	return ctx.machine.TriggerError(ctx.ctx, ev)
-}
-
-func NewAudioPlayerMachine(state *AudioPlayerState, env AudioPlayerEnvironment) *AudioPlayerMachine{
-	return &AudioPlayerMachine{
-		State:        state,
-		CurrentState: "init",
-		env:          env,
-		transitions:  map[string]map[string]string{
-				"": {
-					"error": "init",
-					"load": "loading",
-				},
-				"init": {
-				},
-				"loading": {
-					"play": "playing",
-				},
-				"paused": {
-					"play": "playing",
-				},
-				"playing": {
-					"pause": "paused",
-				},
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerError(ctx.ctx, ev)
+	})
+}
+
+// audioPlayerDefinition is the structure of the AudioPlayerMachine, shared by every instance.
+var audioPlayerDefinition = &fsmruntime.Definition[AudioPlayerState, AudioPlayerEvent]{
+	Name:   "audio_player",
+	States: []AudioPlayerState{AudioPlayerStateInit, AudioPlayerStateLoading, AudioPlayerStatePlaying, AudioPlayerStatePaused},
+	Events: []AudioPlayerEvent{AudioPlayerEventLoad, AudioPlayerEventPlay, AudioPlayerEventPause, AudioPlayerEventError},
+	Transitions: map[AudioPlayerState]map[AudioPlayerEvent]AudioPlayerState{
+		"": {
+			AudioPlayerEventError: AudioPlayerStateInit,
+			AudioPlayerEventLoad:  AudioPlayerStateLoading,
+		},
+		AudioPlayerStateInit: {},
+		AudioPlayerStateLoading: {
+			AudioPlayerEventPlay: AudioPlayerStatePlaying,
+		},
+		AudioPlayerStatePaused: {
+			AudioPlayerEventPlay: AudioPlayerStatePlaying,
+		},
+		AudioPlayerStatePlaying: {
+			AudioPlayerEventPause: AudioPlayerStatePaused,
 		},
+	},
+	Parents: map[AudioPlayerState]AudioPlayerState{},
+	Initial: map[AudioPlayerState]AudioPlayerState{},
+	Regions: map[AudioPlayerState][]AudioPlayerState{},
+	Order: map[AudioPlayerState]int{
+		AudioPlayerStateInit:    0,
+		AudioPlayerStateLoading: 1,
+		AudioPlayerStatePlaying: 2,
+		AudioPlayerStatePaused:  3,
+	},
+	Rules: []AudioPlayerTransitionRule{
+		{From: "", Event: AudioPlayerEventLoad, To: AudioPlayerStateLoading},
+		{From: AudioPlayerStatePaused, Event: AudioPlayerEventPlay, To: AudioPlayerStatePlaying},
+		{From: AudioPlayerStateLoading, Event: AudioPlayerEventPlay, To: AudioPlayerStatePlaying},
+		{From: AudioPlayerStatePlaying, Event: AudioPlayerEventPause, To: AudioPlayerStatePaused},
+		{From: "", Event: AudioPlayerEventError, To: AudioPlayerStateInit},
+	},
+	MaxChainLength: 100,
+}
+
+// NewAudioPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewAudioPlayerMachine(state *AudioPlayerData, env AudioPlayerEnvironment) *AudioPlayerMachine {
+	machine := &AudioPlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(audioPlayerDefinition, state, fsmruntime.Handlers[AudioPlayerState, AudioPlayerEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
 	}
+	return machine
 }
 
-func (machine *AudioPlayerMachine) Start(ctx context.Context) (error) {
-	return machine.didEnterState(ctx)
+// NewAudioPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewAudioPlayerMachineFromSnapshot(snapshot AudioPlayerSnapshot, env AudioPlayerEnvironment) (*AudioPlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(AudioPlayerData)
+	}
+	machine := NewAudioPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
 }
 
-func (machine *AudioPlayerMachine) getState(event string) (string, error) {
-	target := machine.transitions[machine.CurrentState][event]
-	if target != "" {
-		return target, nil
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *AudioPlayerMachine) eventFunc(event AudioPlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case AudioPlayerEventLoad:
+		ev, ok := payload.(EventLoad)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoad, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoad(ctx, ev)
+		}, nil
+	case AudioPlayerEventPlay:
+		ev, ok := payload.(EventPlay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPlay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPlay(ctx, ev)
+		}, nil
+	case AudioPlayerEventPause:
+		ev, ok := payload.(EventPause)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPause, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPause(ctx, ev)
+		}, nil
+	case AudioPlayerEventError:
+		ev, ok := payload.(EventError)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventError, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerError(ctx, ev)
+		}, nil
 	}
-	target = machine.transitions[""][event]
-	if target != "" {
-		return target, nil
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *AudioPlayerMachine) exitState(ctx context.Context, transition AudioPlayerTransition, state AudioPlayerState) error {
+	switch state {
+	case AudioPlayerStateInit:
+		if machine.OnExitInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitInit", transition, machine.OnExitInit(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStateLoading:
+		if machine.OnExitLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitLoading", transition, machine.OnExitLoading(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStatePaused:
+		if machine.OnExitPaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaused", transition, machine.OnExitPaused(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
 	}
-	return "", errors.New("invalid transition: no transition target from " + machine.CurrentState + " via " + event)
+	return nil
 }
 
-func (machine *AudioPlayerMachine) didEnterState(ctx context.Context) error {
-	switch machine.CurrentState {
-	case "init":
+func (machine *AudioPlayerMachine) enterState(ctx context.Context, transition AudioPlayerTransition, state AudioPlayerState) error {
+	switch state {
+	case AudioPlayerStateInit:
 		if machine.OnStateInit == nil {
 			break
 		}
-		return machine.OnStateInit(newAudioPlayerContext(ctx, machine), machine.env, *machine.State)
-	case "loading":
+		return fsmruntime.WrapHandlerError("OnStateInit", transition, machine.OnStateInit(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStateLoading:
 		if machine.OnStateLoading == nil {
 			break
 		}
-		return machine.OnStateLoading(newAudioPlayerContext(ctx, machine), machine.env, *machine.State)
-	case "playing":
+		return fsmruntime.WrapHandlerError("OnStateLoading", transition, machine.OnStateLoading(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStatePlaying:
 		if machine.OnStatePlaying == nil {
 			break
 		}
-		return machine.OnStatePlaying(newAudioPlayerContext(ctx, machine), machine.env, *machine.State)
-	case "paused":
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
+	case AudioPlayerStatePaused:
 		if machine.OnStatePaused == nil {
 			break
 		}
-		return machine.OnStatePaused(newAudioPlayerContext(ctx, machine), machine.env, *machine.State)
+		return fsmruntime.WrapHandlerError("OnStatePaused", transition, machine.OnStatePaused(newAudioPlayerContext(ctx, machine), machine.env, *machine.State))
 	}
 	return nil
 }
 
-func (machine *AudioPlayerMachine) TriggerLoad (ctx context.Context, ev EventLoad) error {
-	target, err := machine.getState("load")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.LoadAction != nil {
-		err := machine.LoadAction(newAudioPlayerContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
-		}
-	}
-	return machine.didEnterState(ctx)
+// TriggerLoad triggers the load event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *AudioPlayerMachine) TriggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoad(ctx, ev)
+	})
 }
 
-func (machine *AudioPlayerMachine) TriggerPlay (ctx context.Context, ev EventPlay) error {
-	target, err := machine.getState("play")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.PlayAction != nil {
-		err := machine.PlayAction(newAudioPlayerContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
+func (machine *AudioPlayerMachine) triggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Fire(ctx, AudioPlayerEventLoad, ev, nil, func(ctx context.Context, transition AudioPlayerTransition) error {
+		if machine.LoadAction == nil {
+			return nil
 		}
-	}
-	return machine.didEnterState(ctx)
+		return fsmruntime.WrapHandlerError("LoadAction", transition, machine.LoadAction(newAudioPlayerContext(ctx, machine), machine.State, ev))
+	})
 }
 
-func (machine *AudioPlayerMachine) TriggerPause (ctx context.Context, ev EventPause) error {
-	target, err := machine.getState("pause")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.PauseAction != nil {
-		err := machine.PauseAction(newAudioPlayerContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
+// TriggerPlay triggers the play event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *AudioPlayerMachine) TriggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPlay(ctx, ev)
+	})
+}
+
+func (machine *AudioPlayerMachine) triggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Fire(ctx, AudioPlayerEventPlay, ev, nil, func(ctx context.Context, transition AudioPlayerTransition) error {
+		if machine.PlayAction == nil {
+			return nil
 		}
-	}
-	return machine.didEnterState(ctx)
+		return fsmruntime.WrapHandlerError("PlayAction", transition, machine.PlayAction(newAudioPlayerContext(ctx, machine), machine.State, ev))
+	})
 }
 
-func (machine *AudioPlayerMachine) TriggerError (ctx context.Context, ev EventError) error {
-	target, err := machine.getState("error")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.ErrorAction != nil {
-		err := machine.ErrorAction(newAudioPlayerContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
+// TriggerPause triggers the pause event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *AudioPlayerMachine) TriggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPause(ctx, ev)
+	})
+}
+
+func (machine *AudioPlayerMachine) triggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Fire(ctx, AudioPlayerEventPause, ev, nil, func(ctx context.Context, transition AudioPlayerTransition) error {
+		if machine.PauseAction == nil {
+			return nil
 		}
-	}
-	return machine.didEnterState(ctx)
+		return fsmruntime.WrapHandlerError("PauseAction", transition, machine.PauseAction(newAudioPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerError triggers the error event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *AudioPlayerMachine) TriggerError(ctx context.Context, ev EventError) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerError(ctx, ev)
+	})
 }
 
+func (machine *AudioPlayerMachine) triggerError(ctx context.Context, ev EventError) error {
+	return machine.Fire(ctx, AudioPlayerEventError, ev, nil, func(ctx context.Context, transition AudioPlayerTransition) error {
+		if machine.ErrorAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ErrorAction", transition, machine.ErrorAction(newAudioPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
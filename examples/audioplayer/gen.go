@@ -9,10 +9,12 @@ import (
 )
 
 func main() {
-	gen := fsmgen.New("audio_player", AudioPlayerState{}, "AudioPlayerEnvironment", "init", "loading", "playing", "paused")
+	gen := fsmgen.New("audio_player", AudioPlayerData{}, "AudioPlayerEnvironment", "init", "loading", "playing", "paused")
 	// This would work too
-	//gen := fsmgen.New("audio_player", AudioPlayerState{}, AudioPlayerEnvironment{}, "init", "loading", "playing", "paused")
+	//gen := fsmgen.New("audio_player", AudioPlayerData{}, AudioPlayerEnvironment{}, "init", "loading", "playing", "paused")
 	gen.PackageName = "main"
+	gen.DOTFilename = "audio_player.dot"
+	gen.MermaidFilename = "audio_player.mmd"
 	gen.AddEvent(fsmgen.NewEvent("load", EventLoad{}).FromAny().To("loading"))
 	gen.AddEvent(fsmgen.NewEvent("play", EventPlay{}).From("paused", "loading").To("playing"))
 	gen.AddEvent(fsmgen.NewEvent("pause", EventPause{}).From("playing").To("paused"))
@@ -30,18 +30,18 @@ func main() {
 
 func NewMachine() *AudioPlayerMachine {
 	env := AudioPlayerEnvironment{Logger: log.New(os.Stdout, "fsm ", 0)}
-	machine := NewAudioPlayerMachine(&AudioPlayerState{
+	machine := NewAudioPlayerMachine(&AudioPlayerData{
 		Player: &StringAudioPlayer{},
 	}, env)
-	machine.LoadAction = func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventLoad) error {
+	machine.LoadAction = func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventLoad) error {
 		state.file = ev.File
 		return nil
 	}
-	machine.ErrorAction = func(ctx AudioPlayerMachineContext, state *AudioPlayerState, ev EventError) error {
+	machine.ErrorAction = func(ctx AudioPlayerMachineContext, state *AudioPlayerData, ev EventError) error {
 		state.Message = ev.Message
 		return nil
 	}
-	machine.OnStateLoading = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error {
+	machine.OnStateLoading = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error {
 		env.Logger.Print("Did enter State Loading")
 		err := state.Player.Load(state.file)
 		if err != nil {
@@ -51,7 +51,7 @@ func NewMachine() *AudioPlayerMachine {
 		}
 		return ctx.TriggerPlay(EventPlay{})
 	}
-	machine.OnStatePlaying = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error {
+	machine.OnStatePlaying = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error {
 		env.Logger.Print("Did enter State Playing")
 		err := state.Player.Play()
 		if err != nil {
@@ -61,7 +61,7 @@ func NewMachine() *AudioPlayerMachine {
 		}
 		return nil
 	}
-	machine.OnStatePaused = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerState) error {
+	machine.OnStatePaused = func(ctx AudioPlayerMachineContext, env AudioPlayerEnvironment, state AudioPlayerData) error {
 		env.Logger.Print("Did enter State Paused")
 		err := state.Player.Pause()
 		if err != nil {
@@ -40,7 +40,7 @@ type EventError struct {
 	Message string
 }
 
-type AudioPlayerState struct {
+type AudioPlayerData struct {
 	file    *os.File
 	Player  AudioPlayer
 	Message string
@@ -0,0 +1,371 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// ActorCounterState is a state the ActorCounterMachine may be in.
+type ActorCounterState string
+
+const (
+	ActorCounterStateClosed ActorCounterState = "closed"
+	ActorCounterStateOpen   ActorCounterState = "open"
+)
+
+// String returns the name of the state.
+func (state ActorCounterState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state ActorCounterState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseActorCounterState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *ActorCounterState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseActorCounterState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseActorCounterState returns the ActorCounterState with the supplied name.
+func ParseActorCounterState(str string) (ActorCounterState, error) {
+	switch ActorCounterState(str) {
+	case ActorCounterStateClosed, ActorCounterStateOpen:
+		return ActorCounterState(str), nil
+	}
+	return "", errors.New("unknown actor_counter state: " + str)
+}
+
+// ActorCounterEvent is an event that may be triggered on the ActorCounterMachine.
+type ActorCounterEvent string
+
+const (
+	ActorCounterEventOpen      ActorCounterEvent = "open"
+	ActorCounterEventIncrement ActorCounterEvent = "increment"
+	ActorCounterEventClose     ActorCounterEvent = "close"
+)
+
+// String returns the name of the event.
+func (event ActorCounterEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event ActorCounterEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseActorCounterEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *ActorCounterEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseActorCounterEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseActorCounterEvent returns the ActorCounterEvent with the supplied name.
+func ParseActorCounterEvent(str string) (ActorCounterEvent, error) {
+	switch ActorCounterEvent(str) {
+	case ActorCounterEventOpen, ActorCounterEventIncrement, ActorCounterEventClose:
+		return ActorCounterEvent(str), nil
+	}
+	return "", errors.New("unknown actor_counter event: " + str)
+}
+
+// ActorCounterMachine is the actor_counter state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type ActorCounterMachine struct {
+	*fsmruntime.Machine[ActorCounterState, ActorCounterEvent, Counter]
+
+	env Environment
+
+	OpenAction      func(ctx ActorCounterMachineContext, state *Counter, ev EventOpen) error
+	IncrementAction func(ctx ActorCounterMachineContext, state *Counter, ev EventIncrement) error
+	CloseAction     func(ctx ActorCounterMachineContext, state *Counter, ev EventClose) error
+
+	OnStateClosed func(ctx ActorCounterMachineContext, env Environment, state Counter) error
+	OnStateOpen   func(ctx ActorCounterMachineContext, env Environment, state Counter) error
+
+	OnExitClosed func(ctx ActorCounterMachineContext, env Environment, state Counter) error
+	OnExitOpen   func(ctx ActorCounterMachineContext, env Environment, state Counter) error
+}
+
+// ActorCounterTransition describes a transition of the ActorCounterMachine.
+type ActorCounterTransition = fsmruntime.Transition[ActorCounterState, ActorCounterEvent]
+
+// ActorCounterSnapshot is a serializable copy of the ActorCounterMachine's state, which
+// Restore resumes from.
+type ActorCounterSnapshot = fsmruntime.Snapshot[ActorCounterState, ActorCounterEvent, Counter]
+
+// ActorCounterTransitionRule describes a transition declared by the ActorCounterMachine's definition.
+type ActorCounterTransitionRule = fsmruntime.TransitionRule[ActorCounterState, ActorCounterEvent]
+
+// ActorCounterListener observes every event processed by a ActorCounterMachine.
+type ActorCounterListener = fsmruntime.Listener[ActorCounterState, ActorCounterEvent]
+
+// ActorCounterBaseListener implements ActorCounterListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type ActorCounterBaseListener = fsmruntime.BaseListener[ActorCounterState, ActorCounterEvent]
+
+// ActorCounterMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type ActorCounterMachineContext interface {
+	Context() context.Context
+	TriggerOpen(ev EventOpen) error
+	TriggerIncrement(ev EventIncrement) error
+	TriggerClose(ev EventClose) error
+}
+
+type actorCounterMachineContext struct {
+	ctx     context.Context
+	machine *ActorCounterMachine
+}
+
+func newActorCounterContext(ctx context.Context, machine *ActorCounterMachine) ActorCounterMachineContext {
+	return &actorCounterMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx actorCounterMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerOpen queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx actorCounterMachineContext) TriggerOpen(ev EventOpen) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerOpen(ctx.ctx, ev)
+	})
+}
+
+// TriggerIncrement queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx actorCounterMachineContext) TriggerIncrement(ev EventIncrement) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerIncrement(ctx.ctx, ev)
+	})
+}
+
+// TriggerClose queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx actorCounterMachineContext) TriggerClose(ev EventClose) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerClose(ctx.ctx, ev)
+	})
+}
+
+// actorCounterDefinition is the structure of the ActorCounterMachine, shared by every instance.
+var actorCounterDefinition = &fsmruntime.Definition[ActorCounterState, ActorCounterEvent]{
+	Name:   "actor_counter",
+	States: []ActorCounterState{ActorCounterStateClosed, ActorCounterStateOpen},
+	Events: []ActorCounterEvent{ActorCounterEventOpen, ActorCounterEventIncrement, ActorCounterEventClose},
+	Transitions: map[ActorCounterState]map[ActorCounterEvent]ActorCounterState{
+		"": {},
+		ActorCounterStateClosed: {
+			ActorCounterEventOpen: ActorCounterStateOpen,
+		},
+		ActorCounterStateOpen: {
+			ActorCounterEventClose:     ActorCounterStateClosed,
+			ActorCounterEventIncrement: ActorCounterStateOpen,
+		},
+	},
+	Parents: map[ActorCounterState]ActorCounterState{},
+	Initial: map[ActorCounterState]ActorCounterState{},
+	Regions: map[ActorCounterState][]ActorCounterState{},
+	Order: map[ActorCounterState]int{
+		ActorCounterStateClosed: 0,
+		ActorCounterStateOpen:   1,
+	},
+	Rules: []ActorCounterTransitionRule{
+		{From: ActorCounterStateClosed, Event: ActorCounterEventOpen, To: ActorCounterStateOpen},
+		{From: ActorCounterStateOpen, Event: ActorCounterEventIncrement, To: ActorCounterStateOpen},
+		{From: ActorCounterStateOpen, Event: ActorCounterEventClose, To: ActorCounterStateClosed},
+	},
+	MaxChainLength: 100,
+	Concurrency:    fsmruntime.ConcurrencyActor,
+}
+
+// NewActorCounterMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewActorCounterMachine(state *Counter, env Environment) *ActorCounterMachine {
+	machine := &ActorCounterMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(actorCounterDefinition, state, fsmruntime.Handlers[ActorCounterState, ActorCounterEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewActorCounterMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewActorCounterMachineFromSnapshot(snapshot ActorCounterSnapshot, env Environment) (*ActorCounterMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(Counter)
+	}
+	machine := NewActorCounterMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *ActorCounterMachine) eventFunc(event ActorCounterEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case ActorCounterEventOpen:
+		ev, ok := payload.(EventOpen)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventOpen, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerOpen(ctx, ev)
+		}, nil
+	case ActorCounterEventIncrement:
+		ev, ok := payload.(EventIncrement)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventIncrement, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerIncrement(ctx, ev)
+		}, nil
+	case ActorCounterEventClose:
+		ev, ok := payload.(EventClose)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventClose, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerClose(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *ActorCounterMachine) exitState(ctx context.Context, transition ActorCounterTransition, state ActorCounterState) error {
+	switch state {
+	case ActorCounterStateClosed:
+		if machine.OnExitClosed == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitClosed", transition, machine.OnExitClosed(newActorCounterContext(ctx, machine), machine.env, *machine.State))
+	case ActorCounterStateOpen:
+		if machine.OnExitOpen == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOpen", transition, machine.OnExitOpen(newActorCounterContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *ActorCounterMachine) enterState(ctx context.Context, transition ActorCounterTransition, state ActorCounterState) error {
+	switch state {
+	case ActorCounterStateClosed:
+		if machine.OnStateClosed == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateClosed", transition, machine.OnStateClosed(newActorCounterContext(ctx, machine), machine.env, *machine.State))
+	case ActorCounterStateOpen:
+		if machine.OnStateOpen == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOpen", transition, machine.OnStateOpen(newActorCounterContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerOpen triggers the open event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *ActorCounterMachine) TriggerOpen(ctx context.Context, ev EventOpen) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerOpen(ctx, ev)
+	})
+}
+
+func (machine *ActorCounterMachine) triggerOpen(ctx context.Context, ev EventOpen) error {
+	return machine.Fire(ctx, ActorCounterEventOpen, ev, nil, func(ctx context.Context, transition ActorCounterTransition) error {
+		if machine.OpenAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("OpenAction", transition, machine.OpenAction(newActorCounterContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerIncrement triggers the increment event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *ActorCounterMachine) TriggerIncrement(ctx context.Context, ev EventIncrement) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerIncrement(ctx, ev)
+	})
+}
+
+func (machine *ActorCounterMachine) triggerIncrement(ctx context.Context, ev EventIncrement) error {
+	return machine.Fire(ctx, ActorCounterEventIncrement, ev, nil, func(ctx context.Context, transition ActorCounterTransition) error {
+		if machine.IncrementAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("IncrementAction", transition, machine.IncrementAction(newActorCounterContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerClose triggers the close event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *ActorCounterMachine) TriggerClose(ctx context.Context, ev EventClose) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerClose(ctx, ev)
+	})
+}
+
+func (machine *ActorCounterMachine) triggerClose(ctx context.Context, ev EventClose) error {
+	return machine.Fire(ctx, ActorCounterEventClose, ev, nil, func(ctx context.Context, transition ActorCounterTransition) error {
+		if machine.CloseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("CloseAction", transition, machine.CloseAction(newActorCounterContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,193 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+const (
+	goroutines = 20
+	increments = 25
+)
+
+func TestMutexMachine(t *testing.T) {
+	ctx := context.Background()
+	machine := NewMutexCounterMachine(&Counter{}, Environment{})
+	machine.IncrementAction = func(ctx MutexCounterMachineContext, counter *Counter, ev EventIncrement) error {
+		counter.Count++
+		if ev.By > 1 {
+			// Re-entrant triggers from handlers are queued rather than deadlocking on the mutex.
+			counter.Chains++
+			return ctx.TriggerIncrement(EventIncrement{By: ev.By - 1})
+		}
+		return nil
+	}
+	assert.NilError(t, machine.TriggerOpen(ctx, EventOpen{}))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				assert.Check(t, machine.TriggerIncrement(ctx, EventIncrement{By: 2}))
+				assert.Check(t, machine.Current() == MutexCounterStateOpen)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NilError(t, machine.Trigger(ctx, MutexCounterEventClose, EventClose{}))
+	assert.Equal(t, MutexCounterStateClosed, machine.Current())
+	assert.DeepEqual(t, &Counter{Count: 2 * goroutines * increments, Chains: goroutines * increments}, machine.State)
+}
+
+func TestActorMachine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	machine := NewActorCounterMachine(&Counter{}, Environment{})
+	machine.IncrementAction = func(ctx ActorCounterMachineContext, counter *Counter, ev EventIncrement) error {
+		counter.Count += ev.By
+		return nil
+	}
+	machine.OnStateOpen = func(ctx ActorCounterMachineContext, env Environment, counter Counter) error {
+		if counter.Count >= 100 {
+			return ctx.TriggerClose(EventClose{})
+		}
+		return nil
+	}
+	stopped := make(chan error)
+	go func() {
+		stopped <- machine.Run(ctx)
+	}()
+
+	assert.NilError(t, machine.TriggerOpen(ctx, EventOpen{}))
+	results := make(chan (<-chan error), goroutines*increments)
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				if j%2 == 0 {
+					results <- machine.Send(ctx, ActorCounterEventIncrement, EventIncrement{By: 0})
+					continue
+				}
+				assert.Check(t, machine.SendAndWait(ctx, ActorCounterEventIncrement, EventIncrement{By: 0}))
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	for result := range results {
+		assert.NilError(t, <-result)
+	}
+
+	err := <-machine.Send(ctx, ActorCounterEventIncrement, EventOpen{})
+	assert.Error(t, err, "invalid payload for event increment: expected EventIncrement, got concurrent.EventOpen")
+	assert.NilError(t, machine.SendAndWait(ctx, ActorCounterEventIncrement, EventIncrement{By: 100}))
+	assert.Equal(t, ActorCounterStateClosed, machine.Current())
+	err = machine.TriggerIncrement(ctx, EventIncrement{By: 1})
+	assert.Error(t, err, "invalid transition: no transition target from closed via increment")
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-stopped)
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+	assert.Equal(t, context.DeadlineExceeded, machine.TriggerOpen(timeout, EventOpen{}))
+	assert.Equal(t, 100, machine.State.Count)
+}
+
+// currentListener records the current state of its machine as each transition is observed.
+type currentListener struct {
+	MutexCounterBaseListener
+	current func() MutexCounterState
+	states  []MutexCounterState
+}
+
+func (listener *currentListener) OnTransition(ctx context.Context, from, to MutexCounterState, event MutexCounterEvent, payload interface{}) {
+	listener.states = append(listener.states, listener.current())
+}
+
+func TestMutexMachineQueries(t *testing.T) {
+	ctx := context.Background()
+	machine := NewMutexCounterMachine(&Counter{}, Environment{})
+	listener := &currentListener{current: machine.Current}
+	machine.AddListener(listener)
+	machine.OnStateOpen = func(ctx MutexCounterMachineContext, env Environment, counter Counter) error {
+		assert.Check(t, machine.CanTrigger(MutexCounterEventIncrement))
+		assert.Check(t, machine.IsIn(MutexCounterStateOpen))
+		return nil
+	}
+	machine.IncrementAction = func(ctx MutexCounterMachineContext, counter *Counter, ev EventIncrement) error {
+		counter.Count++
+		if ev.By > 1 {
+			// Triggering with the handler's context queues the event rather than waiting for the lock.
+			return machine.TriggerIncrement(ctx.Context(), EventIncrement{By: ev.By - 1})
+		}
+		return nil
+	}
+	within(t, func() {
+		assert.NilError(t, machine.TriggerOpen(ctx, EventOpen{}))
+		assert.NilError(t, machine.TriggerIncrement(ctx, EventIncrement{By: 3}))
+	})
+	assert.Equal(t, 3, machine.State.Count)
+	assert.DeepEqual(t, []MutexCounterState{MutexCounterStateOpen, MutexCounterStateOpen, MutexCounterStateOpen, MutexCounterStateOpen}, listener.states)
+}
+
+func TestActorMachineQueries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	machine := NewActorCounterMachine(&Counter{}, Environment{})
+	states := []ActorCounterState{}
+	machine.AddListener(&actorCurrentListener{record: func() {
+		states = append(states, machine.Current())
+	}})
+	machine.OnStateOpen = func(ctx ActorCounterMachineContext, env Environment, counter Counter) error {
+		assert.Check(t, machine.CanTrigger(ActorCounterEventIncrement))
+		assert.Check(t, machine.Snapshot().State.Count == counter.Count)
+		return nil
+	}
+	machine.IncrementAction = func(ctx ActorCounterMachineContext, counter *Counter, ev EventIncrement) error {
+		counter.Count++
+		if ev.By > 1 {
+			// Triggering with the handler's context queues the event rather than waiting for the goroutine running the
+			// machine, which is running this action.
+			return machine.TriggerIncrement(ctx.Context(), EventIncrement{By: ev.By - 1})
+		}
+		return nil
+	}
+	go machine.Run(ctx)
+	within(t, func() {
+		assert.NilError(t, machine.TriggerOpen(ctx, EventOpen{}))
+		assert.NilError(t, <-machine.Send(ctx, ActorCounterEventIncrement, EventIncrement{By: 3}))
+	})
+	assert.Equal(t, 3, machine.Snapshot().State.Count)
+	assert.DeepEqual(t, []ActorCounterState{ActorCounterStateOpen, ActorCounterStateOpen, ActorCounterStateOpen, ActorCounterStateOpen}, states)
+}
+
+type actorCurrentListener struct {
+	ActorCounterBaseListener
+	record func()
+}
+
+func (listener *actorCurrentListener) OnTransition(ctx context.Context, from, to ActorCounterState, event ActorCounterEvent, payload interface{}) {
+	listener.record()
+}
+
+// within fails the test if fn does not return within a second, such as if it deadlocks.
+func within(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out, the machine is deadlocked")
+	}
+}
@@ -0,0 +1,28 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/concurrent"
+)
+
+func main() {
+	for name, concurrency := range map[string]fsmgen.Concurrency{
+		"mutex_counter": fsmgen.ConcurrencyMutex,
+		"actor_counter": fsmgen.ConcurrencyActor,
+	} {
+		gen := fsmgen.New(name, concurrent.Counter{}, concurrent.Environment{}, concurrent.StateClosed, concurrent.StateOpen)
+		gen.PackageName = "concurrent"
+		gen.Concurrency = concurrency
+		gen.AddEvent(fsmgen.NewEvent("open", concurrent.EventOpen{}).From(concurrent.StateClosed).To(concurrent.StateOpen))
+		gen.AddEvent(fsmgen.NewEvent("increment", concurrent.EventIncrement{}).From(concurrent.StateOpen).To(concurrent.StateOpen))
+		gen.AddEvent(fsmgen.NewEvent("close", concurrent.EventClose{}).From(concurrent.StateOpen).To(concurrent.StateClosed))
+		err := gen.Write()
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+}
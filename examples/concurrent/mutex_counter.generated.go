@@ -0,0 +1,371 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// MutexCounterState is a state the MutexCounterMachine may be in.
+type MutexCounterState string
+
+const (
+	MutexCounterStateClosed MutexCounterState = "closed"
+	MutexCounterStateOpen   MutexCounterState = "open"
+)
+
+// String returns the name of the state.
+func (state MutexCounterState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state MutexCounterState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseMutexCounterState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *MutexCounterState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseMutexCounterState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseMutexCounterState returns the MutexCounterState with the supplied name.
+func ParseMutexCounterState(str string) (MutexCounterState, error) {
+	switch MutexCounterState(str) {
+	case MutexCounterStateClosed, MutexCounterStateOpen:
+		return MutexCounterState(str), nil
+	}
+	return "", errors.New("unknown mutex_counter state: " + str)
+}
+
+// MutexCounterEvent is an event that may be triggered on the MutexCounterMachine.
+type MutexCounterEvent string
+
+const (
+	MutexCounterEventOpen      MutexCounterEvent = "open"
+	MutexCounterEventIncrement MutexCounterEvent = "increment"
+	MutexCounterEventClose     MutexCounterEvent = "close"
+)
+
+// String returns the name of the event.
+func (event MutexCounterEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event MutexCounterEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseMutexCounterEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *MutexCounterEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseMutexCounterEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseMutexCounterEvent returns the MutexCounterEvent with the supplied name.
+func ParseMutexCounterEvent(str string) (MutexCounterEvent, error) {
+	switch MutexCounterEvent(str) {
+	case MutexCounterEventOpen, MutexCounterEventIncrement, MutexCounterEventClose:
+		return MutexCounterEvent(str), nil
+	}
+	return "", errors.New("unknown mutex_counter event: " + str)
+}
+
+// MutexCounterMachine is the mutex_counter state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type MutexCounterMachine struct {
+	*fsmruntime.Machine[MutexCounterState, MutexCounterEvent, Counter]
+
+	env Environment
+
+	OpenAction      func(ctx MutexCounterMachineContext, state *Counter, ev EventOpen) error
+	IncrementAction func(ctx MutexCounterMachineContext, state *Counter, ev EventIncrement) error
+	CloseAction     func(ctx MutexCounterMachineContext, state *Counter, ev EventClose) error
+
+	OnStateClosed func(ctx MutexCounterMachineContext, env Environment, state Counter) error
+	OnStateOpen   func(ctx MutexCounterMachineContext, env Environment, state Counter) error
+
+	OnExitClosed func(ctx MutexCounterMachineContext, env Environment, state Counter) error
+	OnExitOpen   func(ctx MutexCounterMachineContext, env Environment, state Counter) error
+}
+
+// MutexCounterTransition describes a transition of the MutexCounterMachine.
+type MutexCounterTransition = fsmruntime.Transition[MutexCounterState, MutexCounterEvent]
+
+// MutexCounterSnapshot is a serializable copy of the MutexCounterMachine's state, which
+// Restore resumes from.
+type MutexCounterSnapshot = fsmruntime.Snapshot[MutexCounterState, MutexCounterEvent, Counter]
+
+// MutexCounterTransitionRule describes a transition declared by the MutexCounterMachine's definition.
+type MutexCounterTransitionRule = fsmruntime.TransitionRule[MutexCounterState, MutexCounterEvent]
+
+// MutexCounterListener observes every event processed by a MutexCounterMachine.
+type MutexCounterListener = fsmruntime.Listener[MutexCounterState, MutexCounterEvent]
+
+// MutexCounterBaseListener implements MutexCounterListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type MutexCounterBaseListener = fsmruntime.BaseListener[MutexCounterState, MutexCounterEvent]
+
+// MutexCounterMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type MutexCounterMachineContext interface {
+	Context() context.Context
+	TriggerOpen(ev EventOpen) error
+	TriggerIncrement(ev EventIncrement) error
+	TriggerClose(ev EventClose) error
+}
+
+type mutexCounterMachineContext struct {
+	ctx     context.Context
+	machine *MutexCounterMachine
+}
+
+func newMutexCounterContext(ctx context.Context, machine *MutexCounterMachine) MutexCounterMachineContext {
+	return &mutexCounterMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx mutexCounterMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerOpen queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx mutexCounterMachineContext) TriggerOpen(ev EventOpen) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerOpen(ctx.ctx, ev)
+	})
+}
+
+// TriggerIncrement queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx mutexCounterMachineContext) TriggerIncrement(ev EventIncrement) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerIncrement(ctx.ctx, ev)
+	})
+}
+
+// TriggerClose queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx mutexCounterMachineContext) TriggerClose(ev EventClose) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerClose(ctx.ctx, ev)
+	})
+}
+
+// mutexCounterDefinition is the structure of the MutexCounterMachine, shared by every instance.
+var mutexCounterDefinition = &fsmruntime.Definition[MutexCounterState, MutexCounterEvent]{
+	Name:   "mutex_counter",
+	States: []MutexCounterState{MutexCounterStateClosed, MutexCounterStateOpen},
+	Events: []MutexCounterEvent{MutexCounterEventOpen, MutexCounterEventIncrement, MutexCounterEventClose},
+	Transitions: map[MutexCounterState]map[MutexCounterEvent]MutexCounterState{
+		"": {},
+		MutexCounterStateClosed: {
+			MutexCounterEventOpen: MutexCounterStateOpen,
+		},
+		MutexCounterStateOpen: {
+			MutexCounterEventClose:     MutexCounterStateClosed,
+			MutexCounterEventIncrement: MutexCounterStateOpen,
+		},
+	},
+	Parents: map[MutexCounterState]MutexCounterState{},
+	Initial: map[MutexCounterState]MutexCounterState{},
+	Regions: map[MutexCounterState][]MutexCounterState{},
+	Order: map[MutexCounterState]int{
+		MutexCounterStateClosed: 0,
+		MutexCounterStateOpen:   1,
+	},
+	Rules: []MutexCounterTransitionRule{
+		{From: MutexCounterStateClosed, Event: MutexCounterEventOpen, To: MutexCounterStateOpen},
+		{From: MutexCounterStateOpen, Event: MutexCounterEventIncrement, To: MutexCounterStateOpen},
+		{From: MutexCounterStateOpen, Event: MutexCounterEventClose, To: MutexCounterStateClosed},
+	},
+	MaxChainLength: 100,
+	Concurrency:    fsmruntime.ConcurrencyMutex,
+}
+
+// NewMutexCounterMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewMutexCounterMachine(state *Counter, env Environment) *MutexCounterMachine {
+	machine := &MutexCounterMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(mutexCounterDefinition, state, fsmruntime.Handlers[MutexCounterState, MutexCounterEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewMutexCounterMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewMutexCounterMachineFromSnapshot(snapshot MutexCounterSnapshot, env Environment) (*MutexCounterMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(Counter)
+	}
+	machine := NewMutexCounterMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *MutexCounterMachine) eventFunc(event MutexCounterEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case MutexCounterEventOpen:
+		ev, ok := payload.(EventOpen)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventOpen, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerOpen(ctx, ev)
+		}, nil
+	case MutexCounterEventIncrement:
+		ev, ok := payload.(EventIncrement)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventIncrement, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerIncrement(ctx, ev)
+		}, nil
+	case MutexCounterEventClose:
+		ev, ok := payload.(EventClose)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventClose, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerClose(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *MutexCounterMachine) exitState(ctx context.Context, transition MutexCounterTransition, state MutexCounterState) error {
+	switch state {
+	case MutexCounterStateClosed:
+		if machine.OnExitClosed == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitClosed", transition, machine.OnExitClosed(newMutexCounterContext(ctx, machine), machine.env, *machine.State))
+	case MutexCounterStateOpen:
+		if machine.OnExitOpen == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOpen", transition, machine.OnExitOpen(newMutexCounterContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *MutexCounterMachine) enterState(ctx context.Context, transition MutexCounterTransition, state MutexCounterState) error {
+	switch state {
+	case MutexCounterStateClosed:
+		if machine.OnStateClosed == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateClosed", transition, machine.OnStateClosed(newMutexCounterContext(ctx, machine), machine.env, *machine.State))
+	case MutexCounterStateOpen:
+		if machine.OnStateOpen == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOpen", transition, machine.OnStateOpen(newMutexCounterContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerOpen triggers the open event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *MutexCounterMachine) TriggerOpen(ctx context.Context, ev EventOpen) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerOpen(ctx, ev)
+	})
+}
+
+func (machine *MutexCounterMachine) triggerOpen(ctx context.Context, ev EventOpen) error {
+	return machine.Fire(ctx, MutexCounterEventOpen, ev, nil, func(ctx context.Context, transition MutexCounterTransition) error {
+		if machine.OpenAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("OpenAction", transition, machine.OpenAction(newMutexCounterContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerIncrement triggers the increment event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *MutexCounterMachine) TriggerIncrement(ctx context.Context, ev EventIncrement) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerIncrement(ctx, ev)
+	})
+}
+
+func (machine *MutexCounterMachine) triggerIncrement(ctx context.Context, ev EventIncrement) error {
+	return machine.Fire(ctx, MutexCounterEventIncrement, ev, nil, func(ctx context.Context, transition MutexCounterTransition) error {
+		if machine.IncrementAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("IncrementAction", transition, machine.IncrementAction(newMutexCounterContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerClose triggers the close event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *MutexCounterMachine) TriggerClose(ctx context.Context, ev EventClose) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerClose(ctx, ev)
+	})
+}
+
+func (machine *MutexCounterMachine) triggerClose(ctx context.Context, ev EventClose) error {
+	return machine.Fire(ctx, MutexCounterEventClose, ev, nil, func(ctx context.Context, transition MutexCounterTransition) error {
+		if machine.CloseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("CloseAction", transition, machine.CloseAction(newMutexCounterContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,20 @@
+package concurrent
+
+//go:generate go run gen/gen.go
+type Counter struct {
+	Count  int
+	Chains int
+}
+
+type Environment struct{}
+
+const (
+	StateClosed = "closed"
+	StateOpen   = "open"
+)
+
+type EventOpen struct{}
+type EventIncrement struct {
+	By int
+}
+type EventClose struct{}
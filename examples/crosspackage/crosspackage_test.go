@@ -0,0 +1,39 @@
+package crosspackage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/snikch/go-fsmgen/examples/crosspackage/domain"
+	"github.com/snikch/go-fsmgen/examples/crosspackage/events"
+	"gotest.tools/assert"
+)
+
+func TestCrossPackage(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPlayerMachine(&domain.State{}, &domain.Environment{})
+	machine.StartAction = func(ctx PlayerMachineContext, state *domain.State, ev events.Start) error {
+		state.Playing = ev.Track
+		return nil
+	}
+	machine.StopAction = func(ctx PlayerMachineContext, state *domain.State, ev *events.Stop) error {
+		state.Stopped = ev.Reason
+		return nil
+	}
+	machine.EnqueueAction = func(ctx PlayerMachineContext, state *domain.State, ev []events.Track) error {
+		for _, track := range ev {
+			state.Queue = append(state.Queue, track.Name)
+		}
+		return nil
+	}
+
+	assert.NilError(t, machine.TriggerStart(ctx, events.Start{Track: "intro"}))
+	assert.NilError(t, machine.TriggerEnqueue(ctx, []events.Track{{Name: "verse"}, {Name: "chorus"}}))
+	assert.NilError(t, machine.TriggerStop(ctx, &events.Stop{Reason: "done"}))
+	assert.Equal(t, PlayerStateIdle, machine.CurrentState)
+	assert.DeepEqual(t, &domain.State{
+		Playing: "intro",
+		Queue:   []string{"verse", "chorus"},
+		Stopped: "done",
+	}, machine.State)
+}
@@ -0,0 +1,5 @@
+// Package crosspackage demonstrates generating a machine into a package separate from its state, environment and
+// event types.
+package crosspackage
+
+//go:generate go run gen/gen.go
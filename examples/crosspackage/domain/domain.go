@@ -0,0 +1,11 @@
+package domain
+
+// State is the state object of the player machine.
+type State struct {
+	Playing string
+	Queue   []string
+	Stopped string
+}
+
+// Environment is the environment of the player machine.
+type Environment struct{}
@@ -0,0 +1,16 @@
+package events
+
+// Start starts playback of the supplied track.
+type Start struct {
+	Track string
+}
+
+// Stop stops playback.
+type Stop struct {
+	Reason string
+}
+
+// Track is a single entry in a queue of tracks.
+type Track struct {
+	Name string
+}
@@ -0,0 +1,24 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/crosspackage/domain"
+	"github.com/snikch/go-fsmgen/examples/crosspackage/events"
+)
+
+func main() {
+	gen := fsmgen.New("player", &domain.State{}, &domain.Environment{}, "idle", "playing")
+	gen.PackageName = "crosspackage"
+	gen.PackagePath = "github.com/snikch/go-fsmgen/examples/crosspackage"
+	gen.AddEvent(fsmgen.NewEvent("start", events.Start{}).From("idle").To("playing"))
+	gen.AddEvent(fsmgen.NewEvent("stop", &events.Stop{}).From("playing").To("idle"))
+	gen.AddEvent(fsmgen.NewEvent("enqueue", []events.Track{}).FromAny().To("playing"))
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
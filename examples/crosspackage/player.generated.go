@@ -0,0 +1,373 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package crosspackage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "github.com/snikch/go-fsmgen/examples/crosspackage/domain"
+	events "github.com/snikch/go-fsmgen/examples/crosspackage/events"
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateIdle    PlayerState = "idle"
+	PlayerStatePlaying PlayerState = "playing"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateIdle, PlayerStatePlaying:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventStart   PlayerEvent = "start"
+	PlayerEventStop    PlayerEvent = "stop"
+	PlayerEventEnqueue PlayerEvent = "enqueue"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventStart, PlayerEventStop, PlayerEventEnqueue:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, domain.State]
+
+	env *domain.Environment
+
+	StartAction   func(ctx PlayerMachineContext, state *domain.State, ev events.Start) error
+	StopAction    func(ctx PlayerMachineContext, state *domain.State, ev *events.Stop) error
+	EnqueueAction func(ctx PlayerMachineContext, state *domain.State, ev []events.Track) error
+
+	OnStateIdle    func(ctx PlayerMachineContext, env *domain.Environment, state domain.State) error
+	OnStatePlaying func(ctx PlayerMachineContext, env *domain.Environment, state domain.State) error
+
+	OnExitIdle    func(ctx PlayerMachineContext, env *domain.Environment, state domain.State) error
+	OnExitPlaying func(ctx PlayerMachineContext, env *domain.Environment, state domain.State) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, domain.State]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerStart(ev events.Start) error
+	TriggerStop(ev *events.Stop) error
+	TriggerEnqueue(ev []events.Track) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerStart queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStart(ev events.Start) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStart(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStop(ev *events.Stop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// TriggerEnqueue queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerEnqueue(ev []events.Track) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerEnqueue(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateIdle, PlayerStatePlaying},
+	Events: []PlayerEvent{PlayerEventStart, PlayerEventStop, PlayerEventEnqueue},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {
+			PlayerEventEnqueue: PlayerStatePlaying,
+		},
+		PlayerStateIdle: {
+			PlayerEventStart: PlayerStatePlaying,
+		},
+		PlayerStatePlaying: {
+			PlayerEventStop: PlayerStateIdle,
+		},
+	},
+	Parents: map[PlayerState]PlayerState{},
+	Initial: map[PlayerState]PlayerState{},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateIdle:    0,
+		PlayerStatePlaying: 1,
+	},
+	Rules: []PlayerTransitionRule{
+		{From: PlayerStateIdle, Event: PlayerEventStart, To: PlayerStatePlaying},
+		{From: PlayerStatePlaying, Event: PlayerEventStop, To: PlayerStateIdle},
+		{From: "", Event: PlayerEventEnqueue, To: PlayerStatePlaying},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *domain.State, env *domain.Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env *domain.Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(domain.State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventStart:
+		ev, ok := payload.(events.Start)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected events.Start, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStart(ctx, ev)
+		}, nil
+	case PlayerEventStop:
+		ev, ok := payload.(*events.Stop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected *events.Stop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	case PlayerEventEnqueue:
+		ev, ok := payload.([]events.Track)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected []events.Track, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerEnqueue(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateIdle:
+		if machine.OnExitIdle == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitIdle", transition, machine.OnExitIdle(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateIdle:
+		if machine.OnStateIdle == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateIdle", transition, machine.OnStateIdle(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerStart triggers the start event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStart(ctx context.Context, ev events.Start) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStart(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStart(ctx context.Context, ev events.Start) error {
+	return machine.Fire(ctx, PlayerEventStart, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StartAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StartAction", transition, machine.StartAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStop(ctx context.Context, ev *events.Stop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStop(ctx context.Context, ev *events.Stop) error {
+	return machine.Fire(ctx, PlayerEventStop, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerEnqueue triggers the enqueue event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerEnqueue(ctx context.Context, ev []events.Track) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerEnqueue(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerEnqueue(ctx context.Context, ev []events.Track) error {
+	return machine.Fire(ctx, PlayerEventEnqueue, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.EnqueueAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("EnqueueAction", transition, machine.EnqueueAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
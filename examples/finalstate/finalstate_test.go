@@ -2,8 +2,11 @@ package finalstate
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/snikch/go-fsmgen/runtime"
 	"gotest.tools/assert"
 )
 
@@ -34,10 +37,72 @@ func TestFinalState(t *testing.T) {
 		return nil
 	}
 
-	assert.Equal(t, "init", machine.CurrentState)
+	assert.Equal(t, InitFinalStateInit, machine.CurrentState)
 	err := machine.Start(context.Background())
 	assert.NilError(t, err)
-	assert.Equal(t, "final", machine.CurrentState)
+	assert.Equal(t, InitFinalStateFinal, machine.CurrentState)
 	assert.DeepEqual(t, expectedActions, actions)
 	assert.DeepEqual(t, expectedTransitions, transitions)
 }
+
+func TestDone(t *testing.T) {
+	machine := NewInitFinalMachine(&State{}, Environment{})
+	assert.Assert(t, !machine.IsDone())
+	assert.NilError(t, machine.TriggerRun(context.Background(), EventRun{}))
+	assert.NilError(t, machine.TriggerFinish(context.Background(), EventFinish{}))
+	assert.Assert(t, machine.IsDone())
+	select {
+	case <-machine.Done():
+	default:
+		t.Fatal("expected the done channel to be closed")
+	}
+
+	assert.Assert(t, !machine.CanTrigger(InitFinalEventRun))
+	err := machine.TriggerRun(context.Background(), EventRun{})
+	assert.Assert(t, errors.Is(err, runtime.ErrMachineDone))
+	assert.Error(t, err, "machine is done: run cannot be triggered from final state final")
+
+	restored, err := NewInitFinalMachineFromSnapshot(machine.Snapshot(), Environment{})
+	assert.NilError(t, err)
+	assert.Assert(t, restored.IsDone())
+	assert.NilError(t, restored.Restore(InitFinalSnapshot{Configuration: []InitFinalState{InitFinalStateRunning}}))
+	assert.Assert(t, !restored.IsDone())
+}
+
+func TestCompletion(t *testing.T) {
+	completed := false
+	machine := NewJobMachine(&State{}, Environment{})
+	machine.SucceedAction = func(ctx JobMachineContext, state *State, ev EventSucceed) error {
+		completed = true
+		return nil
+	}
+	ctx := context.Background()
+	assert.NilError(t, machine.TriggerRun(ctx, EventRun{}))
+	assert.NilError(t, machine.TriggerDownload(ctx, EventDownload{}))
+	assert.DeepEqual(t, []JobState{JobStateRunning, JobStateDownload, JobStateDownloaded, JobStateVerify, JobStateVerifying}, machine.Configuration())
+	assert.Assert(t, !completed)
+
+	assert.NilError(t, machine.TriggerVerify(ctx, EventVerify{}))
+	assert.Assert(t, completed)
+	assert.Equal(t, JobStateSucceeded, machine.Current())
+	assert.Assert(t, machine.IsDone())
+}
+
+func TestStateText(t *testing.T) {
+	state, err := ParseInitFinalState(StateRunning)
+	assert.NilError(t, err)
+	assert.Equal(t, InitFinalStateRunning, state)
+	_, err = ParseInitFinalState("unknown")
+	assert.Error(t, err, "unknown init_final state: unknown")
+
+	out, err := json.Marshal(map[string]InitFinalState{"state": InitFinalStateFinal})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"state":"final"}`, string(out))
+	_, err = json.Marshal(InitFinalState("unknown"))
+	assert.ErrorContains(t, err, "unknown init_final state: unknown")
+
+	var event InitFinalEvent
+	assert.NilError(t, json.Unmarshal([]byte(`"finish"`), &event))
+	assert.Equal(t, InitFinalEventFinish, event)
+	assert.ErrorContains(t, json.Unmarshal([]byte(`"init"`), &event), "unknown init_final event: init")
+}
@@ -14,8 +14,27 @@ func main() {
 	gen.PackageName = "finalstate"
 	gen.AddEvent(fsmgen.NewEvent("run", finalstate.EventRun{}).From(finalstate.StateInit).To(finalstate.StateRunning))
 	gen.AddEvent(fsmgen.NewEvent("finish", finalstate.EventFinish{}).From(finalstate.StateRunning).To(finalstate.StateFinal))
+	gen.Final(finalstate.StateFinal)
 	err := gen.Write()
 	if err != nil {
 		log.Panic(err)
 	}
+
+	// A job that completes once both of its parallel regions have reached a final state.
+	job := fsmgen.New("job", finalstate.State{}, finalstate.Environment{}, finalstate.StateQueued, finalstate.StateRunning,
+		finalstate.StateDownload, finalstate.StateDownloading, finalstate.StateDownloaded,
+		finalstate.StateVerify, finalstate.StateVerifying, finalstate.StateVerified, finalstate.StateSucceeded)
+	job.PackageName = "finalstate"
+	job.AddRegions(finalstate.StateRunning, finalstate.StateDownload, finalstate.StateVerify)
+	job.AddSubstates(finalstate.StateDownload, finalstate.StateDownloading, finalstate.StateDownloaded)
+	job.AddSubstates(finalstate.StateVerify, finalstate.StateVerifying, finalstate.StateVerified)
+	job.Final(finalstate.StateDownloaded, finalstate.StateVerified, finalstate.StateSucceeded)
+	job.AddEvent(fsmgen.NewEvent("run", finalstate.EventRun{}).From(finalstate.StateQueued).To(finalstate.StateRunning))
+	job.AddEvent(fsmgen.NewEvent("download", finalstate.EventDownload{}).From(finalstate.StateDownloading).To(finalstate.StateDownloaded))
+	job.AddEvent(fsmgen.NewEvent("verify", finalstate.EventVerify{}).From(finalstate.StateVerifying).To(finalstate.StateVerified))
+	job.AddEvent(fsmgen.NewEvent("succeed", finalstate.EventSucceed{}).From(finalstate.StateRunning).To(finalstate.StateSucceeded).OnCompletion())
+	err = job.Write()
+	if err != nil {
+		log.Panic(err)
+	}
 }
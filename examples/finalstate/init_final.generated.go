@@ -1,29 +1,154 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
 
 package finalstate
 
-// Code generated by go-fsmgen DO NOT EDIT.
-
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// InitFinalState is a state the InitFinalMachine may be in.
+type InitFinalState string
+
+const (
+	InitFinalStateInit    InitFinalState = "init"
+	InitFinalStateRunning InitFinalState = "running"
+	InitFinalStateFinal   InitFinalState = "final"
 )
 
+// String returns the name of the state.
+func (state InitFinalState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state InitFinalState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseInitFinalState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *InitFinalState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseInitFinalState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseInitFinalState returns the InitFinalState with the supplied name.
+func ParseInitFinalState(str string) (InitFinalState, error) {
+	switch InitFinalState(str) {
+	case InitFinalStateInit, InitFinalStateRunning, InitFinalStateFinal:
+		return InitFinalState(str), nil
+	}
+	return "", errors.New("unknown init_final state: " + str)
+}
+
+// InitFinalEvent is an event that may be triggered on the InitFinalMachine.
+type InitFinalEvent string
+
+const (
+	InitFinalEventRun    InitFinalEvent = "run"
+	InitFinalEventFinish InitFinalEvent = "finish"
+)
+
+// String returns the name of the event.
+func (event InitFinalEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event InitFinalEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseInitFinalEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *InitFinalEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseInitFinalEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseInitFinalEvent returns the InitFinalEvent with the supplied name.
+func ParseInitFinalEvent(str string) (InitFinalEvent, error) {
+	switch InitFinalEvent(str) {
+	case InitFinalEventRun, InitFinalEventFinish:
+		return InitFinalEvent(str), nil
+	}
+	return "", errors.New("unknown init_final event: " + str)
+}
+
+// InitFinalMachine is the init_final state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
 type InitFinalMachine struct {
-	CurrentState string
-	State *State
+	*fsmruntime.Machine[InitFinalState, InitFinalEvent, State]
 
 	env Environment
-	transitions  map[string]map[string]string
-
 
-	RunAction func(ctx InitFinalMachineContext, state *State, ev EventRun) error
+	RunAction    func(ctx InitFinalMachineContext, state *State, ev EventRun) error
 	FinishAction func(ctx InitFinalMachineContext, state *State, ev EventFinish) error
 
-	OnStateInit func(ctx InitFinalMachineContext, env Environment, state State) error
+	OnStateInit    func(ctx InitFinalMachineContext, env Environment, state State) error
 	OnStateRunning func(ctx InitFinalMachineContext, env Environment, state State) error
-	OnStateFinal func(ctx InitFinalMachineContext, env Environment, state State) error
+	OnStateFinal   func(ctx InitFinalMachineContext, env Environment, state State) error
+
+	OnExitInit    func(ctx InitFinalMachineContext, env Environment, state State) error
+	OnExitRunning func(ctx InitFinalMachineContext, env Environment, state State) error
+	OnExitFinal   func(ctx InitFinalMachineContext, env Environment, state State) error
 }
 
+// InitFinalTransition describes a transition of the InitFinalMachine.
+type InitFinalTransition = fsmruntime.Transition[InitFinalState, InitFinalEvent]
+
+// InitFinalSnapshot is a serializable copy of the InitFinalMachine's state, which
+// Restore resumes from.
+type InitFinalSnapshot = fsmruntime.Snapshot[InitFinalState, InitFinalEvent, State]
+
+// InitFinalTransitionRule describes a transition declared by the InitFinalMachine's definition.
+type InitFinalTransitionRule = fsmruntime.TransitionRule[InitFinalState, InitFinalEvent]
+
+// InitFinalListener observes every event processed by a InitFinalMachine.
+type InitFinalListener = fsmruntime.Listener[InitFinalState, InitFinalEvent]
+
+// InitFinalBaseListener implements InitFinalListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type InitFinalBaseListener = fsmruntime.BaseListener[InitFinalState, InitFinalEvent]
+
+// InitFinalMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
 type InitFinalMachineContext interface {
 	Context() context.Context
 	TriggerRun(ev EventRun) error
@@ -31,13 +156,13 @@ type InitFinalMachineContext interface {
 }
 
 type initFinalMachineContext struct {
-	ctx context.Context
+	ctx     context.Context
 	machine *InitFinalMachine
 }
 
 func newInitFinalContext(ctx context.Context, machine *InitFinalMachine) InitFinalMachineContext {
 	return &initFinalMachineContext{
-		ctx: ctx,
+		ctx:     ctx,
 		machine: machine,
 	}
 }
@@ -46,97 +171,180 @@ func (ctx initFinalMachineContext) Context() context.Context {
 	return ctx.ctx
 }
 
+// TriggerRun queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx initFinalMachineContext) TriggerRun(ev EventRun) error {
-	return ctx.machine.TriggerRun(ctx.ctx, ev)
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerRun(ctx.ctx, ev)
+	})
 }
+
+// TriggerFinish queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
 func (ctx initFinalMachineContext) TriggerFinish(ev EventFinish) error {
-	return ctx.machine.TriggerFinish(ctx.ctx, ev)
-}
-
-func NewInitFinalMachine(state *State, env Environment) *InitFinalMachine{
-	return &InitFinalMachine{
-		State:        state,
-		CurrentState: "init",
-		env:          env,
-		transitions:  map[string]map[string]string{
-				"": {
-				},
-				"final": {
-				},
-				"init": {
-					"run": "running",
-				},
-				"running": {
-					"finish": "final",
-				},
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerFinish(ctx.ctx, ev)
+	})
+}
+
+// initFinalDefinition is the structure of the InitFinalMachine, shared by every instance.
+var initFinalDefinition = &fsmruntime.Definition[InitFinalState, InitFinalEvent]{
+	Name:   "init_final",
+	States: []InitFinalState{InitFinalStateInit, InitFinalStateRunning, InitFinalStateFinal},
+	Events: []InitFinalEvent{InitFinalEventRun, InitFinalEventFinish},
+	Transitions: map[InitFinalState]map[InitFinalEvent]InitFinalState{
+		"":                  {},
+		InitFinalStateFinal: {},
+		InitFinalStateInit: {
+			InitFinalEventRun: InitFinalStateRunning,
+		},
+		InitFinalStateRunning: {
+			InitFinalEventFinish: InitFinalStateFinal,
 		},
+	},
+	Parents: map[InitFinalState]InitFinalState{},
+	Initial: map[InitFinalState]InitFinalState{},
+	Regions: map[InitFinalState][]InitFinalState{},
+	Order: map[InitFinalState]int{
+		InitFinalStateInit:    0,
+		InitFinalStateRunning: 1,
+		InitFinalStateFinal:   2,
+	},
+	Final: map[InitFinalState]bool{
+		InitFinalStateFinal: true,
+	},
+	Rules: []InitFinalTransitionRule{
+		{From: InitFinalStateInit, Event: InitFinalEventRun, To: InitFinalStateRunning},
+		{From: InitFinalStateRunning, Event: InitFinalEventFinish, To: InitFinalStateFinal},
+	},
+	MaxChainLength: 100,
+}
+
+// NewInitFinalMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewInitFinalMachine(state *State, env Environment) *InitFinalMachine {
+	machine := &InitFinalMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(initFinalDefinition, state, fsmruntime.Handlers[InitFinalState, InitFinalEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
 	}
+	return machine
 }
 
-func (machine *InitFinalMachine) Start(ctx context.Context) (error) {
-	return machine.didEnterState(ctx)
+// NewInitFinalMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewInitFinalMachineFromSnapshot(snapshot InitFinalSnapshot, env Environment) (*InitFinalMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewInitFinalMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
 }
 
-func (machine *InitFinalMachine) getState(event string) (string, error) {
-	target := machine.transitions[machine.CurrentState][event]
-	if target != "" {
-		return target, nil
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *InitFinalMachine) eventFunc(event InitFinalEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case InitFinalEventRun:
+		ev, ok := payload.(EventRun)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventRun, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerRun(ctx, ev)
+		}, nil
+	case InitFinalEventFinish:
+		ev, ok := payload.(EventFinish)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventFinish, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerFinish(ctx, ev)
+		}, nil
 	}
-	target = machine.transitions[""][event]
-	if target != "" {
-		return target, nil
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *InitFinalMachine) exitState(ctx context.Context, transition InitFinalTransition, state InitFinalState) error {
+	switch state {
+	case InitFinalStateInit:
+		if machine.OnExitInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitInit", transition, machine.OnExitInit(newInitFinalContext(ctx, machine), machine.env, *machine.State))
+	case InitFinalStateRunning:
+		if machine.OnExitRunning == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitRunning", transition, machine.OnExitRunning(newInitFinalContext(ctx, machine), machine.env, *machine.State))
+	case InitFinalStateFinal:
+		if machine.OnExitFinal == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitFinal", transition, machine.OnExitFinal(newInitFinalContext(ctx, machine), machine.env, *machine.State))
 	}
-	return "", errors.New("invalid transition: no transition target from " + machine.CurrentState + " via " + event)
+	return nil
 }
 
-func (machine *InitFinalMachine) didEnterState(ctx context.Context) error {
-	switch machine.CurrentState {
-	case "init":
+func (machine *InitFinalMachine) enterState(ctx context.Context, transition InitFinalTransition, state InitFinalState) error {
+	switch state {
+	case InitFinalStateInit:
 		if machine.OnStateInit == nil {
 			break
 		}
-		return machine.OnStateInit(newInitFinalContext(ctx, machine), machine.env, *machine.State)
-	case "running":
+		return fsmruntime.WrapHandlerError("OnStateInit", transition, machine.OnStateInit(newInitFinalContext(ctx, machine), machine.env, *machine.State))
+	case InitFinalStateRunning:
 		if machine.OnStateRunning == nil {
 			break
 		}
-		return machine.OnStateRunning(newInitFinalContext(ctx, machine), machine.env, *machine.State)
-	case "final":
+		return fsmruntime.WrapHandlerError("OnStateRunning", transition, machine.OnStateRunning(newInitFinalContext(ctx, machine), machine.env, *machine.State))
+	case InitFinalStateFinal:
 		if machine.OnStateFinal == nil {
 			break
 		}
-		return machine.OnStateFinal(newInitFinalContext(ctx, machine), machine.env, *machine.State)
+		return fsmruntime.WrapHandlerError("OnStateFinal", transition, machine.OnStateFinal(newInitFinalContext(ctx, machine), machine.env, *machine.State))
 	}
 	return nil
 }
 
-func (machine *InitFinalMachine) TriggerRun (ctx context.Context, ev EventRun) error {
-	target, err := machine.getState("run")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.RunAction != nil {
-		err := machine.RunAction(newInitFinalContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
-		}
-	}
-	return machine.didEnterState(ctx)
+// TriggerRun triggers the run event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *InitFinalMachine) TriggerRun(ctx context.Context, ev EventRun) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerRun(ctx, ev)
+	})
 }
 
-func (machine *InitFinalMachine) TriggerFinish (ctx context.Context, ev EventFinish) error {
-	target, err := machine.getState("finish")
-	if err != nil {
-	return err
-	}
-	machine.CurrentState = target
-	if machine.FinishAction != nil {
-		err := machine.FinishAction(newInitFinalContext(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
+func (machine *InitFinalMachine) triggerRun(ctx context.Context, ev EventRun) error {
+	return machine.Fire(ctx, InitFinalEventRun, ev, nil, func(ctx context.Context, transition InitFinalTransition) error {
+		if machine.RunAction == nil {
+			return nil
 		}
-	}
-	return machine.didEnterState(ctx)
+		return fsmruntime.WrapHandlerError("RunAction", transition, machine.RunAction(newInitFinalContext(ctx, machine), machine.State, ev))
+	})
 }
 
+// TriggerFinish triggers the finish event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *InitFinalMachine) TriggerFinish(ctx context.Context, ev EventFinish) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerFinish(ctx, ev)
+	})
+}
+
+func (machine *InitFinalMachine) triggerFinish(ctx context.Context, ev EventFinish) error {
+	return machine.Fire(ctx, InitFinalEventFinish, ev, nil, func(ctx context.Context, transition InitFinalTransition) error {
+		if machine.FinishAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("FinishAction", transition, machine.FinishAction(newInitFinalContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,546 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package finalstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// JobState is a state the JobMachine may be in.
+type JobState string
+
+const (
+	JobStateQueued      JobState = "queued"
+	JobStateRunning     JobState = "running"
+	JobStateDownload    JobState = "download"
+	JobStateDownloading JobState = "downloading"
+	JobStateDownloaded  JobState = "downloaded"
+	JobStateVerify      JobState = "verify"
+	JobStateVerifying   JobState = "verifying"
+	JobStateVerified    JobState = "verified"
+	JobStateSucceeded   JobState = "succeeded"
+)
+
+// String returns the name of the state.
+func (state JobState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state JobState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseJobState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *JobState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseJobState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseJobState returns the JobState with the supplied name.
+func ParseJobState(str string) (JobState, error) {
+	switch JobState(str) {
+	case JobStateQueued, JobStateRunning, JobStateDownload, JobStateDownloading, JobStateDownloaded, JobStateVerify, JobStateVerifying, JobStateVerified, JobStateSucceeded:
+		return JobState(str), nil
+	}
+	return "", errors.New("unknown job state: " + str)
+}
+
+// JobEvent is an event that may be triggered on the JobMachine.
+type JobEvent string
+
+const (
+	JobEventRun      JobEvent = "run"
+	JobEventDownload JobEvent = "download"
+	JobEventVerify   JobEvent = "verify"
+	JobEventSucceed  JobEvent = "succeed"
+)
+
+// String returns the name of the event.
+func (event JobEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event JobEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseJobEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *JobEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseJobEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseJobEvent returns the JobEvent with the supplied name.
+func ParseJobEvent(str string) (JobEvent, error) {
+	switch JobEvent(str) {
+	case JobEventRun, JobEventDownload, JobEventVerify, JobEventSucceed:
+		return JobEvent(str), nil
+	}
+	return "", errors.New("unknown job event: " + str)
+}
+
+// JobMachine is the job state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type JobMachine struct {
+	*fsmruntime.Machine[JobState, JobEvent, State]
+
+	env Environment
+
+	RunAction      func(ctx JobMachineContext, state *State, ev EventRun) error
+	DownloadAction func(ctx JobMachineContext, state *State, ev EventDownload) error
+	VerifyAction   func(ctx JobMachineContext, state *State, ev EventVerify) error
+	SucceedAction  func(ctx JobMachineContext, state *State, ev EventSucceed) error
+
+	OnStateQueued      func(ctx JobMachineContext, env Environment, state State) error
+	OnStateRunning     func(ctx JobMachineContext, env Environment, state State) error
+	OnStateDownload    func(ctx JobMachineContext, env Environment, state State) error
+	OnStateDownloading func(ctx JobMachineContext, env Environment, state State) error
+	OnStateDownloaded  func(ctx JobMachineContext, env Environment, state State) error
+	OnStateVerify      func(ctx JobMachineContext, env Environment, state State) error
+	OnStateVerifying   func(ctx JobMachineContext, env Environment, state State) error
+	OnStateVerified    func(ctx JobMachineContext, env Environment, state State) error
+	OnStateSucceeded   func(ctx JobMachineContext, env Environment, state State) error
+
+	OnExitQueued      func(ctx JobMachineContext, env Environment, state State) error
+	OnExitRunning     func(ctx JobMachineContext, env Environment, state State) error
+	OnExitDownload    func(ctx JobMachineContext, env Environment, state State) error
+	OnExitDownloading func(ctx JobMachineContext, env Environment, state State) error
+	OnExitDownloaded  func(ctx JobMachineContext, env Environment, state State) error
+	OnExitVerify      func(ctx JobMachineContext, env Environment, state State) error
+	OnExitVerifying   func(ctx JobMachineContext, env Environment, state State) error
+	OnExitVerified    func(ctx JobMachineContext, env Environment, state State) error
+	OnExitSucceeded   func(ctx JobMachineContext, env Environment, state State) error
+}
+
+// JobTransition describes a transition of the JobMachine.
+type JobTransition = fsmruntime.Transition[JobState, JobEvent]
+
+// JobSnapshot is a serializable copy of the JobMachine's state, which
+// Restore resumes from.
+type JobSnapshot = fsmruntime.Snapshot[JobState, JobEvent, State]
+
+// JobTransitionRule describes a transition declared by the JobMachine's definition.
+type JobTransitionRule = fsmruntime.TransitionRule[JobState, JobEvent]
+
+// JobListener observes every event processed by a JobMachine.
+type JobListener = fsmruntime.Listener[JobState, JobEvent]
+
+// JobBaseListener implements JobListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type JobBaseListener = fsmruntime.BaseListener[JobState, JobEvent]
+
+// JobMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type JobMachineContext interface {
+	Context() context.Context
+	TriggerRun(ev EventRun) error
+	TriggerDownload(ev EventDownload) error
+	TriggerVerify(ev EventVerify) error
+	TriggerSucceed(ev EventSucceed) error
+}
+
+type jobMachineContext struct {
+	ctx     context.Context
+	machine *JobMachine
+}
+
+func newJobContext(ctx context.Context, machine *JobMachine) JobMachineContext {
+	return &jobMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx jobMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerRun queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx jobMachineContext) TriggerRun(ev EventRun) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerRun(ctx.ctx, ev)
+	})
+}
+
+// TriggerDownload queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx jobMachineContext) TriggerDownload(ev EventDownload) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerDownload(ctx.ctx, ev)
+	})
+}
+
+// TriggerVerify queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx jobMachineContext) TriggerVerify(ev EventVerify) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerVerify(ctx.ctx, ev)
+	})
+}
+
+// TriggerSucceed queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx jobMachineContext) TriggerSucceed(ev EventSucceed) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerSucceed(ctx.ctx, ev)
+	})
+}
+
+// jobDefinition is the structure of the JobMachine, shared by every instance.
+var jobDefinition = &fsmruntime.Definition[JobState, JobEvent]{
+	Name:   "job",
+	States: []JobState{JobStateQueued, JobStateRunning, JobStateDownload, JobStateDownloading, JobStateDownloaded, JobStateVerify, JobStateVerifying, JobStateVerified, JobStateSucceeded},
+	Events: []JobEvent{JobEventRun, JobEventDownload, JobEventVerify, JobEventSucceed},
+	Transitions: map[JobState]map[JobEvent]JobState{
+		"":                 {},
+		JobStateDownload:   {},
+		JobStateDownloaded: {},
+		JobStateDownloading: {
+			JobEventDownload: JobStateDownloaded,
+		},
+		JobStateQueued: {
+			JobEventRun: JobStateRunning,
+		},
+		JobStateRunning: {
+			JobEventSucceed: JobStateSucceeded,
+		},
+		JobStateSucceeded: {},
+		JobStateVerified:  {},
+		JobStateVerify:    {},
+		JobStateVerifying: {
+			JobEventVerify: JobStateVerified,
+		},
+	},
+	Parents: map[JobState]JobState{
+		JobStateDownload:    JobStateRunning,
+		JobStateDownloaded:  JobStateDownload,
+		JobStateDownloading: JobStateDownload,
+		JobStateVerified:    JobStateVerify,
+		JobStateVerify:      JobStateRunning,
+		JobStateVerifying:   JobStateVerify,
+	},
+	Initial: map[JobState]JobState{
+		JobStateDownload: JobStateDownloading,
+		JobStateVerify:   JobStateVerifying,
+	},
+	Regions: map[JobState][]JobState{
+		JobStateRunning: {JobStateDownload, JobStateVerify},
+	},
+	Order: map[JobState]int{
+		JobStateQueued:      0,
+		JobStateRunning:     1,
+		JobStateDownload:    2,
+		JobStateDownloading: 3,
+		JobStateDownloaded:  4,
+		JobStateVerify:      5,
+		JobStateVerifying:   6,
+		JobStateVerified:    7,
+		JobStateSucceeded:   8,
+	},
+	Final: map[JobState]bool{
+		JobStateDownloaded: true,
+		JobStateSucceeded:  true,
+		JobStateVerified:   true,
+	},
+	Completions: map[JobState][]JobEvent{
+		JobStateRunning: {JobEventSucceed},
+	},
+	Rules: []JobTransitionRule{
+		{From: JobStateQueued, Event: JobEventRun, To: JobStateRunning},
+		{From: JobStateDownloading, Event: JobEventDownload, To: JobStateDownloaded},
+		{From: JobStateVerifying, Event: JobEventVerify, To: JobStateVerified},
+		{From: JobStateRunning, Event: JobEventSucceed, To: JobStateSucceeded},
+	},
+	MaxChainLength: 100,
+}
+
+// NewJobMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewJobMachine(state *State, env Environment) *JobMachine {
+	machine := &JobMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(jobDefinition, state, fsmruntime.Handlers[JobState, JobEvent]{
+		Enter:     machine.enterState,
+		Exit:      machine.exitState,
+		Event:     machine.eventFunc,
+		Automatic: machine.triggerAutomatic,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewJobMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewJobMachineFromSnapshot(snapshot JobSnapshot, env Environment) (*JobMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewJobMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *JobMachine) eventFunc(event JobEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case JobEventRun:
+		ev, ok := payload.(EventRun)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventRun, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerRun(ctx, ev)
+		}, nil
+	case JobEventDownload:
+		ev, ok := payload.(EventDownload)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventDownload, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerDownload(ctx, ev)
+		}, nil
+	case JobEventVerify:
+		ev, ok := payload.(EventVerify)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventVerify, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerVerify(ctx, ev)
+		}, nil
+	case JobEventSucceed:
+		ev, ok := payload.(EventSucceed)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventSucceed, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerSucceed(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+// triggerAutomatic processes the supplied delayed or completion event with a zero value event object.
+func (machine *JobMachine) triggerAutomatic(ctx context.Context, event JobEvent) error {
+	switch event {
+	case JobEventSucceed:
+		var ev EventSucceed
+		return machine.triggerSucceed(ctx, ev)
+	}
+	return fmt.Errorf("unknown automatic event %s", event)
+}
+
+func (machine *JobMachine) exitState(ctx context.Context, transition JobTransition, state JobState) error {
+	switch state {
+	case JobStateQueued:
+		if machine.OnExitQueued == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitQueued", transition, machine.OnExitQueued(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateRunning:
+		if machine.OnExitRunning == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitRunning", transition, machine.OnExitRunning(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownload:
+		if machine.OnExitDownload == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitDownload", transition, machine.OnExitDownload(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownloading:
+		if machine.OnExitDownloading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitDownloading", transition, machine.OnExitDownloading(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownloaded:
+		if machine.OnExitDownloaded == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitDownloaded", transition, machine.OnExitDownloaded(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerify:
+		if machine.OnExitVerify == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitVerify", transition, machine.OnExitVerify(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerifying:
+		if machine.OnExitVerifying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitVerifying", transition, machine.OnExitVerifying(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerified:
+		if machine.OnExitVerified == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitVerified", transition, machine.OnExitVerified(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateSucceeded:
+		if machine.OnExitSucceeded == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitSucceeded", transition, machine.OnExitSucceeded(newJobContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *JobMachine) enterState(ctx context.Context, transition JobTransition, state JobState) error {
+	switch state {
+	case JobStateQueued:
+		if machine.OnStateQueued == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateQueued", transition, machine.OnStateQueued(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateRunning:
+		if machine.OnStateRunning == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateRunning", transition, machine.OnStateRunning(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownload:
+		if machine.OnStateDownload == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateDownload", transition, machine.OnStateDownload(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownloading:
+		if machine.OnStateDownloading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateDownloading", transition, machine.OnStateDownloading(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateDownloaded:
+		if machine.OnStateDownloaded == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateDownloaded", transition, machine.OnStateDownloaded(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerify:
+		if machine.OnStateVerify == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateVerify", transition, machine.OnStateVerify(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerifying:
+		if machine.OnStateVerifying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateVerifying", transition, machine.OnStateVerifying(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateVerified:
+		if machine.OnStateVerified == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateVerified", transition, machine.OnStateVerified(newJobContext(ctx, machine), machine.env, *machine.State))
+	case JobStateSucceeded:
+		if machine.OnStateSucceeded == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateSucceeded", transition, machine.OnStateSucceeded(newJobContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerRun triggers the run event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *JobMachine) TriggerRun(ctx context.Context, ev EventRun) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerRun(ctx, ev)
+	})
+}
+
+func (machine *JobMachine) triggerRun(ctx context.Context, ev EventRun) error {
+	return machine.Fire(ctx, JobEventRun, ev, nil, func(ctx context.Context, transition JobTransition) error {
+		if machine.RunAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("RunAction", transition, machine.RunAction(newJobContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerDownload triggers the download event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *JobMachine) TriggerDownload(ctx context.Context, ev EventDownload) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerDownload(ctx, ev)
+	})
+}
+
+func (machine *JobMachine) triggerDownload(ctx context.Context, ev EventDownload) error {
+	return machine.Fire(ctx, JobEventDownload, ev, nil, func(ctx context.Context, transition JobTransition) error {
+		if machine.DownloadAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("DownloadAction", transition, machine.DownloadAction(newJobContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerVerify triggers the verify event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *JobMachine) TriggerVerify(ctx context.Context, ev EventVerify) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerVerify(ctx, ev)
+	})
+}
+
+func (machine *JobMachine) triggerVerify(ctx context.Context, ev EventVerify) error {
+	return machine.Fire(ctx, JobEventVerify, ev, nil, func(ctx context.Context, transition JobTransition) error {
+		if machine.VerifyAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("VerifyAction", transition, machine.VerifyAction(newJobContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerSucceed triggers the succeed event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *JobMachine) TriggerSucceed(ctx context.Context, ev EventSucceed) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerSucceed(ctx, ev)
+	})
+}
+
+func (machine *JobMachine) triggerSucceed(ctx context.Context, ev EventSucceed) error {
+	return machine.Fire(ctx, JobEventSucceed, ev, nil, func(ctx context.Context, transition JobTransition) error {
+		if machine.SucceedAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("SucceedAction", transition, machine.SucceedAction(newJobContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -10,8 +10,20 @@ const (
 	StateInit    = "init"
 	StateRunning = "running"
 	StateFinal   = "final"
+
+	StateQueued      = "queued"
+	StateDownload    = "download"
+	StateDownloading = "downloading"
+	StateDownloaded  = "downloaded"
+	StateVerify      = "verify"
+	StateVerifying   = "verifying"
+	StateVerified    = "verified"
+	StateSucceeded   = "succeeded"
 )
 
 type EventInit struct{}
 type EventRun struct{}
 type EventFinish struct{}
+type EventDownload struct{}
+type EventVerify struct{}
+type EventSucceed struct{}
@@ -0,0 +1,36 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/guards"
+)
+
+func main() {
+	gen := fsmgen.New("player", guards.State{}, guards.Environment{}, guards.StateInit, guards.StateBuffering, guards.StatePlaying)
+	gen.PackageName = "guards"
+	gen.AddEvent(fsmgen.NewEvent("load", guards.EventLoad{}).FromAny().To(guards.StateInit))
+	// Loading over a playing file is only allowed while unlocked.
+	gen.AddEvent(fsmgen.NewEvent("load", guards.EventLoad{}).From(guards.StatePlaying).
+		Guard("unlocked").
+		To(guards.StateInit).
+		Overrides())
+	// Play only if a file is loaded, otherwise stay in init.
+	gen.AddEvent(fsmgen.NewEvent("play", guards.EventPlay{}).From(guards.StateInit).
+		Branch("file_loaded", guards.StatePlaying).
+		To(guards.StateInit))
+	// Resume straight to playing if buffered, otherwise buffer if there is a file. Rejected when neither pass.
+	gen.AddEvent(fsmgen.NewEvent("resume", guards.EventResume{}).From(guards.StateInit).
+		Branch("buffered", guards.StatePlaying).
+		Branch("file_loaded", guards.StateBuffering))
+	gen.AddEvent(fsmgen.NewEvent("stop", guards.EventStop{}).From(guards.StateBuffering, guards.StatePlaying).
+		Guard("unlocked").
+		To(guards.StateInit))
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
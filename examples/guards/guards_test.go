@@ -0,0 +1,128 @@
+package guards
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func newMachine() *PlayerMachine {
+	machine := NewPlayerMachine(&State{}, Environment{})
+	machine.LoadAction = func(ctx PlayerMachineContext, state *State, ev EventLoad) error {
+		state.File = ev.File
+		return nil
+	}
+	machine.PlayFileLoadedGuard = func(ctx PlayerMachineContext, state State, ev EventPlay) bool {
+		return state.File != ""
+	}
+	machine.ResumeBufferedGuard = func(ctx PlayerMachineContext, state State, ev EventResume) bool {
+		return state.Buffered
+	}
+	machine.ResumeFileLoadedGuard = func(ctx PlayerMachineContext, state State, ev EventResume) bool {
+		return state.File != ""
+	}
+	machine.StopUnlockedGuard = func(ctx PlayerMachineContext, state State, ev EventStop) bool {
+		return !state.Locked
+	}
+	machine.LoadUnlockedGuard = func(ctx PlayerMachineContext, state State, ev EventLoad) bool {
+		return !state.Locked
+	}
+	return machine
+}
+
+func TestBranchFallsThroughToTarget(t *testing.T) {
+	ctx := context.Background()
+	machine := newMachine()
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{File: "song.mp3"}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+}
+
+func TestBranchesEvaluatedInOrder(t *testing.T) {
+	ctx := context.Background()
+	machine := newMachine()
+	machine.State.File = "song.mp3"
+	machine.State.Buffered = true
+	assert.NilError(t, machine.TriggerResume(ctx, EventResume{}))
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+
+	machine = newMachine()
+	machine.State.File = "song.mp3"
+	assert.NilError(t, machine.TriggerResume(ctx, EventResume{}))
+	assert.Equal(t, PlayerStateBuffering, machine.CurrentState)
+}
+
+func TestGuardRejected(t *testing.T) {
+	ctx := context.Background()
+	machine := newMachine()
+	err := machine.TriggerResume(ctx, EventResume{})
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrGuardRejected))
+	assert.Error(t, err, "guard rejected transition: no guard passed for resume from init")
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+
+	machine.State.File = "song.mp3"
+	machine.State.Locked = true
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	err = machine.TriggerStop(ctx, EventStop{})
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrGuardRejected))
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+
+	// A guard without a hook never passes.
+	machine.StopUnlockedGuard = nil
+	machine.State.Locked = false
+	assert.Assert(t, errors.Is(machine.TriggerStop(ctx, EventStop{}), fsmruntime.ErrGuardRejected))
+}
+
+func TestOverride(t *testing.T) {
+	ctx := context.Background()
+	machine := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{File: "song.mp3"}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	machine.State.Locked = true
+	err := machine.TriggerLoad(ctx, EventLoad{File: "other.mp3"})
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrGuardRejected))
+	assert.Equal(t, "song.mp3", machine.State.File)
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+
+	machine.State.Locked = false
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{File: "other.mp3"}))
+	assert.Equal(t, "other.mp3", machine.State.File)
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+}
+
+func TestAvailableEvents(t *testing.T) {
+	machine := newMachine()
+	assert.DeepEqual(t, []PlayerEvent{PlayerEventLoad, PlayerEventPlay, PlayerEventResume}, machine.AvailableEvents())
+	assert.Assert(t, machine.CanTrigger(PlayerEventPlay))
+	assert.Assert(t, !machine.CanTrigger(PlayerEventStop))
+	assert.DeepEqual(t, []PlayerState{PlayerStateInit, PlayerStateBuffering, PlayerStatePlaying}, machine.States())
+}
+
+func TestTransitionTable(t *testing.T) {
+	assert.DeepEqual(t, []PlayerTransitionRule{
+		{From: "", Event: PlayerEventLoad, To: PlayerStateInit},
+		{From: PlayerStatePlaying, Event: PlayerEventLoad, To: PlayerStateInit, Guard: "unlocked"},
+		{From: PlayerStateInit, Event: PlayerEventPlay, To: PlayerStatePlaying, Guard: "file_loaded"},
+		{From: PlayerStateInit, Event: PlayerEventPlay, To: PlayerStateInit},
+		{From: PlayerStateInit, Event: PlayerEventResume, To: PlayerStatePlaying, Guard: "buffered"},
+		{From: PlayerStateInit, Event: PlayerEventResume, To: PlayerStateBuffering, Guard: "file_loaded"},
+		{From: PlayerStateBuffering, Event: PlayerEventStop, To: PlayerStateInit, Guard: "unlocked"},
+		{From: PlayerStatePlaying, Event: PlayerEventStop, To: PlayerStateInit, Guard: "unlocked"},
+	}, newMachine().TransitionTable())
+}
+
+func TestTransitionTableJSON(t *testing.T) {
+	table := newMachine().TransitionTable()
+	data, err := json.Marshal(table)
+	assert.NilError(t, err)
+	var decoded []PlayerTransitionRule
+	assert.NilError(t, json.Unmarshal(data, &decoded))
+	assert.DeepEqual(t, table, decoded)
+}
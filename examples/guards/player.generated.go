@@ -0,0 +1,482 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package guards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateInit      PlayerState = "init"
+	PlayerStateBuffering PlayerState = "buffering"
+	PlayerStatePlaying   PlayerState = "playing"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateInit, PlayerStateBuffering, PlayerStatePlaying:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventLoad   PlayerEvent = "load"
+	PlayerEventPlay   PlayerEvent = "play"
+	PlayerEventResume PlayerEvent = "resume"
+	PlayerEventStop   PlayerEvent = "stop"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventLoad, PlayerEventPlay, PlayerEventResume, PlayerEventStop:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, State]
+
+	env Environment
+
+	LoadAction   func(ctx PlayerMachineContext, state *State, ev EventLoad) error
+	PlayAction   func(ctx PlayerMachineContext, state *State, ev EventPlay) error
+	ResumeAction func(ctx PlayerMachineContext, state *State, ev EventResume) error
+	StopAction   func(ctx PlayerMachineContext, state *State, ev EventStop) error
+
+	LoadUnlockedGuard     func(ctx PlayerMachineContext, state State, ev EventLoad) bool
+	PlayFileLoadedGuard   func(ctx PlayerMachineContext, state State, ev EventPlay) bool
+	ResumeBufferedGuard   func(ctx PlayerMachineContext, state State, ev EventResume) bool
+	ResumeFileLoadedGuard func(ctx PlayerMachineContext, state State, ev EventResume) bool
+	StopUnlockedGuard     func(ctx PlayerMachineContext, state State, ev EventStop) bool
+
+	OnStateInit      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateBuffering func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePlaying   func(ctx PlayerMachineContext, env Environment, state State) error
+
+	OnExitInit      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitBuffering func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPlaying   func(ctx PlayerMachineContext, env Environment, state State) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, State]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerLoad(ev EventLoad) error
+	TriggerPlay(ev EventPlay) error
+	TriggerResume(ev EventResume) error
+	TriggerStop(ev EventStop) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerLoad queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoad(ev EventLoad) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoad(ctx.ctx, ev)
+	})
+}
+
+// TriggerPlay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerPlay(ev EventPlay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPlay(ctx.ctx, ev)
+	})
+}
+
+// TriggerResume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerResume(ev EventResume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerResume(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStop(ev EventStop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateInit, PlayerStateBuffering, PlayerStatePlaying},
+	Events: []PlayerEvent{PlayerEventLoad, PlayerEventPlay, PlayerEventResume, PlayerEventStop},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {
+			PlayerEventLoad: PlayerStateInit,
+		},
+		PlayerStateBuffering: {
+			PlayerEventStop: PlayerStateInit,
+		},
+		PlayerStateInit: {
+			PlayerEventPlay:   PlayerStateInit,
+			PlayerEventResume: "",
+		},
+		PlayerStatePlaying: {
+			PlayerEventLoad: PlayerStateInit,
+			PlayerEventStop: PlayerStateInit,
+		},
+	},
+	Parents: map[PlayerState]PlayerState{},
+	Initial: map[PlayerState]PlayerState{},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateInit:      0,
+		PlayerStateBuffering: 1,
+		PlayerStatePlaying:   2,
+	},
+	Rules: []PlayerTransitionRule{
+		{From: "", Event: PlayerEventLoad, To: PlayerStateInit},
+		{From: PlayerStatePlaying, Event: PlayerEventLoad, To: PlayerStateInit, Guard: "unlocked"},
+		{From: PlayerStateInit, Event: PlayerEventPlay, To: PlayerStatePlaying, Guard: "file_loaded"},
+		{From: PlayerStateInit, Event: PlayerEventPlay, To: PlayerStateInit},
+		{From: PlayerStateInit, Event: PlayerEventResume, To: PlayerStatePlaying, Guard: "buffered"},
+		{From: PlayerStateInit, Event: PlayerEventResume, To: PlayerStateBuffering, Guard: "file_loaded"},
+		{From: PlayerStateBuffering, Event: PlayerEventStop, To: PlayerStateInit, Guard: "unlocked"},
+		{From: PlayerStatePlaying, Event: PlayerEventStop, To: PlayerStateInit, Guard: "unlocked"},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *State, env Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventLoad:
+		ev, ok := payload.(EventLoad)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoad, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoad(ctx, ev)
+		}, nil
+	case PlayerEventPlay:
+		ev, ok := payload.(EventPlay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPlay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPlay(ctx, ev)
+		}, nil
+	case PlayerEventResume:
+		ev, ok := payload.(EventResume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventResume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerResume(ctx, ev)
+		}, nil
+	case PlayerEventStop:
+		ev, ok := payload.(EventStop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInit:
+		if machine.OnExitInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitInit", transition, machine.OnExitInit(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateBuffering:
+		if machine.OnExitBuffering == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitBuffering", transition, machine.OnExitBuffering(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInit:
+		if machine.OnStateInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateInit", transition, machine.OnStateInit(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateBuffering:
+		if machine.OnStateBuffering == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateBuffering", transition, machine.OnStateBuffering(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerLoad triggers the load event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoad(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoad(ctx context.Context, ev EventLoad) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == PlayerStatePlaying {
+			switch {
+			case machine.LoadUnlockedGuard != nil && machine.LoadUnlockedGuard(guardCtx, *machine.State, ev):
+				return PlayerStateInit, nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, PlayerEventLoad, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventLoad, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadAction", transition, machine.LoadAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPlay triggers the play event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPlay(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerPlay(ctx context.Context, ev EventPlay) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == PlayerStateInit {
+			switch {
+			case machine.PlayFileLoadedGuard != nil && machine.PlayFileLoadedGuard(guardCtx, *machine.State, ev):
+				return PlayerStatePlaying, nil
+			}
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventPlay, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.PlayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PlayAction", transition, machine.PlayAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerResume triggers the resume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerResume(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerResume(ctx context.Context, ev EventResume) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == PlayerStateInit {
+			switch {
+			case machine.ResumeBufferedGuard != nil && machine.ResumeBufferedGuard(guardCtx, *machine.State, ev):
+				return PlayerStatePlaying, nil
+			case machine.ResumeFileLoadedGuard != nil && machine.ResumeFileLoadedGuard(guardCtx, *machine.State, ev):
+				return PlayerStateBuffering, nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, PlayerEventResume, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventResume, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.ResumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ResumeAction", transition, machine.ResumeAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStop(ctx context.Context, ev EventStop) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == PlayerStateBuffering || from == PlayerStatePlaying {
+			switch {
+			case machine.StopUnlockedGuard != nil && machine.StopUnlockedGuard(guardCtx, *machine.State, ev):
+				return PlayerStateInit, nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, PlayerEventStop, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventStop, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,23 @@
+package guards
+
+//go:generate go run gen/gen.go
+type State struct {
+	File     string
+	Buffered bool
+	Locked   bool
+}
+
+type Environment struct{}
+
+const (
+	StateInit      = "init"
+	StateBuffering = "buffering"
+	StatePlaying   = "playing"
+)
+
+type EventLoad struct {
+	File string
+}
+type EventPlay struct{}
+type EventResume struct{}
+type EventStop struct{}
@@ -0,0 +1,28 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/hierarchy"
+)
+
+func main() {
+	gen := fsmgen.New("player", hierarchy.State{}, hierarchy.Environment{}, hierarchy.StateStopped, hierarchy.StateActive, hierarchy.StateLoading, hierarchy.StatePlaying, hierarchy.StatePaused)
+	gen.PackageName = "hierarchy"
+	gen.AddSubstates(hierarchy.StateActive, hierarchy.StateLoading, hierarchy.StatePlaying, hierarchy.StatePaused)
+	gen.AddEvent(fsmgen.NewEvent("load", hierarchy.EventLoad{}).From(hierarchy.StateStopped).To(hierarchy.StateActive))
+	gen.AddEvent(fsmgen.NewEvent("loaded", hierarchy.EventLoaded{}).From(hierarchy.StateLoading).To(hierarchy.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("pause", hierarchy.EventPause{}).From(hierarchy.StatePlaying).To(hierarchy.StatePaused))
+	gen.AddEvent(fsmgen.NewEvent("resume", hierarchy.EventResume{}).From(hierarchy.StatePaused).To(hierarchy.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("reload", hierarchy.EventReload{}).From(hierarchy.StateActive).To(hierarchy.StateActive))
+	gen.AddEvent(fsmgen.NewEvent("stop", hierarchy.EventStop{}).From(hierarchy.StateActive).To(hierarchy.StateStopped))
+	gen.AddTransitionHook("leave_active", hierarchy.StateActive, hierarchy.StateStopped)
+	gen.MermaidFilename = "player.mmd"
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
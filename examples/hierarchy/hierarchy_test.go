@@ -0,0 +1,95 @@
+package hierarchy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func newMachine() (*PlayerMachine, *[]string) {
+	log := &[]string{}
+	machine := NewPlayerMachine(&State{}, Environment{Log: log})
+	record := func(entry string) func(ctx PlayerMachineContext, env Environment, state State) error {
+		return func(ctx PlayerMachineContext, env Environment, state State) error {
+			env.Record(entry)
+			return nil
+		}
+	}
+	machine.OnStateActive = record("enter active")
+	machine.OnExitActive = record("exit active")
+	machine.OnStateLoading = record("enter loading")
+	machine.OnExitLoading = record("exit loading")
+	machine.OnStatePlaying = record("enter playing")
+	machine.OnExitPlaying = record("exit playing")
+	machine.OnStatePaused = record("enter paused")
+	machine.OnExitPaused = record("exit paused")
+	machine.LeaveActiveHook = func(ctx PlayerMachineContext, env Environment, state State, transition PlayerTransition) error {
+		env.Record("leave active from " + transition.From.String())
+		return nil
+	}
+	return machine, log
+}
+
+func TestEnterCompoundState(t *testing.T) {
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerLoad(context.Background(), EventLoad{}))
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+	assert.DeepEqual(t, []PlayerState{PlayerStateActive, PlayerStateLoading}, machine.ActivePath())
+	assert.Assert(t, machine.IsIn(PlayerStateActive))
+	assert.Assert(t, machine.IsIn(PlayerStateLoading))
+	assert.Assert(t, !machine.IsIn(PlayerStateStopped))
+	assert.DeepEqual(t, []string{"enter active", "enter loading"}, *log)
+}
+
+func TestSiblingTransitionStaysInParent(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	assert.NilError(t, machine.TriggerLoaded(ctx, EventLoaded{}))
+	assert.NilError(t, machine.TriggerPause(ctx, EventPause{}))
+	assert.DeepEqual(t, []string{
+		"enter active", "enter loading",
+		"exit loading", "enter playing",
+		"exit playing", "enter paused",
+	}, *log)
+}
+
+func TestParentEventAppliesToDescendants(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	assert.NilError(t, machine.TriggerLoaded(ctx, EventLoaded{}))
+	*log = nil
+	assert.NilError(t, machine.TriggerStop(ctx, EventStop{}))
+	assert.Equal(t, PlayerStateStopped, machine.CurrentState)
+	assert.DeepEqual(t, []PlayerState{PlayerStateStopped}, machine.ActivePath())
+	assert.DeepEqual(t, []string{"exit playing", "exit active", "leave active from playing"}, *log)
+
+	err := machine.TriggerStop(ctx, EventStop{})
+	var invalid *fsmruntime.InvalidTransitionError
+	assert.Assert(t, errors.As(err, &invalid))
+	assert.DeepEqual(t, &fsmruntime.InvalidTransitionError{From: "stopped", Event: "stop"}, invalid)
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrInvalidTransition))
+}
+
+func TestCompoundSelfTransitionReentersInitialChild(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	assert.NilError(t, machine.TriggerLoaded(ctx, EventLoaded{}))
+	*log = nil
+	assert.NilError(t, machine.TriggerReload(ctx, EventReload{}))
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+	assert.DeepEqual(t, []string{"exit playing", "exit active", "enter active", "enter loading"}, *log)
+}
+
+func TestAvailableEventsIncludeParentEvents(t *testing.T) {
+	machine, _ := newMachine()
+	assert.NilError(t, machine.TriggerLoad(context.Background(), EventLoad{}))
+	assert.DeepEqual(t, []PlayerEvent{PlayerEventLoaded, PlayerEventReload, PlayerEventStop}, machine.AvailableEvents())
+	assert.Assert(t, machine.CanTrigger(PlayerEventStop))
+	assert.Assert(t, !machine.CanTrigger(PlayerEventPause))
+}
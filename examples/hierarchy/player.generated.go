@@ -0,0 +1,553 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package hierarchy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateStopped PlayerState = "stopped"
+	PlayerStateActive  PlayerState = "active"
+	PlayerStateLoading PlayerState = "loading"
+	PlayerStatePlaying PlayerState = "playing"
+	PlayerStatePaused  PlayerState = "paused"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateStopped, PlayerStateActive, PlayerStateLoading, PlayerStatePlaying, PlayerStatePaused:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventLoad   PlayerEvent = "load"
+	PlayerEventLoaded PlayerEvent = "loaded"
+	PlayerEventPause  PlayerEvent = "pause"
+	PlayerEventResume PlayerEvent = "resume"
+	PlayerEventReload PlayerEvent = "reload"
+	PlayerEventStop   PlayerEvent = "stop"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventLoad, PlayerEventLoaded, PlayerEventPause, PlayerEventResume, PlayerEventReload, PlayerEventStop:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, State]
+
+	env Environment
+
+	LoadAction   func(ctx PlayerMachineContext, state *State, ev EventLoad) error
+	LoadedAction func(ctx PlayerMachineContext, state *State, ev EventLoaded) error
+	PauseAction  func(ctx PlayerMachineContext, state *State, ev EventPause) error
+	ResumeAction func(ctx PlayerMachineContext, state *State, ev EventResume) error
+	ReloadAction func(ctx PlayerMachineContext, state *State, ev EventReload) error
+	StopAction   func(ctx PlayerMachineContext, state *State, ev EventStop) error
+
+	OnStateStopped func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateActive  func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateLoading func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePlaying func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePaused  func(ctx PlayerMachineContext, env Environment, state State) error
+
+	OnExitStopped func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitActive  func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitLoading func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPlaying func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPaused  func(ctx PlayerMachineContext, env Environment, state State) error
+
+	LeaveActiveHook func(ctx PlayerMachineContext, env Environment, state State, transition PlayerTransition) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, State]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerLoad(ev EventLoad) error
+	TriggerLoaded(ev EventLoaded) error
+	TriggerPause(ev EventPause) error
+	TriggerResume(ev EventResume) error
+	TriggerReload(ev EventReload) error
+	TriggerStop(ev EventStop) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerLoad queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoad(ev EventLoad) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoad(ctx.ctx, ev)
+	})
+}
+
+// TriggerLoaded queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoaded(ev EventLoaded) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoaded(ctx.ctx, ev)
+	})
+}
+
+// TriggerPause queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerPause(ev EventPause) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPause(ctx.ctx, ev)
+	})
+}
+
+// TriggerResume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerResume(ev EventResume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerResume(ctx.ctx, ev)
+	})
+}
+
+// TriggerReload queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerReload(ev EventReload) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerReload(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStop(ev EventStop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateStopped, PlayerStateActive, PlayerStateLoading, PlayerStatePlaying, PlayerStatePaused},
+	Events: []PlayerEvent{PlayerEventLoad, PlayerEventLoaded, PlayerEventPause, PlayerEventResume, PlayerEventReload, PlayerEventStop},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {},
+		PlayerStateActive: {
+			PlayerEventReload: PlayerStateActive,
+			PlayerEventStop:   PlayerStateStopped,
+		},
+		PlayerStateLoading: {
+			PlayerEventLoaded: PlayerStatePlaying,
+		},
+		PlayerStatePaused: {
+			PlayerEventResume: PlayerStatePlaying,
+		},
+		PlayerStatePlaying: {
+			PlayerEventPause: PlayerStatePaused,
+		},
+		PlayerStateStopped: {
+			PlayerEventLoad: PlayerStateActive,
+		},
+	},
+	Parents: map[PlayerState]PlayerState{
+		PlayerStateLoading: PlayerStateActive,
+		PlayerStatePaused:  PlayerStateActive,
+		PlayerStatePlaying: PlayerStateActive,
+	},
+	Initial: map[PlayerState]PlayerState{
+		PlayerStateActive: PlayerStateLoading,
+	},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateStopped: 0,
+		PlayerStateActive:  1,
+		PlayerStateLoading: 2,
+		PlayerStatePlaying: 3,
+		PlayerStatePaused:  4,
+	},
+	Rules: []PlayerTransitionRule{
+		{From: PlayerStateStopped, Event: PlayerEventLoad, To: PlayerStateActive},
+		{From: PlayerStateLoading, Event: PlayerEventLoaded, To: PlayerStatePlaying},
+		{From: PlayerStatePlaying, Event: PlayerEventPause, To: PlayerStatePaused},
+		{From: PlayerStatePaused, Event: PlayerEventResume, To: PlayerStatePlaying},
+		{From: PlayerStateActive, Event: PlayerEventReload, To: PlayerStateActive},
+		{From: PlayerStateActive, Event: PlayerEventStop, To: PlayerStateStopped},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *State, env Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Hooks: machine.runTransitionHooks,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventLoad:
+		ev, ok := payload.(EventLoad)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoad, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoad(ctx, ev)
+		}, nil
+	case PlayerEventLoaded:
+		ev, ok := payload.(EventLoaded)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoaded, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoaded(ctx, ev)
+		}, nil
+	case PlayerEventPause:
+		ev, ok := payload.(EventPause)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPause, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPause(ctx, ev)
+		}, nil
+	case PlayerEventResume:
+		ev, ok := payload.(EventResume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventResume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerResume(ctx, ev)
+		}, nil
+	case PlayerEventReload:
+		ev, ok := payload.(EventReload)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventReload, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerReload(ctx, ev)
+		}, nil
+	case PlayerEventStop:
+		ev, ok := payload.(EventStop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition, in declaration order. A hook's states
+// match the transition's states and any of their ancestors.
+func (machine *PlayerMachine) runTransitionHooks(ctx context.Context, transition PlayerTransition) error {
+	if machine.LeaveActiveHook != nil && playerDefinition.Within(transition.From, PlayerStateActive) && playerDefinition.Within(transition.To, PlayerStateStopped) {
+		err := machine.LeaveActiveHook(newPlayerContext(ctx, machine), machine.env, *machine.State, transition)
+		if err != nil {
+			return fsmruntime.WrapHandlerError("LeaveActiveHook", transition, err)
+		}
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateStopped:
+		if machine.OnExitStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitStopped", transition, machine.OnExitStopped(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnExitActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitActive", transition, machine.OnExitActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnExitLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitLoading", transition, machine.OnExitLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnExitPaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaused", transition, machine.OnExitPaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateStopped:
+		if machine.OnStateStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateStopped", transition, machine.OnStateStopped(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnStateActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateActive", transition, machine.OnStateActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnStateLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateLoading", transition, machine.OnStateLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnStatePaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaused", transition, machine.OnStatePaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerLoad triggers the load event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoad(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Fire(ctx, PlayerEventLoad, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadAction", transition, machine.LoadAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerLoaded triggers the loaded event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoaded(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Fire(ctx, PlayerEventLoaded, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadedAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadedAction", transition, machine.LoadedAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPause triggers the pause event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPause(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Fire(ctx, PlayerEventPause, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.PauseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PauseAction", transition, machine.PauseAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerResume triggers the resume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerResume(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Fire(ctx, PlayerEventResume, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.ResumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ResumeAction", transition, machine.ResumeAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerReload triggers the reload event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerReload(ctx context.Context, ev EventReload) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerReload(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerReload(ctx context.Context, ev EventReload) error {
+	return machine.Fire(ctx, PlayerEventReload, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.ReloadAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ReloadAction", transition, machine.ReloadAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Fire(ctx, PlayerEventStop, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
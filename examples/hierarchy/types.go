@@ -0,0 +1,29 @@
+package hierarchy
+
+//go:generate go run gen/gen.go
+type State struct{}
+
+// Environment records the order entry and exit handlers are called in.
+type Environment struct {
+	Log *[]string
+}
+
+func (env Environment) Record(entry string) {
+	*env.Log = append(*env.Log, entry)
+}
+
+// The active state contains the loading, playing and paused states, and is entered through loading.
+const (
+	StateStopped = "stopped"
+	StateActive  = "active"
+	StateLoading = "loading"
+	StatePlaying = "playing"
+	StatePaused  = "paused"
+)
+
+type EventLoad struct{}
+type EventLoaded struct{}
+type EventPause struct{}
+type EventResume struct{}
+type EventReload struct{}
+type EventStop struct{}
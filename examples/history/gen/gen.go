@@ -0,0 +1,34 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/history"
+)
+
+func main() {
+	gen := fsmgen.New("player", history.State{}, history.Environment{},
+		history.StateInterrupted, history.StateActive, history.StateLoading,
+		history.StatePlaying, history.StateNormal, history.StateFast, history.StatePaused,
+	)
+	gen.PackageName = "history"
+	gen.AddSubstates(history.StateActive, history.StateLoading, history.StatePlaying, history.StatePaused)
+	gen.AddSubstates(history.StatePlaying, history.StateNormal, history.StateFast)
+	gen.ShallowHistory(history.HistoryActive, history.StateActive, history.StateLoading)
+	gen.DeepHistory(history.DeepHistoryActive, history.StateActive, history.StateLoading)
+	gen.AddEvent(fsmgen.NewEvent("start", history.EventStart{}).From(history.StateInterrupted).To(history.StateActive))
+	gen.AddEvent(fsmgen.NewEvent("loaded", history.EventLoaded{}).From(history.StateLoading).To(history.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("fast_forward", history.EventFastForward{}).From(history.StateNormal).To(history.StateFast))
+	gen.AddEvent(fsmgen.NewEvent("pause", history.EventPause{}).From(history.StatePlaying).To(history.StatePaused))
+	gen.AddEvent(fsmgen.NewEvent("interrupt", history.EventInterrupt{}).From(history.StateActive).To(history.StateInterrupted))
+	gen.AddEvent(fsmgen.NewEvent("resume", history.EventResume{}).From(history.StateInterrupted).To(history.HistoryActive))
+	gen.AddEvent(fsmgen.NewEvent("restore", history.EventRestore{}).From(history.StateInterrupted).To(history.DeepHistoryActive))
+	gen.MermaidFilename = "player.mmd"
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
@@ -0,0 +1,73 @@
+package history
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// newFastMachine returns a machine that was playing fast when it was interrupted.
+func newFastMachine(t *testing.T) *PlayerMachine {
+	ctx := context.Background()
+	machine := NewPlayerMachine(&State{}, Environment{})
+	assert.NilError(t, machine.TriggerStart(ctx, EventStart{}))
+	assert.NilError(t, machine.TriggerLoaded(ctx, EventLoaded{}))
+	assert.NilError(t, machine.TriggerFastForward(ctx, EventFastForward{}))
+	assert.NilError(t, machine.TriggerInterrupt(ctx, EventInterrupt{}))
+	assert.Equal(t, PlayerStateInterrupted, machine.CurrentState)
+	return machine
+}
+
+func TestHistoryDefault(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPlayerMachine(&State{}, Environment{})
+	assert.NilError(t, machine.TriggerResume(ctx, EventResume{}))
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+
+	machine = NewPlayerMachine(&State{}, Environment{})
+	assert.NilError(t, machine.TriggerRestore(ctx, EventRestore{}))
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+}
+
+func TestShallowHistory(t *testing.T) {
+	machine := newFastMachine(t)
+	var entered []PlayerState
+	machine.OnStatePlaying = func(ctx PlayerMachineContext, env Environment, state State) error {
+		entered = append(entered, PlayerStatePlaying)
+		return nil
+	}
+	machine.OnStateNormal = func(ctx PlayerMachineContext, env Environment, state State) error {
+		entered = append(entered, PlayerStateNormal)
+		return nil
+	}
+	assert.NilError(t, machine.TriggerResume(context.Background(), EventResume{}))
+	// Shallow history restores the active child of active, which enters its own initial child.
+	assert.DeepEqual(t, []PlayerState{PlayerStateActive, PlayerStatePlaying, PlayerStateNormal}, machine.ActivePath())
+	assert.DeepEqual(t, []PlayerState{PlayerStatePlaying, PlayerStateNormal}, entered)
+}
+
+func TestDeepHistory(t *testing.T) {
+	machine := newFastMachine(t)
+	assert.NilError(t, machine.TriggerRestore(context.Background(), EventRestore{}))
+	assert.DeepEqual(t, []PlayerState{PlayerStateActive, PlayerStatePlaying, PlayerStateFast}, machine.ActivePath())
+}
+
+func TestHistoryIsRecordedOnEveryExit(t *testing.T) {
+	ctx := context.Background()
+	machine := newFastMachine(t)
+	assert.NilError(t, machine.TriggerRestore(ctx, EventRestore{}))
+	assert.NilError(t, machine.TriggerPause(ctx, EventPause{}))
+	assert.NilError(t, machine.TriggerInterrupt(ctx, EventInterrupt{}))
+	assert.NilError(t, machine.TriggerRestore(ctx, EventRestore{}))
+	assert.Equal(t, PlayerStatePaused, machine.CurrentState)
+}
+
+func TestSnapshotRestoresHistory(t *testing.T) {
+	snapshot := newFastMachine(t).Snapshot()
+	assert.DeepEqual(t, []PlayerState{PlayerStateFast}, snapshot.History[PlayerStateActiveDeepHistory])
+	restored, err := NewPlayerMachineFromSnapshot(snapshot, Environment{})
+	assert.NilError(t, err)
+	assert.NilError(t, restored.TriggerRestore(context.Background(), EventRestore{}))
+	assert.Equal(t, PlayerStateFast, restored.CurrentState)
+}
@@ -0,0 +1,621 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateInterrupted PlayerState = "interrupted"
+	PlayerStateActive      PlayerState = "active"
+	PlayerStateLoading     PlayerState = "loading"
+	PlayerStatePlaying     PlayerState = "playing"
+	PlayerStateNormal      PlayerState = "normal"
+	PlayerStateFast        PlayerState = "fast"
+	PlayerStatePaused      PlayerState = "paused"
+)
+
+// History pseudo-states, which may be the target of a transition but are never active.
+const (
+	PlayerStateActiveHistory     PlayerState = "active_history"
+	PlayerStateActiveDeepHistory PlayerState = "active_deep_history"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateInterrupted, PlayerStateActive, PlayerStateLoading, PlayerStatePlaying, PlayerStateNormal, PlayerStateFast, PlayerStatePaused, PlayerStateActiveHistory, PlayerStateActiveDeepHistory:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventStart       PlayerEvent = "start"
+	PlayerEventLoaded      PlayerEvent = "loaded"
+	PlayerEventFastForward PlayerEvent = "fast_forward"
+	PlayerEventPause       PlayerEvent = "pause"
+	PlayerEventInterrupt   PlayerEvent = "interrupt"
+	PlayerEventResume      PlayerEvent = "resume"
+	PlayerEventRestore     PlayerEvent = "restore"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventStart, PlayerEventLoaded, PlayerEventFastForward, PlayerEventPause, PlayerEventInterrupt, PlayerEventResume, PlayerEventRestore:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, State]
+
+	env Environment
+
+	StartAction       func(ctx PlayerMachineContext, state *State, ev EventStart) error
+	LoadedAction      func(ctx PlayerMachineContext, state *State, ev EventLoaded) error
+	FastForwardAction func(ctx PlayerMachineContext, state *State, ev EventFastForward) error
+	PauseAction       func(ctx PlayerMachineContext, state *State, ev EventPause) error
+	InterruptAction   func(ctx PlayerMachineContext, state *State, ev EventInterrupt) error
+	ResumeAction      func(ctx PlayerMachineContext, state *State, ev EventResume) error
+	RestoreAction     func(ctx PlayerMachineContext, state *State, ev EventRestore) error
+
+	OnStateInterrupted func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateActive      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateLoading     func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePlaying     func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateNormal      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateFast        func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePaused      func(ctx PlayerMachineContext, env Environment, state State) error
+
+	OnExitInterrupted func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitActive      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitLoading     func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPlaying     func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitNormal      func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitFast        func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPaused      func(ctx PlayerMachineContext, env Environment, state State) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, State]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerStart(ev EventStart) error
+	TriggerLoaded(ev EventLoaded) error
+	TriggerFastForward(ev EventFastForward) error
+	TriggerPause(ev EventPause) error
+	TriggerInterrupt(ev EventInterrupt) error
+	TriggerResume(ev EventResume) error
+	TriggerRestore(ev EventRestore) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerStart queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStart(ev EventStart) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStart(ctx.ctx, ev)
+	})
+}
+
+// TriggerLoaded queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoaded(ev EventLoaded) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoaded(ctx.ctx, ev)
+	})
+}
+
+// TriggerFastForward queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerFastForward(ev EventFastForward) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerFastForward(ctx.ctx, ev)
+	})
+}
+
+// TriggerPause queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerPause(ev EventPause) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPause(ctx.ctx, ev)
+	})
+}
+
+// TriggerInterrupt queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerInterrupt(ev EventInterrupt) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerInterrupt(ctx.ctx, ev)
+	})
+}
+
+// TriggerResume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerResume(ev EventResume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerResume(ctx.ctx, ev)
+	})
+}
+
+// TriggerRestore queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerRestore(ev EventRestore) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerRestore(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateInterrupted, PlayerStateActive, PlayerStateLoading, PlayerStatePlaying, PlayerStateNormal, PlayerStateFast, PlayerStatePaused},
+	Events: []PlayerEvent{PlayerEventStart, PlayerEventLoaded, PlayerEventFastForward, PlayerEventPause, PlayerEventInterrupt, PlayerEventResume, PlayerEventRestore},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {},
+		PlayerStateActive: {
+			PlayerEventInterrupt: PlayerStateInterrupted,
+		},
+		PlayerStateFast: {},
+		PlayerStateInterrupted: {
+			PlayerEventRestore: PlayerStateActiveDeepHistory,
+			PlayerEventResume:  PlayerStateActiveHistory,
+			PlayerEventStart:   PlayerStateActive,
+		},
+		PlayerStateLoading: {
+			PlayerEventLoaded: PlayerStatePlaying,
+		},
+		PlayerStateNormal: {
+			PlayerEventFastForward: PlayerStateFast,
+		},
+		PlayerStatePaused: {},
+		PlayerStatePlaying: {
+			PlayerEventPause: PlayerStatePaused,
+		},
+	},
+	Parents: map[PlayerState]PlayerState{
+		PlayerStateActiveDeepHistory: PlayerStateActive,
+		PlayerStateActiveHistory:     PlayerStateActive,
+		PlayerStateFast:              PlayerStatePlaying,
+		PlayerStateLoading:           PlayerStateActive,
+		PlayerStateNormal:            PlayerStatePlaying,
+		PlayerStatePaused:            PlayerStateActive,
+		PlayerStatePlaying:           PlayerStateActive,
+	},
+	Initial: map[PlayerState]PlayerState{
+		PlayerStateActive:  PlayerStateLoading,
+		PlayerStatePlaying: PlayerStateNormal,
+	},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateInterrupted: 0,
+		PlayerStateActive:      1,
+		PlayerStateLoading:     2,
+		PlayerStatePlaying:     3,
+		PlayerStateNormal:      4,
+		PlayerStateFast:        5,
+		PlayerStatePaused:      6,
+	},
+	Histories: map[PlayerState]fsmruntime.History[PlayerState]{
+		PlayerStateActiveHistory:     {Parent: PlayerStateActive, Default: PlayerStateLoading, Deep: false},
+		PlayerStateActiveDeepHistory: {Parent: PlayerStateActive, Default: PlayerStateLoading, Deep: true},
+	},
+	Rules: []PlayerTransitionRule{
+		{From: PlayerStateInterrupted, Event: PlayerEventStart, To: PlayerStateActive},
+		{From: PlayerStateLoading, Event: PlayerEventLoaded, To: PlayerStatePlaying},
+		{From: PlayerStateNormal, Event: PlayerEventFastForward, To: PlayerStateFast},
+		{From: PlayerStatePlaying, Event: PlayerEventPause, To: PlayerStatePaused},
+		{From: PlayerStateActive, Event: PlayerEventInterrupt, To: PlayerStateInterrupted},
+		{From: PlayerStateInterrupted, Event: PlayerEventResume, To: PlayerStateActiveHistory},
+		{From: PlayerStateInterrupted, Event: PlayerEventRestore, To: PlayerStateActiveDeepHistory},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *State, env Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventStart:
+		ev, ok := payload.(EventStart)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStart, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStart(ctx, ev)
+		}, nil
+	case PlayerEventLoaded:
+		ev, ok := payload.(EventLoaded)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoaded, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoaded(ctx, ev)
+		}, nil
+	case PlayerEventFastForward:
+		ev, ok := payload.(EventFastForward)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventFastForward, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerFastForward(ctx, ev)
+		}, nil
+	case PlayerEventPause:
+		ev, ok := payload.(EventPause)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPause, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPause(ctx, ev)
+		}, nil
+	case PlayerEventInterrupt:
+		ev, ok := payload.(EventInterrupt)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventInterrupt, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerInterrupt(ctx, ev)
+		}, nil
+	case PlayerEventResume:
+		ev, ok := payload.(EventResume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventResume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerResume(ctx, ev)
+		}, nil
+	case PlayerEventRestore:
+		ev, ok := payload.(EventRestore)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventRestore, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerRestore(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInterrupted:
+		if machine.OnExitInterrupted == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitInterrupted", transition, machine.OnExitInterrupted(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnExitActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitActive", transition, machine.OnExitActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnExitLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitLoading", transition, machine.OnExitLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateNormal:
+		if machine.OnExitNormal == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitNormal", transition, machine.OnExitNormal(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateFast:
+		if machine.OnExitFast == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitFast", transition, machine.OnExitFast(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnExitPaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaused", transition, machine.OnExitPaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInterrupted:
+		if machine.OnStateInterrupted == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateInterrupted", transition, machine.OnStateInterrupted(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnStateActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateActive", transition, machine.OnStateActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnStateLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateLoading", transition, machine.OnStateLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateNormal:
+		if machine.OnStateNormal == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateNormal", transition, machine.OnStateNormal(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateFast:
+		if machine.OnStateFast == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateFast", transition, machine.OnStateFast(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnStatePaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaused", transition, machine.OnStatePaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerStart triggers the start event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStart(ctx context.Context, ev EventStart) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStart(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStart(ctx context.Context, ev EventStart) error {
+	return machine.Fire(ctx, PlayerEventStart, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StartAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StartAction", transition, machine.StartAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerLoaded triggers the loaded event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoaded(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Fire(ctx, PlayerEventLoaded, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadedAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadedAction", transition, machine.LoadedAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerFastForward triggers the fast_forward event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerFastForward(ctx context.Context, ev EventFastForward) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerFastForward(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerFastForward(ctx context.Context, ev EventFastForward) error {
+	return machine.Fire(ctx, PlayerEventFastForward, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.FastForwardAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("FastForwardAction", transition, machine.FastForwardAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPause triggers the pause event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPause(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Fire(ctx, PlayerEventPause, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.PauseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PauseAction", transition, machine.PauseAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerInterrupt triggers the interrupt event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerInterrupt(ctx context.Context, ev EventInterrupt) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerInterrupt(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerInterrupt(ctx context.Context, ev EventInterrupt) error {
+	return machine.Fire(ctx, PlayerEventInterrupt, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.InterruptAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("InterruptAction", transition, machine.InterruptAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerResume triggers the resume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerResume(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Fire(ctx, PlayerEventResume, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.ResumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ResumeAction", transition, machine.ResumeAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerRestore triggers the restore event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerRestore(ctx context.Context, ev EventRestore) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerRestore(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerRestore(ctx context.Context, ev EventRestore) error {
+	return machine.Fire(ctx, PlayerEventRestore, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.RestoreAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("RestoreAction", transition, machine.RestoreAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,28 @@
+package history
+
+//go:generate go run gen/gen.go
+type State struct{}
+
+type Environment struct{}
+
+// The active state contains loading, playing and paused, and playing contains the normal and fast speeds.
+const (
+	StateInterrupted = "interrupted"
+	StateActive      = "active"
+	StateLoading     = "loading"
+	StatePlaying     = "playing"
+	StateNormal      = "normal"
+	StateFast        = "fast"
+	StatePaused      = "paused"
+
+	HistoryActive     = "active_history"
+	DeepHistoryActive = "active_deep_history"
+)
+
+type EventStart struct{}
+type EventLoaded struct{}
+type EventFastForward struct{}
+type EventPause struct{}
+type EventInterrupt struct{}
+type EventResume struct{}
+type EventRestore struct{}
@@ -0,0 +1,551 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// DecoderState is a state the DecoderMachine may be in.
+type DecoderState string
+
+const (
+	DecoderStateStopped DecoderState = "stopped"
+	DecoderStatePlaying DecoderState = "playing"
+	DecoderStatePaused  DecoderState = "paused"
+)
+
+// String returns the name of the state.
+func (state DecoderState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state DecoderState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseDecoderState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *DecoderState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseDecoderState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseDecoderState returns the DecoderState with the supplied name.
+func ParseDecoderState(str string) (DecoderState, error) {
+	switch DecoderState(str) {
+	case DecoderStateStopped, DecoderStatePlaying, DecoderStatePaused:
+		return DecoderState(str), nil
+	}
+	return "", errors.New("unknown decoder state: " + str)
+}
+
+// DecoderEvent is an event that may be triggered on the DecoderMachine.
+type DecoderEvent string
+
+const (
+	DecoderEventPlay    DecoderEvent = "play"
+	DecoderEventPause   DecoderEvent = "pause"
+	DecoderEventRestart DecoderEvent = "restart"
+	DecoderEventStop    DecoderEvent = "stop"
+	DecoderEventSeek    DecoderEvent = "seek"
+	DecoderEventVolume  DecoderEvent = "volume"
+)
+
+// String returns the name of the event.
+func (event DecoderEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event DecoderEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseDecoderEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *DecoderEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseDecoderEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseDecoderEvent returns the DecoderEvent with the supplied name.
+func ParseDecoderEvent(str string) (DecoderEvent, error) {
+	switch DecoderEvent(str) {
+	case DecoderEventPlay, DecoderEventPause, DecoderEventRestart, DecoderEventStop, DecoderEventSeek, DecoderEventVolume:
+		return DecoderEvent(str), nil
+	}
+	return "", errors.New("unknown decoder event: " + str)
+}
+
+// DecoderMachine is the decoder state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type DecoderMachine struct {
+	*fsmruntime.Machine[DecoderState, DecoderEvent, State]
+
+	env Environment
+
+	PlayAction    func(ctx DecoderMachineContext, state *State, ev EventPlay) error
+	PauseAction   func(ctx DecoderMachineContext, state *State, ev EventPause) error
+	RestartAction func(ctx DecoderMachineContext, state *State, ev EventRestart) error
+	StopAction    func(ctx DecoderMachineContext, state *State, ev EventStop) error
+	SeekAction    func(ctx DecoderMachineContext, state *State, ev EventSeek) error
+	VolumeAction  func(ctx DecoderMachineContext, state *State, ev EventVolume) error
+
+	VolumeUnmutedGuard func(ctx DecoderMachineContext, state State, ev EventVolume) bool
+
+	OnStateStopped func(ctx DecoderMachineContext, env Environment, state State) error
+	OnStatePlaying func(ctx DecoderMachineContext, env Environment, state State) error
+	OnStatePaused  func(ctx DecoderMachineContext, env Environment, state State) error
+
+	OnExitStopped func(ctx DecoderMachineContext, env Environment, state State) error
+	OnExitPlaying func(ctx DecoderMachineContext, env Environment, state State) error
+	OnExitPaused  func(ctx DecoderMachineContext, env Environment, state State) error
+
+	ReleaseDecoderHook func(ctx DecoderMachineContext, env Environment, state State, transition DecoderTransition) error
+	ResumeHook         func(ctx DecoderMachineContext, env Environment, state State, transition DecoderTransition) error
+}
+
+// DecoderTransition describes a transition of the DecoderMachine.
+type DecoderTransition = fsmruntime.Transition[DecoderState, DecoderEvent]
+
+// DecoderSnapshot is a serializable copy of the DecoderMachine's state, which
+// Restore resumes from.
+type DecoderSnapshot = fsmruntime.Snapshot[DecoderState, DecoderEvent, State]
+
+// DecoderTransitionRule describes a transition declared by the DecoderMachine's definition.
+type DecoderTransitionRule = fsmruntime.TransitionRule[DecoderState, DecoderEvent]
+
+// DecoderListener observes every event processed by a DecoderMachine.
+type DecoderListener = fsmruntime.Listener[DecoderState, DecoderEvent]
+
+// DecoderBaseListener implements DecoderListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type DecoderBaseListener = fsmruntime.BaseListener[DecoderState, DecoderEvent]
+
+// DecoderMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type DecoderMachineContext interface {
+	Context() context.Context
+	TriggerPlay(ev EventPlay) error
+	TriggerPause(ev EventPause) error
+	TriggerRestart(ev EventRestart) error
+	TriggerStop(ev EventStop) error
+	TriggerSeek(ev EventSeek) error
+	TriggerVolume(ev EventVolume) error
+}
+
+type decoderMachineContext struct {
+	ctx     context.Context
+	machine *DecoderMachine
+}
+
+func newDecoderContext(ctx context.Context, machine *DecoderMachine) DecoderMachineContext {
+	return &decoderMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx decoderMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPlay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerPlay(ev EventPlay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPlay(ctx.ctx, ev)
+	})
+}
+
+// TriggerPause queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerPause(ev EventPause) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPause(ctx.ctx, ev)
+	})
+}
+
+// TriggerRestart queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerRestart(ev EventRestart) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerRestart(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerStop(ev EventStop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// TriggerSeek queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerSeek(ev EventSeek) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerSeek(ctx.ctx, ev)
+	})
+}
+
+// TriggerVolume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx decoderMachineContext) TriggerVolume(ev EventVolume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerVolume(ctx.ctx, ev)
+	})
+}
+
+// decoderDefinition is the structure of the DecoderMachine, shared by every instance.
+var decoderDefinition = &fsmruntime.Definition[DecoderState, DecoderEvent]{
+	Name:   "decoder",
+	States: []DecoderState{DecoderStateStopped, DecoderStatePlaying, DecoderStatePaused},
+	Events: []DecoderEvent{DecoderEventPlay, DecoderEventPause, DecoderEventRestart, DecoderEventStop, DecoderEventSeek, DecoderEventVolume},
+	Transitions: map[DecoderState]map[DecoderEvent]DecoderState{
+		"": {
+			DecoderEventStop:   DecoderStateStopped,
+			DecoderEventVolume: "",
+		},
+		DecoderStatePaused: {
+			DecoderEventPlay: DecoderStatePlaying,
+			DecoderEventSeek: "",
+		},
+		DecoderStatePlaying: {
+			DecoderEventPause:   DecoderStatePaused,
+			DecoderEventRestart: DecoderStatePlaying,
+			DecoderEventSeek:    "",
+		},
+		DecoderStateStopped: {
+			DecoderEventPlay: DecoderStatePlaying,
+		},
+	},
+	Internal: map[DecoderState]map[DecoderEvent]bool{
+		"": {
+			DecoderEventVolume: true,
+		},
+		DecoderStatePaused: {
+			DecoderEventSeek: true,
+		},
+		DecoderStatePlaying: {
+			DecoderEventSeek: true,
+		},
+	},
+	Parents: map[DecoderState]DecoderState{},
+	Initial: map[DecoderState]DecoderState{},
+	Regions: map[DecoderState][]DecoderState{},
+	Order: map[DecoderState]int{
+		DecoderStateStopped: 0,
+		DecoderStatePlaying: 1,
+		DecoderStatePaused:  2,
+	},
+	Rules: []DecoderTransitionRule{
+		{From: DecoderStateStopped, Event: DecoderEventPlay, To: DecoderStatePlaying},
+		{From: DecoderStatePaused, Event: DecoderEventPlay, To: DecoderStatePlaying},
+		{From: DecoderStatePlaying, Event: DecoderEventPause, To: DecoderStatePaused},
+		{From: DecoderStatePlaying, Event: DecoderEventRestart, To: DecoderStatePlaying},
+		{From: "", Event: DecoderEventStop, To: DecoderStateStopped},
+		{From: DecoderStatePlaying, Event: DecoderEventSeek, To: DecoderStatePlaying, Internal: true},
+		{From: DecoderStatePaused, Event: DecoderEventSeek, To: DecoderStatePaused, Internal: true},
+		{From: "", Event: DecoderEventVolume, To: "", Guard: "unmuted", Internal: true},
+	},
+	MaxChainLength: 100,
+}
+
+// NewDecoderMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewDecoderMachine(state *State, env Environment) *DecoderMachine {
+	machine := &DecoderMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(decoderDefinition, state, fsmruntime.Handlers[DecoderState, DecoderEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Hooks: machine.runTransitionHooks,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewDecoderMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewDecoderMachineFromSnapshot(snapshot DecoderSnapshot, env Environment) (*DecoderMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewDecoderMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *DecoderMachine) eventFunc(event DecoderEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case DecoderEventPlay:
+		ev, ok := payload.(EventPlay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPlay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPlay(ctx, ev)
+		}, nil
+	case DecoderEventPause:
+		ev, ok := payload.(EventPause)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPause, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPause(ctx, ev)
+		}, nil
+	case DecoderEventRestart:
+		ev, ok := payload.(EventRestart)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventRestart, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerRestart(ctx, ev)
+		}, nil
+	case DecoderEventStop:
+		ev, ok := payload.(EventStop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	case DecoderEventSeek:
+		ev, ok := payload.(EventSeek)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventSeek, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerSeek(ctx, ev)
+		}, nil
+	case DecoderEventVolume:
+		ev, ok := payload.(EventVolume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventVolume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerVolume(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition, in declaration order. A hook's states
+// match the transition's states and any of their ancestors.
+func (machine *DecoderMachine) runTransitionHooks(ctx context.Context, transition DecoderTransition) error {
+	if machine.ReleaseDecoderHook != nil && decoderDefinition.Within(transition.From, DecoderStatePlaying) {
+		err := machine.ReleaseDecoderHook(newDecoderContext(ctx, machine), machine.env, *machine.State, transition)
+		if err != nil {
+			return fsmruntime.WrapHandlerError("ReleaseDecoderHook", transition, err)
+		}
+	}
+	if machine.ResumeHook != nil && decoderDefinition.Within(transition.From, DecoderStatePaused) && decoderDefinition.Within(transition.To, DecoderStatePlaying) {
+		err := machine.ResumeHook(newDecoderContext(ctx, machine), machine.env, *machine.State, transition)
+		if err != nil {
+			return fsmruntime.WrapHandlerError("ResumeHook", transition, err)
+		}
+	}
+	return nil
+}
+
+func (machine *DecoderMachine) exitState(ctx context.Context, transition DecoderTransition, state DecoderState) error {
+	switch state {
+	case DecoderStateStopped:
+		if machine.OnExitStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitStopped", transition, machine.OnExitStopped(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	case DecoderStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	case DecoderStatePaused:
+		if machine.OnExitPaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaused", transition, machine.OnExitPaused(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *DecoderMachine) enterState(ctx context.Context, transition DecoderTransition, state DecoderState) error {
+	switch state {
+	case DecoderStateStopped:
+		if machine.OnStateStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateStopped", transition, machine.OnStateStopped(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	case DecoderStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	case DecoderStatePaused:
+		if machine.OnStatePaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaused", transition, machine.OnStatePaused(newDecoderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPlay triggers the play event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPlay(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Fire(ctx, DecoderEventPlay, ev, nil, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.PlayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PlayAction", transition, machine.PlayAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPause triggers the pause event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPause(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Fire(ctx, DecoderEventPause, ev, nil, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.PauseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PauseAction", transition, machine.PauseAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerRestart triggers the restart event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerRestart(ctx context.Context, ev EventRestart) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerRestart(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerRestart(ctx context.Context, ev EventRestart) error {
+	return machine.Fire(ctx, DecoderEventRestart, ev, nil, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.RestartAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("RestartAction", transition, machine.RestartAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Fire(ctx, DecoderEventStop, ev, nil, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerSeek triggers the seek event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerSeek(ctx context.Context, ev EventSeek) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerSeek(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerSeek(ctx context.Context, ev EventSeek) error {
+	return machine.Fire(ctx, DecoderEventSeek, ev, nil, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.SeekAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("SeekAction", transition, machine.SeekAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerVolume triggers the volume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DecoderMachine) TriggerVolume(ctx context.Context, ev EventVolume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerVolume(ctx, ev)
+	})
+}
+
+func (machine *DecoderMachine) triggerVolume(ctx context.Context, ev EventVolume) error {
+	guardCtx := newDecoderContext(ctx, machine)
+	resolve := func(from, target DecoderState) (DecoderState, error) {
+		if from == "" {
+			switch {
+			case machine.VolumeUnmutedGuard != nil && machine.VolumeUnmutedGuard(guardCtx, *machine.State, ev):
+				return "", nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, DecoderEventVolume, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, DecoderEventVolume, ev, resolve, func(ctx context.Context, transition DecoderTransition) error {
+		if machine.VolumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("VolumeAction", transition, machine.VolumeAction(newDecoderContext(ctx, machine), machine.State, ev))
+	})
+}
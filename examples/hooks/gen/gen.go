@@ -0,0 +1,28 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/hooks"
+)
+
+func main() {
+	gen := fsmgen.New("decoder", hooks.State{}, hooks.Environment{}, hooks.StateStopped, hooks.StatePlaying, hooks.StatePaused)
+	gen.PackageName = "hooks"
+	gen.AddEvent(fsmgen.NewEvent("play", hooks.EventPlay{}).From(hooks.StateStopped, hooks.StatePaused).To(hooks.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("pause", hooks.EventPause{}).From(hooks.StatePlaying).To(hooks.StatePaused))
+	gen.AddEvent(fsmgen.NewEvent("restart", hooks.EventRestart{}).From(hooks.StatePlaying).To(hooks.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("stop", hooks.EventStop{}).FromAny().To(hooks.StateStopped))
+	// Internal transitions only run their action, without leaving the state.
+	gen.AddEvent(fsmgen.NewEvent("seek", hooks.EventSeek{}).From(hooks.StatePlaying, hooks.StatePaused).Internal())
+	gen.AddEvent(fsmgen.NewEvent("volume", hooks.EventVolume{}).FromAny().Guard("unmuted").Internal())
+	gen.AddTransitionHook("release_decoder", hooks.StatePlaying, "")
+	gen.AddTransitionHook("resume", hooks.StatePaused, hooks.StatePlaying)
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
@@ -0,0 +1,181 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func newMachine() (*DecoderMachine, *[]string) {
+	log := &[]string{}
+	machine := NewDecoderMachine(&State{}, Environment{Log: log})
+	machine.OnStatePlaying = func(ctx DecoderMachineContext, env Environment, state State) error {
+		env.Record("enter playing")
+		return nil
+	}
+	machine.OnExitPlaying = func(ctx DecoderMachineContext, env Environment, state State) error {
+		env.Record("exit playing")
+		return nil
+	}
+	machine.OnStatePaused = func(ctx DecoderMachineContext, env Environment, state State) error {
+		env.Record("enter paused")
+		return nil
+	}
+	machine.OnExitPaused = func(ctx DecoderMachineContext, env Environment, state State) error {
+		env.Record("exit paused")
+		return nil
+	}
+	machine.PauseAction = func(ctx DecoderMachineContext, state *State, ev EventPause) error {
+		*log = append(*log, "pause action")
+		return nil
+	}
+	machine.RestartAction = func(ctx DecoderMachineContext, state *State, ev EventRestart) error {
+		*log = append(*log, "restart action")
+		return nil
+	}
+	machine.ReleaseDecoderHook = func(ctx DecoderMachineContext, env Environment, state State, transition DecoderTransition) error {
+		env.Record("release decoder via " + transition.Event.String() + " to " + transition.To.String())
+		return nil
+	}
+	machine.ResumeHook = func(ctx DecoderMachineContext, env Environment, state State, transition DecoderTransition) error {
+		env.Record("resume")
+		return nil
+	}
+	return machine, log
+}
+
+func TestHookOrdering(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.NilError(t, machine.TriggerPause(ctx, EventPause{}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.NilError(t, machine.TriggerRestart(ctx, EventRestart{}))
+	assert.NilError(t, machine.TriggerStop(ctx, EventStop{}))
+	assert.DeepEqual(t, []string{
+		"enter playing",
+		// Exit the source, run the hooks and the action, then enter the target.
+		"exit playing",
+		"release decoder via pause to paused",
+		"pause action",
+		"enter paused",
+		"exit paused",
+		"resume",
+		"enter playing",
+		// Self-transitions exit and re-enter the state.
+		"exit playing",
+		"release decoder via restart to playing",
+		"restart action",
+		"enter playing",
+		"exit playing",
+		"release decoder via stop to stopped",
+	}, *log)
+}
+
+func TestInternalTransitions(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	machine.SeekAction = func(ctx DecoderMachineContext, state *State, ev EventSeek) error {
+		*log = append(*log, fmt.Sprintf("seek to %d", ev.Position))
+		return nil
+	}
+	muted := true
+	machine.VolumeUnmutedGuard = func(ctx DecoderMachineContext, state State, ev EventVolume) bool {
+		return !muted
+	}
+	machine.VolumeAction = func(ctx DecoderMachineContext, state *State, ev EventVolume) error {
+		*log = append(*log, fmt.Sprintf("volume %d", ev.Level))
+		return nil
+	}
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.NilError(t, machine.TriggerSeek(ctx, EventSeek{Position: 30}))
+	assert.Assert(t, errors.Is(machine.TriggerVolume(ctx, EventVolume{Level: 5}), fsmruntime.ErrGuardRejected))
+	muted = false
+	assert.NilError(t, machine.TriggerVolume(ctx, EventVolume{Level: 5}))
+	assert.Equal(t, DecoderStatePlaying, machine.CurrentState)
+	assert.DeepEqual(t, []string{
+		"enter playing",
+		// Internal transitions neither exit nor re-enter the state, and run no transition hooks.
+		"seek to 30",
+		"volume 5",
+	}, *log)
+
+	assert.NilError(t, machine.TriggerStop(ctx, EventStop{}))
+	var invalid *fsmruntime.InvalidTransitionError
+	assert.Assert(t, errors.As(machine.TriggerSeek(ctx, EventSeek{}), &invalid))
+}
+
+func TestExitHandlerErrorAbortsTransition(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	errBusy := errors.New("busy")
+	machine.OnExitPlaying = func(ctx DecoderMachineContext, env Environment, state State) error {
+		env.Record("exit playing")
+		return errBusy
+	}
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Assert(t, errors.Is(machine.TriggerPause(ctx, EventPause{}), errBusy))
+	assert.Equal(t, DecoderStatePlaying, machine.CurrentState)
+	assert.DeepEqual(t, []string{"enter playing", "exit playing"}, *log)
+}
+
+func TestActionErrorReentersSource(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	errFull := errors.New("full")
+	machine.PauseAction = func(ctx DecoderMachineContext, state *State, ev EventPause) error {
+		*log = append(*log, "pause action")
+		return errFull
+	}
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Assert(t, errors.Is(machine.TriggerPause(ctx, EventPause{}), errFull))
+	assert.Equal(t, DecoderStatePlaying, machine.CurrentState)
+	assert.DeepEqual(t, []string{
+		"enter playing",
+		"exit playing",
+		"release decoder via pause to paused",
+		"pause action",
+		// The exited source state is re-entered, so its decoder is acquired again. Hooks are not undone.
+		"enter playing",
+	}, *log)
+}
+
+// recorder is a listener that records every notification.
+type recorder struct {
+	DecoderBaseListener
+	log []string
+}
+
+func (r *recorder) OnTransition(ctx context.Context, from, to DecoderState, event DecoderEvent, payload interface{}) {
+	r.log = append(r.log, fmt.Sprintf("%s: %s -> %s (%T)", event, from, to, payload))
+}
+
+func (r *recorder) OnRejected(ctx context.Context, from DecoderState, event DecoderEvent, payload interface{}, err error) {
+	r.log = append(r.log, fmt.Sprintf("%s rejected from %s", event, from))
+}
+
+func (r *recorder) OnError(ctx context.Context, from, to DecoderState, event DecoderEvent, payload interface{}, err error) {
+	r.log = append(r.log, fmt.Sprintf("%s failed: %v", event, err))
+}
+
+func TestListener(t *testing.T) {
+	ctx := context.Background()
+	machine, _ := newMachine()
+	listener := &recorder{}
+	machine.AddListener(listener)
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Assert(t, machine.TriggerPlay(ctx, EventPlay{}) != nil)
+	machine.PauseAction = func(ctx DecoderMachineContext, state *State, ev EventPause) error {
+		return errors.New("busy")
+	}
+	assert.Assert(t, machine.TriggerPause(ctx, EventPause{}) != nil)
+	assert.DeepEqual(t, []string{
+		"play: stopped -> playing (hooks.EventPlay)",
+		"play rejected from playing",
+		"pause failed: PauseAction failed on pause from playing to paused: busy",
+	}, listener.log)
+}
@@ -0,0 +1,30 @@
+package hooks
+
+//go:generate go run gen/gen.go
+type State struct{}
+
+// Environment records the order handlers and hooks are called in.
+type Environment struct {
+	Log *[]string
+}
+
+func (env Environment) Record(entry string) {
+	*env.Log = append(*env.Log, entry)
+}
+
+const (
+	StateStopped = "stopped"
+	StatePlaying = "playing"
+	StatePaused  = "paused"
+)
+
+type EventPlay struct{}
+type EventPause struct{}
+type EventRestart struct{}
+type EventStop struct{}
+type EventSeek struct {
+	Position int
+}
+type EventVolume struct {
+	Level int
+}
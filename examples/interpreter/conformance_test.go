@@ -0,0 +1,421 @@
+package interpreter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+var start = time.Date(2020, 11, 13, 6, 35, 0, 0, time.UTC)
+
+var errBuffering = errors.New("buffering failed")
+
+// player is implemented by both the generated machine and the interpreter, with states and events named by strings.
+type player interface {
+	Start(ctx context.Context) error
+	Trigger(ctx context.Context, event string, payload interface{}) error
+	Configuration() []string
+	AvailableEvents() []string
+	TransitionTable() []runtime.TransitionRule[string, string]
+	IsDone() bool
+	State() State
+	Snapshot() []byte
+	// Reload replaces the player with one restored from a snapshot of it.
+	Reload(t *testing.T)
+	Advance(d time.Duration)
+}
+
+// The handlers of both players share these functions, so that any difference in behaviour comes from the engine.
+
+func nextAction(state *State) error {
+	state.Track++
+	return nil
+}
+
+func bufferedAction(state *State, ev EventBuffered) error {
+	state.Position = -1
+	if ev.Fail {
+		return errBuffering
+	}
+	return nil
+}
+
+func lastTrackGuard(state State) bool {
+	return state.Track+1 >= state.Tracks
+}
+
+func inRangeGuard(ev EventVolume) bool {
+	return ev.Level >= 0 && ev.Level <= 10
+}
+
+func cloneState(state *State) *State {
+	clone := *state
+	return &clone
+}
+
+type generatedPlayer struct {
+	machine *PlayerMachine
+	env     Environment
+}
+
+func newGeneratedPlayer(env Environment) *generatedPlayer {
+	p := &generatedPlayer{env: env}
+	p.setup(NewPlayerMachine(&State{Tracks: 2}, env))
+	return p
+}
+
+func (p *generatedPlayer) setup(machine *PlayerMachine) {
+	env := p.env
+	p.machine = machine
+	machine.CloneState = cloneState
+	enter := func(state string) func(ctx PlayerMachineContext, env Environment, state State) error {
+		return func(ctx PlayerMachineContext, env Environment, _ State) error {
+			env.Record("enter " + state)
+			return nil
+		}
+	}
+	exit := func(state string) func(ctx PlayerMachineContext, env Environment, state State) error {
+		return func(ctx PlayerMachineContext, env Environment, _ State) error {
+			env.Record("exit " + state)
+			return nil
+		}
+	}
+	machine.OnStateStopped, machine.OnExitStopped = enter(StateStopped), exit(StateStopped)
+	machine.OnStateActive, machine.OnExitActive = enter(StateActive), exit(StateActive)
+	machine.OnStatePlaying, machine.OnExitPlaying = enter(StatePlaying), exit(StatePlaying)
+	machine.OnStatePaused, machine.OnExitPaused = enter(StatePaused), exit(StatePaused)
+	machine.OnStateBuffering, machine.OnExitBuffering = enter(StateBuffering), exit(StateBuffering)
+	machine.OnStateEjected, machine.OnExitEjected = enter(StateEjected), exit(StateEjected)
+	machine.NextAction = func(ctx PlayerMachineContext, state *State, ev EventNext) error {
+		env.Record("action next")
+		return nextAction(state)
+	}
+	machine.BufferedAction = func(ctx PlayerMachineContext, state *State, ev EventBuffered) error {
+		env.Record("action buffered")
+		return bufferedAction(state, ev)
+	}
+	machine.SeekAction = func(ctx PlayerMachineContext, state *State, ev EventSeek) error {
+		state.Position = ev.Position
+		return nil
+	}
+	machine.VolumeAction = func(ctx PlayerMachineContext, state *State, ev EventVolume) error {
+		state.Volume = ev.Level
+		return nil
+	}
+	machine.NextLastTrackGuard = func(ctx PlayerMachineContext, state State, ev EventNext) bool {
+		return lastTrackGuard(state)
+	}
+	machine.VolumeInRangeGuard = func(ctx PlayerMachineContext, state State, ev EventVolume) bool {
+		return inRangeGuard(ev)
+	}
+	machine.LeaveActiveHook = func(ctx PlayerMachineContext, env Environment, state State, transition PlayerTransition) error {
+		env.Record("hook leave_active")
+		return nil
+	}
+}
+
+func (p *generatedPlayer) Start(ctx context.Context) error {
+	return p.machine.Start(ctx)
+}
+
+func (p *generatedPlayer) Trigger(ctx context.Context, event string, payload interface{}) error {
+	return p.machine.Trigger(ctx, PlayerEvent(event), payload)
+}
+
+func (p *generatedPlayer) Configuration() []string {
+	states := []string{}
+	for _, state := range p.machine.Configuration() {
+		states = append(states, string(state))
+	}
+	return states
+}
+
+func (p *generatedPlayer) AvailableEvents() []string {
+	events := []string{}
+	for _, event := range p.machine.AvailableEvents() {
+		events = append(events, string(event))
+	}
+	return events
+}
+
+func (p *generatedPlayer) TransitionTable() []runtime.TransitionRule[string, string] {
+	rules := []runtime.TransitionRule[string, string]{}
+	for _, rule := range p.machine.TransitionTable() {
+		rules = append(rules, runtime.TransitionRule[string, string]{From: string(rule.From), Event: string(rule.Event), To: string(rule.To), Guard: rule.Guard, Internal: rule.Internal})
+	}
+	return rules
+}
+
+func (p *generatedPlayer) IsDone() bool {
+	return p.machine.IsDone()
+}
+
+func (p *generatedPlayer) State() State {
+	return *p.machine.State
+}
+
+func (p *generatedPlayer) Snapshot() []byte {
+	data, err := json.Marshal(p.machine.Snapshot())
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func (p *generatedPlayer) Reload(t *testing.T) {
+	var snapshot PlayerSnapshot
+	assert.NilError(t, json.Unmarshal(p.Snapshot(), &snapshot))
+	machine, err := NewPlayerMachineFromSnapshot(snapshot, p.env)
+	assert.NilError(t, err)
+	p.setup(machine)
+}
+
+func (p *generatedPlayer) Advance(d time.Duration) {
+	p.env.FakeClock.Advance(d)
+}
+
+type interpretedPlayer struct {
+	gen    *fsmgen.Generator
+	interp *fsmgen.Interpreter
+	env    Environment
+}
+
+func newInterpretedPlayer(t *testing.T, env Environment) *interpretedPlayer {
+	p := &interpretedPlayer{gen: NewGenerator(), env: env}
+	interp, err := p.gen.Interpreter(&State{Tracks: 2}, env)
+	assert.NilError(t, err)
+	p.setup(interp)
+	return p
+}
+
+func (p *interpretedPlayer) setup(interp *fsmgen.Interpreter) {
+	p.interp = interp
+	interp.CloneState = func(state interface{}) interface{} {
+		return cloneState(state.(*State))
+	}
+	for _, state := range p.gen.States {
+		state := state
+		interp.OnState[state] = func(ctx fsmgen.InterpreterContext, env interface{}, _ interface{}) error {
+			env.(Environment).Record("enter " + state)
+			return nil
+		}
+		interp.OnExit[state] = func(ctx fsmgen.InterpreterContext, env interface{}, _ interface{}) error {
+			env.(Environment).Record("exit " + state)
+			return nil
+		}
+	}
+	interp.Actions["next"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) error {
+		p.env.Record("action next")
+		return nextAction(state.(*State))
+	}
+	interp.Actions["buffered"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) error {
+		p.env.Record("action buffered")
+		return bufferedAction(state.(*State), ev.(EventBuffered))
+	}
+	interp.Actions["seek"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) error {
+		state.(*State).Position = ev.(EventSeek).Position
+		return nil
+	}
+	interp.Actions["volume"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) error {
+		state.(*State).Volume = ev.(EventVolume).Level
+		return nil
+	}
+	interp.Guards["next"]["last_track"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) bool {
+		return lastTrackGuard(state.(State))
+	}
+	interp.Guards["volume"]["in_range"] = func(ctx fsmgen.InterpreterContext, state interface{}, ev interface{}) bool {
+		return inRangeGuard(ev.(EventVolume))
+	}
+	interp.Hooks["leave_active"] = func(ctx fsmgen.InterpreterContext, env interface{}, state interface{}, transition fsmgen.InterpreterTransition) error {
+		env.(Environment).Record("hook leave_active")
+		return nil
+	}
+}
+
+func (p *interpretedPlayer) Start(ctx context.Context) error {
+	return p.interp.Start(ctx)
+}
+
+func (p *interpretedPlayer) Trigger(ctx context.Context, event string, payload interface{}) error {
+	return p.interp.Trigger(ctx, event, payload)
+}
+
+func (p *interpretedPlayer) Configuration() []string {
+	return p.interp.Configuration()
+}
+
+func (p *interpretedPlayer) AvailableEvents() []string {
+	return p.interp.AvailableEvents()
+}
+
+func (p *interpretedPlayer) TransitionTable() []runtime.TransitionRule[string, string] {
+	return p.interp.TransitionTable()
+}
+
+func (p *interpretedPlayer) IsDone() bool {
+	return p.interp.IsDone()
+}
+
+func (p *interpretedPlayer) State() State {
+	return *p.interp.StateObject().(*State)
+}
+
+func (p *interpretedPlayer) Snapshot() []byte {
+	data, err := json.Marshal(p.interp.Snapshot())
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func (p *interpretedPlayer) Reload(t *testing.T) {
+	var state interface{} = &State{}
+	snapshot := fsmgen.InterpreterSnapshot{State: &state}
+	assert.NilError(t, json.Unmarshal(p.Snapshot(), &snapshot))
+	interp, err := p.gen.Interpreter(&State{}, p.env)
+	assert.NilError(t, err)
+	assert.NilError(t, interp.Restore(snapshot))
+	p.setup(interp)
+}
+
+func (p *interpretedPlayer) Advance(d time.Duration) {
+	p.env.FakeClock.Advance(d)
+}
+
+// step is an event triggered on both players, a clock advance, or a reload from a snapshot.
+type step struct {
+	event   string
+	payload interface{}
+	advance time.Duration
+	reload  bool
+}
+
+// observation is everything observable about a player after a step.
+type observation struct {
+	Err             string
+	Configuration   []string
+	AvailableEvents []string
+	Done            bool
+	State           State
+	Log             []string
+	Snapshot        string
+}
+
+var script = []step{
+	{event: "volume", payload: EventVolume{Level: 3}},
+	{event: "play", payload: EventPlay{}},
+	{event: "next", payload: EventNext{}},
+	{event: "pause", payload: EventPause{}},
+	{event: "seek", payload: EventSeek{Position: 42}},
+	{event: "stop", payload: EventStop{}},
+	{event: "play", payload: EventPlay{}},
+	{event: "resume", payload: EventResume{}},
+	{event: "buffer", payload: EventBuffer{}},
+	{advance: 3 * time.Second},
+	{reload: true},
+	{advance: 2 * time.Second},
+	{event: "play", payload: EventPlay{}},
+	{event: "buffered", payload: EventBuffered{Fail: true}},
+	{event: "buffered", payload: EventBuffered{}},
+	{event: "next", payload: EventNext{}},
+	{event: "pause", payload: EventPause{}},
+	{event: "volume", payload: EventVolume{Level: 11}},
+	{event: "volume", payload: EventPlay{}},
+	{event: "rewind", payload: nil},
+	{event: "eject", payload: EventEject{}},
+	{event: "play", payload: EventPlay{}},
+}
+
+// run runs the script on the supplied player, returning an observation per step.
+func run(t *testing.T, p player, log *[]string) []observation {
+	ctx := context.Background()
+	assert.NilError(t, p.Start(ctx))
+	observations := []observation{}
+	for _, step := range script {
+		*log = nil
+		var err error
+		switch {
+		case step.reload:
+			p.Reload(t)
+		case step.advance != 0:
+			p.Advance(step.advance)
+		default:
+			err = p.Trigger(ctx, step.event, step.payload)
+		}
+		observed := observation{
+			Configuration:   p.Configuration(),
+			AvailableEvents: p.AvailableEvents(),
+			Done:            p.IsDone(),
+			State:           p.State(),
+			Log:             *log,
+			Snapshot:        string(p.Snapshot()),
+		}
+		if err != nil {
+			observed.Err = err.Error()
+		}
+		observations = append(observations, observed)
+	}
+	return observations
+}
+
+func TestConformance(t *testing.T) {
+	generatedLog, interpretedLog := []string{}, []string{}
+	generated := newGeneratedPlayer(Environment{Log: &generatedLog, FakeClock: runtime.NewFakeClock(start)})
+	interpreted := newInterpretedPlayer(t, Environment{Log: &interpretedLog, FakeClock: runtime.NewFakeClock(start)})
+	assert.DeepEqual(t, generated.TransitionTable(), interpreted.TransitionTable())
+
+	expected := run(t, generated, &generatedLog)
+	actual := run(t, interpreted, &interpretedLog)
+	assert.Equal(t, len(script), len(actual))
+	for i := range script {
+		// Payload type errors name the event object type as written in the generated code, or as reported by
+		// reflection in the interpreter.
+		if script[i].event == "volume" && script[i].payload == (EventPlay{}) {
+			assert.Equal(t, "invalid payload for event volume: expected EventVolume, got interpreter.EventPlay", expected[i].Err)
+			assert.Equal(t, "invalid payload for event volume: expected interpreter.EventVolume, got interpreter.EventPlay", actual[i].Err)
+			expected[i].Err = actual[i].Err
+		}
+		assert.DeepEqual(t, expected[i], actual[i])
+	}
+}
+
+// TestConformanceScript checks the script exercises what it is meant to, so that the conformance test cannot pass by
+// having both players reject every event.
+func TestConformanceScript(t *testing.T) {
+	log := []string{}
+	observations := run(t, newGeneratedPlayer(Environment{Log: &log, FakeClock: runtime.NewFakeClock(start)}), &log)
+	at := func(event string, n int) observation {
+		for i, step := range script {
+			if step.event == event {
+				if n == 0 {
+					return observations[i]
+				}
+				n--
+			}
+		}
+		t.Fatalf("no step %d for %s", n, event)
+		return observation{}
+	}
+	assert.Equal(t, 3, at("volume", 0).State.Volume)
+	assert.DeepEqual(t, []string{"exit stopped", "enter active", "enter playing"}, at("play", 0).Log)
+	assert.DeepEqual(t, []string{"exit playing", "hook leave_active", "action next", "enter playing"}, at("next", 0).Log)
+	assert.Equal(t, 42, at("seek", 0).State.Position)
+	assert.Equal(t, 0, len(at("seek", 0).Log))
+	// History resumes the paused state, and later the buffering state that timed out.
+	assert.DeepEqual(t, []string{StateActive, StatePaused}, at("play", 1).Configuration)
+	assert.DeepEqual(t, []string{StateActive, StateBuffering}, at("play", 2).Configuration)
+	// The failed action is rolled back by CloneState.
+	failed := at("buffered", 0)
+	assert.Equal(t, "BufferedAction failed on buffered from buffering to playing: buffering failed", failed.Err)
+	assert.Equal(t, 42, failed.State.Position)
+	assert.DeepEqual(t, []string{StateActive, StateBuffering}, failed.Configuration)
+	assert.Equal(t, -1, at("buffered", 1).State.Position)
+	assert.Assert(t, at("eject", 0).Done)
+	assert.Equal(t, "machine is done: play cannot be triggered from final state ejected", at("play", 3).Err)
+}
@@ -0,0 +1,16 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen/examples/interpreter"
+)
+
+func main() {
+	err := interpreter.NewGenerator().Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
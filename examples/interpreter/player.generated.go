@@ -0,0 +1,827 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateStopped   PlayerState = "stopped"
+	PlayerStateActive    PlayerState = "active"
+	PlayerStatePlaying   PlayerState = "playing"
+	PlayerStatePaused    PlayerState = "paused"
+	PlayerStateBuffering PlayerState = "buffering"
+	PlayerStateEjected   PlayerState = "ejected"
+)
+
+// History pseudo-states, which may be the target of a transition but are never active.
+const (
+	PlayerStateLast PlayerState = "last"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateStopped, PlayerStateActive, PlayerStatePlaying, PlayerStatePaused, PlayerStateBuffering, PlayerStateEjected, PlayerStateLast:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventPlay     PlayerEvent = "play"
+	PlayerEventPause    PlayerEvent = "pause"
+	PlayerEventResume   PlayerEvent = "resume"
+	PlayerEventBuffer   PlayerEvent = "buffer"
+	PlayerEventBuffered PlayerEvent = "buffered"
+	PlayerEventTimeout  PlayerEvent = "timeout"
+	PlayerEventNext     PlayerEvent = "next"
+	PlayerEventSeek     PlayerEvent = "seek"
+	PlayerEventVolume   PlayerEvent = "volume"
+	PlayerEventStop     PlayerEvent = "stop"
+	PlayerEventEject    PlayerEvent = "eject"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventPlay, PlayerEventPause, PlayerEventResume, PlayerEventBuffer, PlayerEventBuffered, PlayerEventTimeout, PlayerEventNext, PlayerEventSeek, PlayerEventVolume, PlayerEventStop, PlayerEventEject:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, State]
+
+	env Environment
+
+	PlayAction     func(ctx PlayerMachineContext, state *State, ev EventPlay) error
+	PauseAction    func(ctx PlayerMachineContext, state *State, ev EventPause) error
+	ResumeAction   func(ctx PlayerMachineContext, state *State, ev EventResume) error
+	BufferAction   func(ctx PlayerMachineContext, state *State, ev EventBuffer) error
+	BufferedAction func(ctx PlayerMachineContext, state *State, ev EventBuffered) error
+	TimeoutAction  func(ctx PlayerMachineContext, state *State, ev EventTimeout) error
+	NextAction     func(ctx PlayerMachineContext, state *State, ev EventNext) error
+	SeekAction     func(ctx PlayerMachineContext, state *State, ev EventSeek) error
+	VolumeAction   func(ctx PlayerMachineContext, state *State, ev EventVolume) error
+	StopAction     func(ctx PlayerMachineContext, state *State, ev EventStop) error
+	EjectAction    func(ctx PlayerMachineContext, state *State, ev EventEject) error
+
+	NextLastTrackGuard func(ctx PlayerMachineContext, state State, ev EventNext) bool
+	VolumeInRangeGuard func(ctx PlayerMachineContext, state State, ev EventVolume) bool
+
+	OnStateStopped   func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateActive    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePlaying   func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePaused    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateBuffering func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateEjected   func(ctx PlayerMachineContext, env Environment, state State) error
+
+	OnExitStopped   func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitActive    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPlaying   func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPaused    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitBuffering func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitEjected   func(ctx PlayerMachineContext, env Environment, state State) error
+
+	LeaveActiveHook func(ctx PlayerMachineContext, env Environment, state State, transition PlayerTransition) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, State]
+
+// PlayerTimerSnapshot is a pending delayed transition of a PlayerSnapshot.
+type PlayerTimerSnapshot = fsmruntime.TimerSnapshot[PlayerState, PlayerEvent]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerPlay(ev EventPlay) error
+	TriggerPause(ev EventPause) error
+	TriggerResume(ev EventResume) error
+	TriggerBuffer(ev EventBuffer) error
+	TriggerBuffered(ev EventBuffered) error
+	TriggerTimeout(ev EventTimeout) error
+	TriggerNext(ev EventNext) error
+	TriggerSeek(ev EventSeek) error
+	TriggerVolume(ev EventVolume) error
+	TriggerStop(ev EventStop) error
+	TriggerEject(ev EventEject) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPlay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerPlay(ev EventPlay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPlay(ctx.ctx, ev)
+	})
+}
+
+// TriggerPause queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerPause(ev EventPause) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPause(ctx.ctx, ev)
+	})
+}
+
+// TriggerResume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerResume(ev EventResume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerResume(ctx.ctx, ev)
+	})
+}
+
+// TriggerBuffer queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerBuffer(ev EventBuffer) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerBuffer(ctx.ctx, ev)
+	})
+}
+
+// TriggerBuffered queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerBuffered(ev EventBuffered) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerBuffered(ctx.ctx, ev)
+	})
+}
+
+// TriggerTimeout queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerTimeout(ev EventTimeout) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerTimeout(ctx.ctx, ev)
+	})
+}
+
+// TriggerNext queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerNext(ev EventNext) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerNext(ctx.ctx, ev)
+	})
+}
+
+// TriggerSeek queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerSeek(ev EventSeek) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerSeek(ctx.ctx, ev)
+	})
+}
+
+// TriggerVolume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerVolume(ev EventVolume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerVolume(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerStop(ev EventStop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// TriggerEject queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerEject(ev EventEject) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerEject(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateStopped, PlayerStateActive, PlayerStatePlaying, PlayerStatePaused, PlayerStateBuffering, PlayerStateEjected},
+	Events: []PlayerEvent{PlayerEventPlay, PlayerEventPause, PlayerEventResume, PlayerEventBuffer, PlayerEventBuffered, PlayerEventTimeout, PlayerEventNext, PlayerEventSeek, PlayerEventVolume, PlayerEventStop, PlayerEventEject},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {
+			PlayerEventVolume: "",
+		},
+		PlayerStateActive: {
+			PlayerEventSeek: "",
+			PlayerEventStop: PlayerStateStopped,
+		},
+		PlayerStateBuffering: {
+			PlayerEventBuffered: PlayerStatePlaying,
+			PlayerEventTimeout:  PlayerStateStopped,
+		},
+		PlayerStateEjected: {},
+		PlayerStatePaused: {
+			PlayerEventNext:   PlayerStatePlaying,
+			PlayerEventResume: PlayerStatePlaying,
+		},
+		PlayerStatePlaying: {
+			PlayerEventBuffer: PlayerStateBuffering,
+			PlayerEventNext:   PlayerStatePlaying,
+			PlayerEventPause:  PlayerStatePaused,
+		},
+		PlayerStateStopped: {
+			PlayerEventEject: PlayerStateEjected,
+			PlayerEventPlay:  PlayerStateLast,
+		},
+	},
+	Internal: map[PlayerState]map[PlayerEvent]bool{
+		"": {
+			PlayerEventVolume: true,
+		},
+		PlayerStateActive: {
+			PlayerEventSeek: true,
+		},
+	},
+	Parents: map[PlayerState]PlayerState{
+		PlayerStateBuffering: PlayerStateActive,
+		PlayerStateLast:      PlayerStateActive,
+		PlayerStatePaused:    PlayerStateActive,
+		PlayerStatePlaying:   PlayerStateActive,
+	},
+	Initial: map[PlayerState]PlayerState{
+		PlayerStateActive: PlayerStatePlaying,
+	},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateStopped:   0,
+		PlayerStateActive:    1,
+		PlayerStatePlaying:   2,
+		PlayerStatePaused:    3,
+		PlayerStateBuffering: 4,
+		PlayerStateEjected:   5,
+	},
+	Final: map[PlayerState]bool{
+		PlayerStateEjected: true,
+	},
+	Histories: map[PlayerState]fsmruntime.History[PlayerState]{
+		PlayerStateLast: {Parent: PlayerStateActive, Default: PlayerStatePlaying, Deep: false},
+	},
+	Delayed: map[PlayerState][]fsmruntime.Delay[PlayerEvent]{
+		PlayerStateBuffering: {
+			{Event: PlayerEventTimeout, After: 5 * time.Second},
+		},
+	},
+	Rules: []PlayerTransitionRule{
+		{From: PlayerStateStopped, Event: PlayerEventPlay, To: PlayerStateLast},
+		{From: PlayerStatePlaying, Event: PlayerEventPause, To: PlayerStatePaused},
+		{From: PlayerStatePaused, Event: PlayerEventResume, To: PlayerStatePlaying},
+		{From: PlayerStatePlaying, Event: PlayerEventBuffer, To: PlayerStateBuffering},
+		{From: PlayerStateBuffering, Event: PlayerEventBuffered, To: PlayerStatePlaying},
+		{From: PlayerStateBuffering, Event: PlayerEventTimeout, To: PlayerStateStopped},
+		{From: PlayerStatePlaying, Event: PlayerEventNext, To: PlayerStateStopped, Guard: "last_track"},
+		{From: PlayerStatePlaying, Event: PlayerEventNext, To: PlayerStatePlaying},
+		{From: PlayerStatePaused, Event: PlayerEventNext, To: PlayerStateStopped, Guard: "last_track"},
+		{From: PlayerStatePaused, Event: PlayerEventNext, To: PlayerStatePlaying},
+		{From: PlayerStateActive, Event: PlayerEventSeek, To: PlayerStateActive, Internal: true},
+		{From: "", Event: PlayerEventVolume, To: "", Guard: "in_range", Internal: true},
+		{From: PlayerStateActive, Event: PlayerEventStop, To: PlayerStateStopped},
+		{From: PlayerStateStopped, Event: PlayerEventEject, To: PlayerStateEjected},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *State, env Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter:     machine.enterState,
+		Exit:      machine.exitState,
+		Hooks:     machine.runTransitionHooks,
+		Event:     machine.eventFunc,
+		Automatic: machine.triggerAutomatic,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventPlay:
+		ev, ok := payload.(EventPlay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPlay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPlay(ctx, ev)
+		}, nil
+	case PlayerEventPause:
+		ev, ok := payload.(EventPause)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPause, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPause(ctx, ev)
+		}, nil
+	case PlayerEventResume:
+		ev, ok := payload.(EventResume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventResume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerResume(ctx, ev)
+		}, nil
+	case PlayerEventBuffer:
+		ev, ok := payload.(EventBuffer)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventBuffer, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerBuffer(ctx, ev)
+		}, nil
+	case PlayerEventBuffered:
+		ev, ok := payload.(EventBuffered)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventBuffered, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerBuffered(ctx, ev)
+		}, nil
+	case PlayerEventTimeout:
+		ev, ok := payload.(EventTimeout)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventTimeout, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerTimeout(ctx, ev)
+		}, nil
+	case PlayerEventNext:
+		ev, ok := payload.(EventNext)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventNext, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerNext(ctx, ev)
+		}, nil
+	case PlayerEventSeek:
+		ev, ok := payload.(EventSeek)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventSeek, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerSeek(ctx, ev)
+		}, nil
+	case PlayerEventVolume:
+		ev, ok := payload.(EventVolume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventVolume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerVolume(ctx, ev)
+		}, nil
+	case PlayerEventStop:
+		ev, ok := payload.(EventStop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	case PlayerEventEject:
+		ev, ok := payload.(EventEject)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventEject, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerEject(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+// triggerAutomatic processes the supplied delayed or completion event with a zero value event object.
+func (machine *PlayerMachine) triggerAutomatic(ctx context.Context, event PlayerEvent) error {
+	switch event {
+	case PlayerEventTimeout:
+		var ev EventTimeout
+		return machine.triggerTimeout(ctx, ev)
+	}
+	return fmt.Errorf("unknown automatic event %s", event)
+}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition, in declaration order. A hook's states
+// match the transition's states and any of their ancestors.
+func (machine *PlayerMachine) runTransitionHooks(ctx context.Context, transition PlayerTransition) error {
+	if machine.LeaveActiveHook != nil && playerDefinition.Within(transition.From, PlayerStateActive) {
+		err := machine.LeaveActiveHook(newPlayerContext(ctx, machine), machine.env, *machine.State, transition)
+		if err != nil {
+			return fsmruntime.WrapHandlerError("LeaveActiveHook", transition, err)
+		}
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateStopped:
+		if machine.OnExitStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitStopped", transition, machine.OnExitStopped(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnExitActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitActive", transition, machine.OnExitActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnExitPaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaused", transition, machine.OnExitPaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateBuffering:
+		if machine.OnExitBuffering == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitBuffering", transition, machine.OnExitBuffering(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateEjected:
+		if machine.OnExitEjected == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitEjected", transition, machine.OnExitEjected(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateStopped:
+		if machine.OnStateStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateStopped", transition, machine.OnStateStopped(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateActive:
+		if machine.OnStateActive == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateActive", transition, machine.OnStateActive(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePaused:
+		if machine.OnStatePaused == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaused", transition, machine.OnStatePaused(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateBuffering:
+		if machine.OnStateBuffering == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateBuffering", transition, machine.OnStateBuffering(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateEjected:
+		if machine.OnStateEjected == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateEjected", transition, machine.OnStateEjected(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPlay triggers the play event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPlay(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Fire(ctx, PlayerEventPlay, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.PlayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PlayAction", transition, machine.PlayAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPause triggers the pause event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPause(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerPause(ctx context.Context, ev EventPause) error {
+	return machine.Fire(ctx, PlayerEventPause, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.PauseAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PauseAction", transition, machine.PauseAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerResume triggers the resume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerResume(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Fire(ctx, PlayerEventResume, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.ResumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ResumeAction", transition, machine.ResumeAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerBuffer triggers the buffer event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerBuffer(ctx context.Context, ev EventBuffer) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerBuffer(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerBuffer(ctx context.Context, ev EventBuffer) error {
+	return machine.Fire(ctx, PlayerEventBuffer, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.BufferAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("BufferAction", transition, machine.BufferAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerBuffered triggers the buffered event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerBuffered(ctx context.Context, ev EventBuffered) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerBuffered(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerBuffered(ctx context.Context, ev EventBuffered) error {
+	return machine.Fire(ctx, PlayerEventBuffered, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.BufferedAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("BufferedAction", transition, machine.BufferedAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerTimeout triggers the timeout event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerTimeout(ctx context.Context, ev EventTimeout) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerTimeout(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerTimeout(ctx context.Context, ev EventTimeout) error {
+	return machine.Fire(ctx, PlayerEventTimeout, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.TimeoutAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("TimeoutAction", transition, machine.TimeoutAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerNext triggers the next event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerNext(ctx context.Context, ev EventNext) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerNext(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerNext(ctx context.Context, ev EventNext) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == PlayerStatePlaying || from == PlayerStatePaused {
+			switch {
+			case machine.NextLastTrackGuard != nil && machine.NextLastTrackGuard(guardCtx, *machine.State, ev):
+				return PlayerStateStopped, nil
+			}
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventNext, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.NextAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("NextAction", transition, machine.NextAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerSeek triggers the seek event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerSeek(ctx context.Context, ev EventSeek) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerSeek(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerSeek(ctx context.Context, ev EventSeek) error {
+	return machine.Fire(ctx, PlayerEventSeek, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.SeekAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("SeekAction", transition, machine.SeekAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerVolume triggers the volume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerVolume(ctx context.Context, ev EventVolume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerVolume(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerVolume(ctx context.Context, ev EventVolume) error {
+	guardCtx := newPlayerContext(ctx, machine)
+	resolve := func(from, target PlayerState) (PlayerState, error) {
+		if from == "" {
+			switch {
+			case machine.VolumeInRangeGuard != nil && machine.VolumeInRangeGuard(guardCtx, *machine.State, ev):
+				return "", nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, PlayerEventVolume, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, PlayerEventVolume, ev, resolve, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.VolumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("VolumeAction", transition, machine.VolumeAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Fire(ctx, PlayerEventStop, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerEject triggers the eject event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerEject(ctx context.Context, ev EventEject) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerEject(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerEject(ctx context.Context, ev EventEject) error {
+	return machine.Fire(ctx, PlayerEventEject, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.EjectAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("EjectAction", transition, machine.EjectAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"time"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/runtime"
+)
+
+//go:generate go run gen/gen.go
+
+// State is the player's state object.
+type State struct {
+	Track    int
+	Tracks   int
+	Position int
+	Volume   int
+}
+
+// Environment records the order handlers are called in, and supplies the clock used to schedule delayed transitions.
+type Environment struct {
+	Log       *[]string
+	FakeClock *runtime.FakeClock
+}
+
+func (env Environment) Record(entry string) {
+	*env.Log = append(*env.Log, entry)
+}
+
+func (env Environment) Clock() runtime.Clock {
+	return env.FakeClock
+}
+
+const (
+	StateStopped   = "stopped"
+	StateActive    = "active"
+	StatePlaying   = "playing"
+	StatePaused    = "paused"
+	StateBuffering = "buffering"
+	StateEjected   = "ejected"
+	HistoryLast    = "last"
+)
+
+type EventPlay struct{}
+type EventPause struct{}
+type EventResume struct{}
+type EventBuffer struct{}
+type EventBuffered struct {
+	Fail bool
+}
+type EventTimeout struct{}
+type EventNext struct{}
+type EventSeek struct {
+	Position int
+}
+type EventVolume struct {
+	Level int
+}
+type EventStop struct{}
+type EventEject struct{}
+
+// NewGenerator returns the player's definition, which is both generated into this package and run by an interpreter,
+// so that the two can be checked for conformance.
+func NewGenerator() *fsmgen.Generator {
+	gen := fsmgen.New("player", State{}, Environment{}, StateStopped, StateActive, StatePlaying, StatePaused, StateBuffering, StateEjected)
+	gen.PackageName = "interpreter"
+	gen.AddSubstates(StateActive, StatePlaying, StatePaused, StateBuffering)
+	gen.ShallowHistory(HistoryLast, StateActive, StatePlaying)
+	gen.Final(StateEjected)
+	gen.AddEvent(fsmgen.NewEvent("play", EventPlay{}).From(StateStopped).To(HistoryLast))
+	gen.AddEvent(fsmgen.NewEvent("pause", EventPause{}).From(StatePlaying).To(StatePaused))
+	gen.AddEvent(fsmgen.NewEvent("resume", EventResume{}).From(StatePaused).To(StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("buffer", EventBuffer{}).From(StatePlaying).To(StateBuffering))
+	gen.AddEvent(fsmgen.NewEvent("buffered", EventBuffered{}).From(StateBuffering).To(StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("timeout", EventTimeout{}).From(StateBuffering).To(StateStopped).After(5 * time.Second))
+	gen.AddEvent(fsmgen.NewEvent("next", EventNext{}).From(StatePlaying, StatePaused).Branch("last_track", StateStopped).To(StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("seek", EventSeek{}).From(StateActive).Internal())
+	gen.AddEvent(fsmgen.NewEvent("volume", EventVolume{}).FromAny().Guard("in_range").Internal())
+	gen.AddEvent(fsmgen.NewEvent("stop", EventStop{}).From(StateActive).To(StateStopped))
+	gen.AddEvent(fsmgen.NewEvent("eject", EventEject{}).From(StateStopped).To(StateEjected))
+	gen.AddTransitionHook("leave_active", StateActive, "")
+	return gen
+}
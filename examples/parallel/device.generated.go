@@ -0,0 +1,645 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// DeviceState is a state the DeviceMachine may be in.
+type DeviceState string
+
+const (
+	DeviceStateOff      DeviceState = "off"
+	DeviceStateOn       DeviceState = "on"
+	DeviceStateNetwork  DeviceState = "network"
+	DeviceStateOffline  DeviceState = "offline"
+	DeviceStateOnline   DeviceState = "online"
+	DeviceStatePlayback DeviceState = "playback"
+	DeviceStateStopped  DeviceState = "stopped"
+	DeviceStatePlaying  DeviceState = "playing"
+)
+
+// String returns the name of the state.
+func (state DeviceState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state DeviceState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseDeviceState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *DeviceState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseDeviceState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseDeviceState returns the DeviceState with the supplied name.
+func ParseDeviceState(str string) (DeviceState, error) {
+	switch DeviceState(str) {
+	case DeviceStateOff, DeviceStateOn, DeviceStateNetwork, DeviceStateOffline, DeviceStateOnline, DeviceStatePlayback, DeviceStateStopped, DeviceStatePlaying:
+		return DeviceState(str), nil
+	}
+	return "", errors.New("unknown device state: " + str)
+}
+
+// DeviceEvent is an event that may be triggered on the DeviceMachine.
+type DeviceEvent string
+
+const (
+	DeviceEventPowerOn  DeviceEvent = "power_on"
+	DeviceEventPowerOff DeviceEvent = "power_off"
+	DeviceEventConnect  DeviceEvent = "connect"
+	DeviceEventPlay     DeviceEvent = "play"
+	DeviceEventSuspend  DeviceEvent = "suspend"
+	DeviceEventWake     DeviceEvent = "wake"
+	DeviceEventResume   DeviceEvent = "resume"
+)
+
+// String returns the name of the event.
+func (event DeviceEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event DeviceEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseDeviceEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *DeviceEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseDeviceEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseDeviceEvent returns the DeviceEvent with the supplied name.
+func ParseDeviceEvent(str string) (DeviceEvent, error) {
+	switch DeviceEvent(str) {
+	case DeviceEventPowerOn, DeviceEventPowerOff, DeviceEventConnect, DeviceEventPlay, DeviceEventSuspend, DeviceEventWake, DeviceEventResume:
+		return DeviceEvent(str), nil
+	}
+	return "", errors.New("unknown device event: " + str)
+}
+
+// DeviceMachine is the device state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type DeviceMachine struct {
+	*fsmruntime.Machine[DeviceState, DeviceEvent, State]
+
+	env Environment
+
+	PowerOnAction  func(ctx DeviceMachineContext, state *State, ev EventPowerOn) error
+	PowerOffAction func(ctx DeviceMachineContext, state *State, ev EventPowerOff) error
+	ConnectAction  func(ctx DeviceMachineContext, state *State, ev EventConnect) error
+	PlayAction     func(ctx DeviceMachineContext, state *State, ev EventPlay) error
+	SuspendAction  func(ctx DeviceMachineContext, state *State, ev EventSuspend) error
+	WakeAction     func(ctx DeviceMachineContext, state *State, ev EventWake) error
+	ResumeAction   func(ctx DeviceMachineContext, state *State, ev EventResume) error
+
+	WakeChargedGuard func(ctx DeviceMachineContext, state State, ev EventWake) bool
+
+	OnStateOff      func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStateOn       func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStateNetwork  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStateOffline  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStateOnline   func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStatePlayback func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStateStopped  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnStatePlaying  func(ctx DeviceMachineContext, env Environment, state State) error
+
+	OnExitOff      func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitOn       func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitNetwork  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitOffline  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitOnline   func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitPlayback func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitStopped  func(ctx DeviceMachineContext, env Environment, state State) error
+	OnExitPlaying  func(ctx DeviceMachineContext, env Environment, state State) error
+}
+
+// DeviceTransition describes a transition of the DeviceMachine.
+type DeviceTransition = fsmruntime.Transition[DeviceState, DeviceEvent]
+
+// DeviceSnapshot is a serializable copy of the DeviceMachine's state, which
+// Restore resumes from.
+type DeviceSnapshot = fsmruntime.Snapshot[DeviceState, DeviceEvent, State]
+
+// DeviceTransitionRule describes a transition declared by the DeviceMachine's definition.
+type DeviceTransitionRule = fsmruntime.TransitionRule[DeviceState, DeviceEvent]
+
+// DeviceListener observes every event processed by a DeviceMachine.
+type DeviceListener = fsmruntime.Listener[DeviceState, DeviceEvent]
+
+// DeviceBaseListener implements DeviceListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type DeviceBaseListener = fsmruntime.BaseListener[DeviceState, DeviceEvent]
+
+// DeviceMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type DeviceMachineContext interface {
+	Context() context.Context
+	TriggerPowerOn(ev EventPowerOn) error
+	TriggerPowerOff(ev EventPowerOff) error
+	TriggerConnect(ev EventConnect) error
+	TriggerPlay(ev EventPlay) error
+	TriggerSuspend(ev EventSuspend) error
+	TriggerWake(ev EventWake) error
+	TriggerResume(ev EventResume) error
+}
+
+type deviceMachineContext struct {
+	ctx     context.Context
+	machine *DeviceMachine
+}
+
+func newDeviceContext(ctx context.Context, machine *DeviceMachine) DeviceMachineContext {
+	return &deviceMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx deviceMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPowerOn queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerPowerOn(ev EventPowerOn) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPowerOn(ctx.ctx, ev)
+	})
+}
+
+// TriggerPowerOff queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerPowerOff(ev EventPowerOff) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPowerOff(ctx.ctx, ev)
+	})
+}
+
+// TriggerConnect queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerConnect(ev EventConnect) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerConnect(ctx.ctx, ev)
+	})
+}
+
+// TriggerPlay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerPlay(ev EventPlay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPlay(ctx.ctx, ev)
+	})
+}
+
+// TriggerSuspend queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerSuspend(ev EventSuspend) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerSuspend(ctx.ctx, ev)
+	})
+}
+
+// TriggerWake queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerWake(ev EventWake) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerWake(ctx.ctx, ev)
+	})
+}
+
+// TriggerResume queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx deviceMachineContext) TriggerResume(ev EventResume) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerResume(ctx.ctx, ev)
+	})
+}
+
+// deviceDefinition is the structure of the DeviceMachine, shared by every instance.
+var deviceDefinition = &fsmruntime.Definition[DeviceState, DeviceEvent]{
+	Name:   "device",
+	States: []DeviceState{DeviceStateOff, DeviceStateOn, DeviceStateNetwork, DeviceStateOffline, DeviceStateOnline, DeviceStatePlayback, DeviceStateStopped, DeviceStatePlaying},
+	Events: []DeviceEvent{DeviceEventPowerOn, DeviceEventPowerOff, DeviceEventConnect, DeviceEventPlay, DeviceEventSuspend, DeviceEventWake, DeviceEventResume},
+	Transitions: map[DeviceState]map[DeviceEvent]DeviceState{
+		"":                 {},
+		DeviceStateNetwork: {},
+		DeviceStateOff: {
+			DeviceEventPowerOn: DeviceStateOn,
+			DeviceEventResume:  DeviceStatePlaying,
+		},
+		DeviceStateOffline: {
+			DeviceEventConnect: DeviceStateOnline,
+			DeviceEventWake:    DeviceStateOnline,
+		},
+		DeviceStateOn: {
+			DeviceEventPowerOff: DeviceStateOff,
+		},
+		DeviceStateOnline: {
+			DeviceEventSuspend: DeviceStateOffline,
+		},
+		DeviceStatePlayback: {},
+		DeviceStatePlaying: {
+			DeviceEventSuspend: DeviceStateStopped,
+		},
+		DeviceStateStopped: {
+			DeviceEventPlay: DeviceStatePlaying,
+			DeviceEventWake: DeviceStatePlaying,
+		},
+	},
+	Parents: map[DeviceState]DeviceState{
+		DeviceStateNetwork:  DeviceStateOn,
+		DeviceStateOffline:  DeviceStateNetwork,
+		DeviceStateOnline:   DeviceStateNetwork,
+		DeviceStatePlayback: DeviceStateOn,
+		DeviceStatePlaying:  DeviceStatePlayback,
+		DeviceStateStopped:  DeviceStatePlayback,
+	},
+	Initial: map[DeviceState]DeviceState{
+		DeviceStateNetwork:  DeviceStateOffline,
+		DeviceStatePlayback: DeviceStateStopped,
+	},
+	Regions: map[DeviceState][]DeviceState{
+		DeviceStateOn: {DeviceStateNetwork, DeviceStatePlayback},
+	},
+	Order: map[DeviceState]int{
+		DeviceStateOff:      0,
+		DeviceStateOn:       1,
+		DeviceStateNetwork:  2,
+		DeviceStateOffline:  3,
+		DeviceStateOnline:   4,
+		DeviceStatePlayback: 5,
+		DeviceStateStopped:  6,
+		DeviceStatePlaying:  7,
+	},
+	Rules: []DeviceTransitionRule{
+		{From: DeviceStateOff, Event: DeviceEventPowerOn, To: DeviceStateOn},
+		{From: DeviceStateOn, Event: DeviceEventPowerOff, To: DeviceStateOff},
+		{From: DeviceStateOffline, Event: DeviceEventConnect, To: DeviceStateOnline},
+		{From: DeviceStateStopped, Event: DeviceEventPlay, To: DeviceStatePlaying},
+		{From: DeviceStateOnline, Event: DeviceEventSuspend, To: DeviceStateOffline},
+		{From: DeviceStatePlaying, Event: DeviceEventSuspend, To: DeviceStateStopped},
+		{From: DeviceStateOffline, Event: DeviceEventWake, To: DeviceStateOnline},
+		{From: DeviceStateStopped, Event: DeviceEventWake, To: DeviceStatePlaying, Guard: "charged"},
+		{From: DeviceStateOff, Event: DeviceEventResume, To: DeviceStatePlaying},
+	},
+	MaxChainLength: 100,
+}
+
+// NewDeviceMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewDeviceMachine(state *State, env Environment) *DeviceMachine {
+	machine := &DeviceMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(deviceDefinition, state, fsmruntime.Handlers[DeviceState, DeviceEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewDeviceMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewDeviceMachineFromSnapshot(snapshot DeviceSnapshot, env Environment) (*DeviceMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewDeviceMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *DeviceMachine) eventFunc(event DeviceEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case DeviceEventPowerOn:
+		ev, ok := payload.(EventPowerOn)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPowerOn, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPowerOn(ctx, ev)
+		}, nil
+	case DeviceEventPowerOff:
+		ev, ok := payload.(EventPowerOff)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPowerOff, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPowerOff(ctx, ev)
+		}, nil
+	case DeviceEventConnect:
+		ev, ok := payload.(EventConnect)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventConnect, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerConnect(ctx, ev)
+		}, nil
+	case DeviceEventPlay:
+		ev, ok := payload.(EventPlay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPlay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPlay(ctx, ev)
+		}, nil
+	case DeviceEventSuspend:
+		ev, ok := payload.(EventSuspend)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventSuspend, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerSuspend(ctx, ev)
+		}, nil
+	case DeviceEventWake:
+		ev, ok := payload.(EventWake)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventWake, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerWake(ctx, ev)
+		}, nil
+	case DeviceEventResume:
+		ev, ok := payload.(EventResume)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventResume, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerResume(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *DeviceMachine) exitState(ctx context.Context, transition DeviceTransition, state DeviceState) error {
+	switch state {
+	case DeviceStateOff:
+		if machine.OnExitOff == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOff", transition, machine.OnExitOff(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOn:
+		if machine.OnExitOn == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOn", transition, machine.OnExitOn(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateNetwork:
+		if machine.OnExitNetwork == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitNetwork", transition, machine.OnExitNetwork(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOffline:
+		if machine.OnExitOffline == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOffline", transition, machine.OnExitOffline(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOnline:
+		if machine.OnExitOnline == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitOnline", transition, machine.OnExitOnline(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStatePlayback:
+		if machine.OnExitPlayback == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlayback", transition, machine.OnExitPlayback(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateStopped:
+		if machine.OnExitStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitStopped", transition, machine.OnExitStopped(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *DeviceMachine) enterState(ctx context.Context, transition DeviceTransition, state DeviceState) error {
+	switch state {
+	case DeviceStateOff:
+		if machine.OnStateOff == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOff", transition, machine.OnStateOff(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOn:
+		if machine.OnStateOn == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOn", transition, machine.OnStateOn(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateNetwork:
+		if machine.OnStateNetwork == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateNetwork", transition, machine.OnStateNetwork(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOffline:
+		if machine.OnStateOffline == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOffline", transition, machine.OnStateOffline(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateOnline:
+		if machine.OnStateOnline == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateOnline", transition, machine.OnStateOnline(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStatePlayback:
+		if machine.OnStatePlayback == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlayback", transition, machine.OnStatePlayback(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStateStopped:
+		if machine.OnStateStopped == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateStopped", transition, machine.OnStateStopped(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	case DeviceStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newDeviceContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPowerOn triggers the power_on event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerPowerOn(ctx context.Context, ev EventPowerOn) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPowerOn(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerPowerOn(ctx context.Context, ev EventPowerOn) error {
+	return machine.Fire(ctx, DeviceEventPowerOn, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.PowerOnAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PowerOnAction", transition, machine.PowerOnAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPowerOff triggers the power_off event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerPowerOff(ctx context.Context, ev EventPowerOff) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPowerOff(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerPowerOff(ctx context.Context, ev EventPowerOff) error {
+	return machine.Fire(ctx, DeviceEventPowerOff, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.PowerOffAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PowerOffAction", transition, machine.PowerOffAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerConnect triggers the connect event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerConnect(ctx context.Context, ev EventConnect) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerConnect(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerConnect(ctx context.Context, ev EventConnect) error {
+	return machine.Fire(ctx, DeviceEventConnect, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.ConnectAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ConnectAction", transition, machine.ConnectAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPlay triggers the play event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPlay(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerPlay(ctx context.Context, ev EventPlay) error {
+	return machine.Fire(ctx, DeviceEventPlay, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.PlayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PlayAction", transition, machine.PlayAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerSuspend triggers the suspend event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerSuspend(ctx context.Context, ev EventSuspend) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerSuspend(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerSuspend(ctx context.Context, ev EventSuspend) error {
+	return machine.Fire(ctx, DeviceEventSuspend, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.SuspendAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("SuspendAction", transition, machine.SuspendAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerWake triggers the wake event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerWake(ctx context.Context, ev EventWake) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerWake(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerWake(ctx context.Context, ev EventWake) error {
+	guardCtx := newDeviceContext(ctx, machine)
+	resolve := func(from, target DeviceState) (DeviceState, error) {
+		if from == DeviceStateStopped {
+			switch {
+			case machine.WakeChargedGuard != nil && machine.WakeChargedGuard(guardCtx, *machine.State, ev):
+				return DeviceStatePlaying, nil
+			}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, DeviceEventWake, machine.CurrentState)
+		}
+		return target, nil
+	}
+	return machine.Fire(ctx, DeviceEventWake, ev, resolve, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.WakeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("WakeAction", transition, machine.WakeAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerResume triggers the resume event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *DeviceMachine) TriggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerResume(ctx, ev)
+	})
+}
+
+func (machine *DeviceMachine) triggerResume(ctx context.Context, ev EventResume) error {
+	return machine.Fire(ctx, DeviceEventResume, ev, nil, func(ctx context.Context, transition DeviceTransition) error {
+		if machine.ResumeAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("ResumeAction", transition, machine.ResumeAction(newDeviceContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,40 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/parallel"
+)
+
+func main() {
+	gen := fsmgen.New("device", parallel.State{}, parallel.Environment{},
+		parallel.StateOff, parallel.StateOn,
+		parallel.StateNetwork, parallel.StateOffline, parallel.StateOnline,
+		parallel.StatePlayback, parallel.StateStopped, parallel.StatePlaying,
+	)
+	gen.PackageName = "parallel"
+	gen.AddRegions(parallel.StateOn, parallel.StateNetwork, parallel.StatePlayback)
+	gen.AddSubstates(parallel.StateNetwork, parallel.StateOffline, parallel.StateOnline)
+	gen.AddSubstates(parallel.StatePlayback, parallel.StateStopped, parallel.StatePlaying)
+	gen.AddEvent(fsmgen.NewEvent("power_on", parallel.EventPowerOn{}).From(parallel.StateOff).To(parallel.StateOn))
+	gen.AddEvent(fsmgen.NewEvent("power_off", parallel.EventPowerOff{}).From(parallel.StateOn).To(parallel.StateOff))
+	gen.AddEvent(fsmgen.NewEvent("connect", parallel.EventConnect{}).From(parallel.StateOffline).To(parallel.StateOnline))
+	gen.AddEvent(fsmgen.NewEvent("play", parallel.EventPlay{}).From(parallel.StateStopped).To(parallel.StatePlaying))
+	// Suspend is declared once for each region, and moves both regions at once.
+	gen.AddEvent(fsmgen.NewEvent("suspend", parallel.EventSuspend{}).From(parallel.StateOnline).To(parallel.StateOffline))
+	gen.AddEvent(fsmgen.NewEvent("suspend", parallel.EventSuspend{}).From(parallel.StatePlaying).To(parallel.StateStopped))
+	// Wake connects the network, and starts playback only if the battery is charged. A failed guard in one region does
+	// not stop the other region from transitioning.
+	gen.AddEvent(fsmgen.NewEvent("wake", parallel.EventWake{}).From(parallel.StateOffline).To(parallel.StateOnline))
+	gen.AddEvent(fsmgen.NewEvent("wake", parallel.EventWake{}).From(parallel.StateStopped).Guard("charged").To(parallel.StatePlaying))
+	// Resume enters the parallel state with playback already playing, and the network region in its initial state.
+	gen.AddEvent(fsmgen.NewEvent("resume", parallel.EventResume{}).From(parallel.StateOff).To(parallel.StatePlaying))
+	gen.MermaidFilename = "device.mmd"
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
@@ -0,0 +1,145 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func newMachine() (*DeviceMachine, *[]string) {
+	log := &[]string{}
+	machine := NewDeviceMachine(&State{}, Environment{Log: log})
+	record := func(entry string) func(ctx DeviceMachineContext, env Environment, state State) error {
+		return func(ctx DeviceMachineContext, env Environment, state State) error {
+			env.Record(entry)
+			return nil
+		}
+	}
+	machine.OnStateOn = record("enter on")
+	machine.OnExitOn = record("exit on")
+	machine.OnStateNetwork = record("enter network")
+	machine.OnExitNetwork = record("exit network")
+	machine.OnStateOffline = record("enter offline")
+	machine.OnExitOffline = record("exit offline")
+	machine.OnStateOnline = record("enter online")
+	machine.OnExitOnline = record("exit online")
+	machine.OnStatePlayback = record("enter playback")
+	machine.OnExitPlayback = record("exit playback")
+	machine.OnStateStopped = record("enter stopped")
+	machine.OnExitStopped = record("exit stopped")
+	machine.OnStatePlaying = record("enter playing")
+	machine.OnExitPlaying = record("exit playing")
+	return machine, log
+}
+
+func TestEnterParallelState(t *testing.T) {
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPowerOn(context.Background(), EventPowerOn{}))
+	assert.Equal(t, DeviceStateOn, machine.CurrentState)
+	assert.DeepEqual(t, []DeviceState{
+		DeviceStateOn, DeviceStateNetwork, DeviceStateOffline, DeviceStatePlayback, DeviceStateStopped,
+	}, machine.Configuration())
+	assert.DeepEqual(t, []string{
+		"enter on", "enter network", "enter offline", "enter playback", "enter stopped",
+	}, *log)
+}
+
+func TestRegionsTransitionIndependently(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPowerOn(ctx, EventPowerOn{}))
+	*log = nil
+	assert.NilError(t, machine.TriggerConnect(ctx, EventConnect{}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	assert.Assert(t, machine.IsIn(DeviceStateOnline))
+	assert.Assert(t, machine.IsIn(DeviceStatePlaying))
+	assert.Assert(t, !machine.IsIn(DeviceStateStopped))
+	assert.DeepEqual(t, []string{"exit offline", "enter online", "exit stopped", "enter playing"}, *log)
+
+	assert.ErrorContains(t, machine.TriggerConnect(ctx, EventConnect{}), "no transition target from on via connect")
+}
+
+func TestEventTransitionsEveryRegion(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPowerOn(ctx, EventPowerOn{}))
+	assert.NilError(t, machine.TriggerConnect(ctx, EventConnect{}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	actions := 0
+	machine.SuspendAction = func(ctx DeviceMachineContext, state *State, ev EventSuspend) error {
+		actions++
+		return nil
+	}
+	*log = nil
+	assert.NilError(t, machine.TriggerSuspend(ctx, EventSuspend{}))
+	assert.Equal(t, 1, actions)
+	assert.DeepEqual(t, []DeviceState{
+		DeviceStateOn, DeviceStateNetwork, DeviceStateOffline, DeviceStatePlayback, DeviceStateStopped,
+	}, machine.Configuration())
+	// Exits run in reverse document order and entries in document order.
+	assert.DeepEqual(t, []string{"exit playing", "exit online", "enter offline", "enter stopped"}, *log)
+}
+
+func TestEventInOneRegion(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPowerOn(ctx, EventPowerOn{}))
+	assert.NilError(t, machine.TriggerPlay(ctx, EventPlay{}))
+	*log = nil
+	assert.NilError(t, machine.TriggerSuspend(ctx, EventSuspend{}))
+	assert.DeepEqual(t, []string{"exit playing", "enter stopped"}, *log)
+}
+
+func TestGuardInOneRegion(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	machine.WakeChargedGuard = func(ctx DeviceMachineContext, state State, ev EventWake) bool {
+		return state.Charged
+	}
+	assert.NilError(t, machine.TriggerPowerOn(ctx, EventPowerOn{}))
+	*log = nil
+	// The playback region's guard fails, but the network region still transitions.
+	assert.NilError(t, machine.TriggerWake(ctx, EventWake{}))
+	assert.DeepEqual(t, []DeviceState{
+		DeviceStateOn, DeviceStateNetwork, DeviceStateOnline, DeviceStatePlayback, DeviceStateStopped,
+	}, machine.Configuration())
+	assert.DeepEqual(t, []string{"exit offline", "enter online"}, *log)
+
+	// The guard's error is returned once no region transitions.
+	assert.NilError(t, machine.TriggerSuspend(ctx, EventSuspend{}))
+	assert.NilError(t, machine.TriggerConnect(ctx, EventConnect{}))
+	err := machine.TriggerWake(ctx, EventWake{})
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrGuardRejected))
+
+	machine.State.Charged = true
+	*log = nil
+	assert.NilError(t, machine.TriggerWake(ctx, EventWake{}))
+	assert.DeepEqual(t, []string{"exit stopped", "enter playing"}, *log)
+}
+
+func TestExitParallelState(t *testing.T) {
+	ctx := context.Background()
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerPowerOn(ctx, EventPowerOn{}))
+	*log = nil
+	assert.NilError(t, machine.TriggerPowerOff(ctx, EventPowerOff{}))
+	assert.Equal(t, DeviceStateOff, machine.CurrentState)
+	assert.DeepEqual(t, []DeviceState{DeviceStateOff}, machine.Configuration())
+	assert.DeepEqual(t, []string{
+		"exit stopped", "exit playback", "exit offline", "exit network", "exit on",
+	}, *log)
+}
+
+func TestEnterRegionDescendant(t *testing.T) {
+	machine, log := newMachine()
+	assert.NilError(t, machine.TriggerResume(context.Background(), EventResume{}))
+	assert.DeepEqual(t, []DeviceState{
+		DeviceStateOn, DeviceStateNetwork, DeviceStateOffline, DeviceStatePlayback, DeviceStatePlaying,
+	}, machine.Configuration())
+	assert.DeepEqual(t, []string{
+		"enter on", "enter network", "enter offline", "enter playback", "enter playing",
+	}, *log)
+}
@@ -0,0 +1,35 @@
+package parallel
+
+//go:generate go run gen/gen.go
+type State struct {
+	Charged bool
+}
+
+// Environment records the order entry and exit handlers are called in.
+type Environment struct {
+	Log *[]string
+}
+
+func (env Environment) Record(entry string) {
+	*env.Log = append(*env.Log, entry)
+}
+
+// The on state is a parallel state with independent network and playback regions.
+const (
+	StateOff      = "off"
+	StateOn       = "on"
+	StateNetwork  = "network"
+	StateOffline  = "offline"
+	StateOnline   = "online"
+	StatePlayback = "playback"
+	StateStopped  = "stopped"
+	StatePlaying  = "playing"
+)
+
+type EventPowerOn struct{}
+type EventPowerOff struct{}
+type EventConnect struct{}
+type EventPlay struct{}
+type EventSuspend struct{}
+type EventResume struct{}
+type EventWake struct{}
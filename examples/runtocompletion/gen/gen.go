@@ -0,0 +1,23 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/runtocompletion"
+)
+
+func main() {
+	gen := fsmgen.New("ping_pong", runtocompletion.State{}, runtocompletion.Environment{}, runtocompletion.StateIdle, runtocompletion.StatePing, runtocompletion.StatePong)
+	gen.PackageName = "runtocompletion"
+	gen.MaxChainLength = 10
+	gen.AddEvent(fsmgen.NewEvent("ping", runtocompletion.EventPing{}).From(runtocompletion.StateIdle, runtocompletion.StatePong).To(runtocompletion.StatePing))
+	gen.AddEvent(fsmgen.NewEvent("pong", runtocompletion.EventPong{}).From(runtocompletion.StatePing).To(runtocompletion.StatePong))
+	gen.AddEvent(fsmgen.NewEvent("stop", runtocompletion.EventStop{}).FromAny().To(runtocompletion.StateIdle))
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
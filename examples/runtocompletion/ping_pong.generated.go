@@ -0,0 +1,389 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package runtocompletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PingPongState is a state the PingPongMachine may be in.
+type PingPongState string
+
+const (
+	PingPongStateIdle PingPongState = "idle"
+	PingPongStatePing PingPongState = "ping"
+	PingPongStatePong PingPongState = "pong"
+)
+
+// String returns the name of the state.
+func (state PingPongState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PingPongState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePingPongState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PingPongState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePingPongState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePingPongState returns the PingPongState with the supplied name.
+func ParsePingPongState(str string) (PingPongState, error) {
+	switch PingPongState(str) {
+	case PingPongStateIdle, PingPongStatePing, PingPongStatePong:
+		return PingPongState(str), nil
+	}
+	return "", errors.New("unknown ping_pong state: " + str)
+}
+
+// PingPongEvent is an event that may be triggered on the PingPongMachine.
+type PingPongEvent string
+
+const (
+	PingPongEventPing PingPongEvent = "ping"
+	PingPongEventPong PingPongEvent = "pong"
+	PingPongEventStop PingPongEvent = "stop"
+)
+
+// String returns the name of the event.
+func (event PingPongEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PingPongEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePingPongEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PingPongEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePingPongEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePingPongEvent returns the PingPongEvent with the supplied name.
+func ParsePingPongEvent(str string) (PingPongEvent, error) {
+	switch PingPongEvent(str) {
+	case PingPongEventPing, PingPongEventPong, PingPongEventStop:
+		return PingPongEvent(str), nil
+	}
+	return "", errors.New("unknown ping_pong event: " + str)
+}
+
+// PingPongMachine is the ping_pong state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PingPongMachine struct {
+	*fsmruntime.Machine[PingPongState, PingPongEvent, State]
+
+	env Environment
+
+	PingAction func(ctx PingPongMachineContext, state *State, ev EventPing) error
+	PongAction func(ctx PingPongMachineContext, state *State, ev EventPong) error
+	StopAction func(ctx PingPongMachineContext, state *State, ev EventStop) error
+
+	OnStateIdle func(ctx PingPongMachineContext, env Environment, state State) error
+	OnStatePing func(ctx PingPongMachineContext, env Environment, state State) error
+	OnStatePong func(ctx PingPongMachineContext, env Environment, state State) error
+
+	OnExitIdle func(ctx PingPongMachineContext, env Environment, state State) error
+	OnExitPing func(ctx PingPongMachineContext, env Environment, state State) error
+	OnExitPong func(ctx PingPongMachineContext, env Environment, state State) error
+}
+
+// PingPongTransition describes a transition of the PingPongMachine.
+type PingPongTransition = fsmruntime.Transition[PingPongState, PingPongEvent]
+
+// PingPongSnapshot is a serializable copy of the PingPongMachine's state, which
+// Restore resumes from.
+type PingPongSnapshot = fsmruntime.Snapshot[PingPongState, PingPongEvent, State]
+
+// PingPongTransitionRule describes a transition declared by the PingPongMachine's definition.
+type PingPongTransitionRule = fsmruntime.TransitionRule[PingPongState, PingPongEvent]
+
+// PingPongListener observes every event processed by a PingPongMachine.
+type PingPongListener = fsmruntime.Listener[PingPongState, PingPongEvent]
+
+// PingPongBaseListener implements PingPongListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PingPongBaseListener = fsmruntime.BaseListener[PingPongState, PingPongEvent]
+
+// PingPongMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PingPongMachineContext interface {
+	Context() context.Context
+	TriggerPing(ev EventPing) error
+	TriggerPong(ev EventPong) error
+	TriggerStop(ev EventStop) error
+}
+
+type pingPongMachineContext struct {
+	ctx     context.Context
+	machine *PingPongMachine
+}
+
+func newPingPongContext(ctx context.Context, machine *PingPongMachine) PingPongMachineContext {
+	return &pingPongMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx pingPongMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPing queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx pingPongMachineContext) TriggerPing(ev EventPing) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPing(ctx.ctx, ev)
+	})
+}
+
+// TriggerPong queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx pingPongMachineContext) TriggerPong(ev EventPong) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPong(ctx.ctx, ev)
+	})
+}
+
+// TriggerStop queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx pingPongMachineContext) TriggerStop(ev EventStop) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerStop(ctx.ctx, ev)
+	})
+}
+
+// pingPongDefinition is the structure of the PingPongMachine, shared by every instance.
+var pingPongDefinition = &fsmruntime.Definition[PingPongState, PingPongEvent]{
+	Name:   "ping_pong",
+	States: []PingPongState{PingPongStateIdle, PingPongStatePing, PingPongStatePong},
+	Events: []PingPongEvent{PingPongEventPing, PingPongEventPong, PingPongEventStop},
+	Transitions: map[PingPongState]map[PingPongEvent]PingPongState{
+		"": {
+			PingPongEventStop: PingPongStateIdle,
+		},
+		PingPongStateIdle: {
+			PingPongEventPing: PingPongStatePing,
+		},
+		PingPongStatePing: {
+			PingPongEventPong: PingPongStatePong,
+		},
+		PingPongStatePong: {
+			PingPongEventPing: PingPongStatePing,
+		},
+	},
+	Parents: map[PingPongState]PingPongState{},
+	Initial: map[PingPongState]PingPongState{},
+	Regions: map[PingPongState][]PingPongState{},
+	Order: map[PingPongState]int{
+		PingPongStateIdle: 0,
+		PingPongStatePing: 1,
+		PingPongStatePong: 2,
+	},
+	Rules: []PingPongTransitionRule{
+		{From: PingPongStateIdle, Event: PingPongEventPing, To: PingPongStatePing},
+		{From: PingPongStatePong, Event: PingPongEventPing, To: PingPongStatePing},
+		{From: PingPongStatePing, Event: PingPongEventPong, To: PingPongStatePong},
+		{From: "", Event: PingPongEventStop, To: PingPongStateIdle},
+	},
+	MaxChainLength: 10,
+}
+
+// NewPingPongMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPingPongMachine(state *State, env Environment) *PingPongMachine {
+	machine := &PingPongMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(pingPongDefinition, state, fsmruntime.Handlers[PingPongState, PingPongEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPingPongMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPingPongMachineFromSnapshot(snapshot PingPongSnapshot, env Environment) (*PingPongMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPingPongMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PingPongMachine) eventFunc(event PingPongEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PingPongEventPing:
+		ev, ok := payload.(EventPing)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPing, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPing(ctx, ev)
+		}, nil
+	case PingPongEventPong:
+		ev, ok := payload.(EventPong)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPong, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPong(ctx, ev)
+		}, nil
+	case PingPongEventStop:
+		ev, ok := payload.(EventStop)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventStop, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerStop(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *PingPongMachine) exitState(ctx context.Context, transition PingPongTransition, state PingPongState) error {
+	switch state {
+	case PingPongStateIdle:
+		if machine.OnExitIdle == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitIdle", transition, machine.OnExitIdle(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	case PingPongStatePing:
+		if machine.OnExitPing == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPing", transition, machine.OnExitPing(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	case PingPongStatePong:
+		if machine.OnExitPong == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPong", transition, machine.OnExitPong(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PingPongMachine) enterState(ctx context.Context, transition PingPongTransition, state PingPongState) error {
+	switch state {
+	case PingPongStateIdle:
+		if machine.OnStateIdle == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateIdle", transition, machine.OnStateIdle(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	case PingPongStatePing:
+		if machine.OnStatePing == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePing", transition, machine.OnStatePing(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	case PingPongStatePong:
+		if machine.OnStatePong == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePong", transition, machine.OnStatePong(newPingPongContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPing triggers the ping event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PingPongMachine) TriggerPing(ctx context.Context, ev EventPing) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPing(ctx, ev)
+	})
+}
+
+func (machine *PingPongMachine) triggerPing(ctx context.Context, ev EventPing) error {
+	return machine.Fire(ctx, PingPongEventPing, ev, nil, func(ctx context.Context, transition PingPongTransition) error {
+		if machine.PingAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PingAction", transition, machine.PingAction(newPingPongContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerPong triggers the pong event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PingPongMachine) TriggerPong(ctx context.Context, ev EventPong) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPong(ctx, ev)
+	})
+}
+
+func (machine *PingPongMachine) triggerPong(ctx context.Context, ev EventPong) error {
+	return machine.Fire(ctx, PingPongEventPong, ev, nil, func(ctx context.Context, transition PingPongTransition) error {
+		if machine.PongAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PongAction", transition, machine.PongAction(newPingPongContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerStop triggers the stop event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PingPongMachine) TriggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerStop(ctx, ev)
+	})
+}
+
+func (machine *PingPongMachine) triggerStop(ctx context.Context, ev EventStop) error {
+	return machine.Fire(ctx, PingPongEventStop, ev, nil, func(ctx context.Context, transition PingPongTransition) error {
+		if machine.StopAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("StopAction", transition, machine.StopAction(newPingPongContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,80 @@
+package runtocompletion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func TestHandlersRunToCompletion(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPingPongMachine(&State{}, Environment{})
+	machine.OnStatePing = func(ctx PingPongMachineContext, env Environment, state State) error {
+		machine.State.Log = append(machine.State.Log, "enter ping")
+		err := ctx.TriggerPong(EventPong{})
+		machine.State.Log = append(machine.State.Log, "queued pong")
+		return err
+	}
+	machine.OnStatePong = func(ctx PingPongMachineContext, env Environment, state State) error {
+		machine.State.Log = append(machine.State.Log, "enter pong")
+		return ctx.TriggerStop(EventStop{})
+	}
+	machine.StopAction = func(ctx PingPongMachineContext, state *State, ev EventStop) error {
+		state.Log = append(state.Log, "stop")
+		return nil
+	}
+
+	assert.NilError(t, machine.TriggerPing(ctx, EventPing{}))
+	assert.Equal(t, PingPongStateIdle, machine.CurrentState)
+	assert.DeepEqual(t, []string{"enter ping", "queued pong", "enter pong", "stop"}, machine.State.Log)
+}
+
+func TestQueuedEventErrorsReturnedByOutermostTrigger(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPingPongMachine(&State{}, Environment{})
+	machine.OnStatePing = func(ctx PingPongMachineContext, env Environment, state State) error {
+		// Ping is not valid from ping, so the queued event is rejected.
+		return ctx.TriggerPing(EventPing{})
+	}
+	err := machine.TriggerPing(ctx, EventPing{})
+	assert.Error(t, err, "invalid transition: no transition target from ping via ping")
+	assert.Equal(t, PingPongStatePing, machine.CurrentState)
+
+	// The queue is discarded, so later triggers are processed normally.
+	machine.OnStatePing = nil
+	assert.NilError(t, machine.TriggerStop(ctx, EventStop{}))
+	assert.Equal(t, PingPongStateIdle, machine.CurrentState)
+}
+
+func TestMaxChainLength(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPingPongMachine(&State{}, Environment{})
+	assert.Equal(t, 10, machine.MaxChainLength)
+	pongs := 0
+	machine.OnStatePing = func(ctx PingPongMachineContext, env Environment, state State) error {
+		return ctx.TriggerPong(EventPong{})
+	}
+	machine.OnStatePong = func(ctx PingPongMachineContext, env Environment, state State) error {
+		pongs++
+		return ctx.TriggerPing(EventPing{})
+	}
+	err := machine.TriggerPing(ctx, EventPing{})
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrMaxChainLength))
+	assert.Equal(t, 5, pongs)
+
+	machine.MaxChainLength = 0
+	machine.OnStatePong = func(ctx PingPongMachineContext, env Environment, state State) error {
+		pongs++
+		if pongs == 1000 {
+			return ctx.TriggerStop(EventStop{})
+		}
+		return ctx.TriggerPing(EventPing{})
+	}
+	assert.NilError(t, machine.TriggerStop(ctx, EventStop{}))
+	assert.NilError(t, machine.TriggerPing(ctx, EventPing{}))
+	assert.Equal(t, 1000, pongs)
+	assert.Equal(t, PingPongStateIdle, machine.CurrentState)
+}
@@ -0,0 +1,18 @@
+package runtocompletion
+
+//go:generate go run gen/gen.go
+type State struct {
+	Log []string
+}
+
+type Environment struct{}
+
+const (
+	StateIdle = "idle"
+	StatePing = "ping"
+	StatePong = "pong"
+)
+
+type EventPing struct{}
+type EventPong struct{}
+type EventStop struct{}
@@ -0,0 +1,28 @@
+package specfile
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestPlayer(t *testing.T) {
+	ctx := context.Background()
+	machine := NewPlayerMachine(&Player{}, Environment{})
+	machine.LoadAction = func(ctx PlayerMachineContext, state *Player, ev EventLoad) error {
+		state.File = ev.File
+		return nil
+	}
+	machine.OnStateLoading = func(ctx PlayerMachineContext, env Environment, state Player) error {
+		return ctx.TriggerPlay(EventPlay{})
+	}
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{File: "song.mp3"}))
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+	assert.Equal(t, "song.mp3", machine.State.File)
+
+	assert.NilError(t, machine.TriggerPause(ctx, EventPause{}))
+	assert.ErrorContains(t, machine.TriggerPause(ctx, EventPause{}), "no transition target from paused via pause")
+	assert.NilError(t, machine.TriggerError(ctx, EventError{}))
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+}
@@ -0,0 +1,19 @@
+package specfile
+
+//go:generate go run github.com/snikch/go-fsmgen/cmd/fsmgen -spec player.yaml
+
+type Player struct {
+	File    string
+	Message string
+}
+
+type Environment struct{}
+
+type EventLoad struct {
+	File string
+}
+type EventPlay struct{}
+type EventPause struct{}
+type EventError struct {
+	Message string
+}
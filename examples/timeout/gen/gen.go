@@ -0,0 +1,24 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/timeout"
+)
+
+func main() {
+	gen := fsmgen.New("player", timeout.State{}, timeout.Environment{}, timeout.StateInit, timeout.StateLoading, timeout.StatePlaying)
+	gen.PackageName = "timeout"
+	gen.AddEvent(fsmgen.NewEvent("load", timeout.EventLoad{}).From(timeout.StateInit, timeout.StateLoading).To(timeout.StateLoading))
+	gen.AddEvent(fsmgen.NewEvent("loaded", timeout.EventLoaded{}).From(timeout.StateLoading).To(timeout.StatePlaying))
+	gen.AddEvent(fsmgen.NewEvent("timeout", timeout.EventTimeout{}).From(timeout.StateLoading).To(timeout.StateInit).After(30 * time.Second))
+	gen.MermaidFilename = "player.mmd"
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
@@ -0,0 +1,407 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// PlayerState is a state the PlayerMachine may be in.
+type PlayerState string
+
+const (
+	PlayerStateInit    PlayerState = "init"
+	PlayerStateLoading PlayerState = "loading"
+	PlayerStatePlaying PlayerState = "playing"
+)
+
+// String returns the name of the state.
+func (state PlayerState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state PlayerState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *PlayerState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParsePlayerState returns the PlayerState with the supplied name.
+func ParsePlayerState(str string) (PlayerState, error) {
+	switch PlayerState(str) {
+	case PlayerStateInit, PlayerStateLoading, PlayerStatePlaying:
+		return PlayerState(str), nil
+	}
+	return "", errors.New("unknown player state: " + str)
+}
+
+// PlayerEvent is an event that may be triggered on the PlayerMachine.
+type PlayerEvent string
+
+const (
+	PlayerEventLoad    PlayerEvent = "load"
+	PlayerEventLoaded  PlayerEvent = "loaded"
+	PlayerEventTimeout PlayerEvent = "timeout"
+)
+
+// String returns the name of the event.
+func (event PlayerEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event PlayerEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParsePlayerEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *PlayerEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParsePlayerEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParsePlayerEvent returns the PlayerEvent with the supplied name.
+func ParsePlayerEvent(str string) (PlayerEvent, error) {
+	switch PlayerEvent(str) {
+	case PlayerEventLoad, PlayerEventLoaded, PlayerEventTimeout:
+		return PlayerEvent(str), nil
+	}
+	return "", errors.New("unknown player event: " + str)
+}
+
+// PlayerMachine is the player state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type PlayerMachine struct {
+	*fsmruntime.Machine[PlayerState, PlayerEvent, State]
+
+	env Environment
+
+	LoadAction    func(ctx PlayerMachineContext, state *State, ev EventLoad) error
+	LoadedAction  func(ctx PlayerMachineContext, state *State, ev EventLoaded) error
+	TimeoutAction func(ctx PlayerMachineContext, state *State, ev EventTimeout) error
+
+	OnStateInit    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStateLoading func(ctx PlayerMachineContext, env Environment, state State) error
+	OnStatePlaying func(ctx PlayerMachineContext, env Environment, state State) error
+
+	OnExitInit    func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitLoading func(ctx PlayerMachineContext, env Environment, state State) error
+	OnExitPlaying func(ctx PlayerMachineContext, env Environment, state State) error
+}
+
+// PlayerTransition describes a transition of the PlayerMachine.
+type PlayerTransition = fsmruntime.Transition[PlayerState, PlayerEvent]
+
+// PlayerSnapshot is a serializable copy of the PlayerMachine's state, which
+// Restore resumes from.
+type PlayerSnapshot = fsmruntime.Snapshot[PlayerState, PlayerEvent, State]
+
+// PlayerTimerSnapshot is a pending delayed transition of a PlayerSnapshot.
+type PlayerTimerSnapshot = fsmruntime.TimerSnapshot[PlayerState, PlayerEvent]
+
+// PlayerTransitionRule describes a transition declared by the PlayerMachine's definition.
+type PlayerTransitionRule = fsmruntime.TransitionRule[PlayerState, PlayerEvent]
+
+// PlayerListener observes every event processed by a PlayerMachine.
+type PlayerListener = fsmruntime.Listener[PlayerState, PlayerEvent]
+
+// PlayerBaseListener implements PlayerListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type PlayerBaseListener = fsmruntime.BaseListener[PlayerState, PlayerEvent]
+
+// PlayerMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type PlayerMachineContext interface {
+	Context() context.Context
+	TriggerLoad(ev EventLoad) error
+	TriggerLoaded(ev EventLoaded) error
+	TriggerTimeout(ev EventTimeout) error
+}
+
+type playerMachineContext struct {
+	ctx     context.Context
+	machine *PlayerMachine
+}
+
+func newPlayerContext(ctx context.Context, machine *PlayerMachine) PlayerMachineContext {
+	return &playerMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx playerMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerLoad queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoad(ev EventLoad) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoad(ctx.ctx, ev)
+	})
+}
+
+// TriggerLoaded queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerLoaded(ev EventLoaded) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerLoaded(ctx.ctx, ev)
+	})
+}
+
+// TriggerTimeout queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx playerMachineContext) TriggerTimeout(ev EventTimeout) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerTimeout(ctx.ctx, ev)
+	})
+}
+
+// playerDefinition is the structure of the PlayerMachine, shared by every instance.
+var playerDefinition = &fsmruntime.Definition[PlayerState, PlayerEvent]{
+	Name:   "player",
+	States: []PlayerState{PlayerStateInit, PlayerStateLoading, PlayerStatePlaying},
+	Events: []PlayerEvent{PlayerEventLoad, PlayerEventLoaded, PlayerEventTimeout},
+	Transitions: map[PlayerState]map[PlayerEvent]PlayerState{
+		"": {},
+		PlayerStateInit: {
+			PlayerEventLoad: PlayerStateLoading,
+		},
+		PlayerStateLoading: {
+			PlayerEventLoad:    PlayerStateLoading,
+			PlayerEventLoaded:  PlayerStatePlaying,
+			PlayerEventTimeout: PlayerStateInit,
+		},
+		PlayerStatePlaying: {},
+	},
+	Parents: map[PlayerState]PlayerState{},
+	Initial: map[PlayerState]PlayerState{},
+	Regions: map[PlayerState][]PlayerState{},
+	Order: map[PlayerState]int{
+		PlayerStateInit:    0,
+		PlayerStateLoading: 1,
+		PlayerStatePlaying: 2,
+	},
+	Delayed: map[PlayerState][]fsmruntime.Delay[PlayerEvent]{
+		PlayerStateLoading: {
+			{Event: PlayerEventTimeout, After: 30 * time.Second},
+		},
+	},
+	Rules: []PlayerTransitionRule{
+		{From: PlayerStateInit, Event: PlayerEventLoad, To: PlayerStateLoading},
+		{From: PlayerStateLoading, Event: PlayerEventLoad, To: PlayerStateLoading},
+		{From: PlayerStateLoading, Event: PlayerEventLoaded, To: PlayerStatePlaying},
+		{From: PlayerStateLoading, Event: PlayerEventTimeout, To: PlayerStateInit},
+	},
+	MaxChainLength: 100,
+}
+
+// NewPlayerMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewPlayerMachine(state *State, env Environment) *PlayerMachine {
+	machine := &PlayerMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(playerDefinition, state, fsmruntime.Handlers[PlayerState, PlayerEvent]{
+		Enter:     machine.enterState,
+		Exit:      machine.exitState,
+		Event:     machine.eventFunc,
+		Automatic: machine.triggerAutomatic,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewPlayerMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewPlayerMachineFromSnapshot(snapshot PlayerSnapshot, env Environment) (*PlayerMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(State)
+	}
+	machine := NewPlayerMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *PlayerMachine) eventFunc(event PlayerEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case PlayerEventLoad:
+		ev, ok := payload.(EventLoad)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoad, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoad(ctx, ev)
+		}, nil
+	case PlayerEventLoaded:
+		ev, ok := payload.(EventLoaded)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventLoaded, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerLoaded(ctx, ev)
+		}, nil
+	case PlayerEventTimeout:
+		ev, ok := payload.(EventTimeout)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventTimeout, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerTimeout(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+// triggerAutomatic processes the supplied delayed or completion event with a zero value event object.
+func (machine *PlayerMachine) triggerAutomatic(ctx context.Context, event PlayerEvent) error {
+	switch event {
+	case PlayerEventTimeout:
+		var ev EventTimeout
+		return machine.triggerTimeout(ctx, ev)
+	}
+	return fmt.Errorf("unknown automatic event %s", event)
+}
+
+func (machine *PlayerMachine) exitState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInit:
+		if machine.OnExitInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitInit", transition, machine.OnExitInit(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnExitLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitLoading", transition, machine.OnExitLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnExitPlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPlaying", transition, machine.OnExitPlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *PlayerMachine) enterState(ctx context.Context, transition PlayerTransition, state PlayerState) error {
+	switch state {
+	case PlayerStateInit:
+		if machine.OnStateInit == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateInit", transition, machine.OnStateInit(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStateLoading:
+		if machine.OnStateLoading == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStateLoading", transition, machine.OnStateLoading(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	case PlayerStatePlaying:
+		if machine.OnStatePlaying == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePlaying", transition, machine.OnStatePlaying(newPlayerContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerLoad triggers the load event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoad(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoad(ctx context.Context, ev EventLoad) error {
+	return machine.Fire(ctx, PlayerEventLoad, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadAction", transition, machine.LoadAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerLoaded triggers the loaded event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerLoaded(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerLoaded(ctx context.Context, ev EventLoaded) error {
+	return machine.Fire(ctx, PlayerEventLoaded, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.LoadedAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("LoadedAction", transition, machine.LoadedAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
+
+// TriggerTimeout triggers the timeout event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *PlayerMachine) TriggerTimeout(ctx context.Context, ev EventTimeout) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerTimeout(ctx, ev)
+	})
+}
+
+func (machine *PlayerMachine) triggerTimeout(ctx context.Context, ev EventTimeout) error {
+	return machine.Fire(ctx, PlayerEventTimeout, ev, nil, func(ctx context.Context, transition PlayerTransition) error {
+		if machine.TimeoutAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("TimeoutAction", transition, machine.TimeoutAction(newPlayerContext(ctx, machine), machine.State, ev))
+	})
+}
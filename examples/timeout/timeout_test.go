@@ -0,0 +1,86 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+func newMachine() (*PlayerMachine, *runtime.FakeClock) {
+	clock := runtime.NewFakeClock(time.Date(2020, 11, 13, 6, 35, 0, 0, time.UTC))
+	return NewPlayerMachine(&State{}, Environment{FakeClock: clock}), clock
+}
+
+func TestDelayedTransition(t *testing.T) {
+	machine, clock := newMachine()
+	assert.NilError(t, machine.TriggerLoad(context.Background(), EventLoad{}))
+	clock.Advance(29 * time.Second)
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+	clock.Advance(time.Second)
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+	assert.Equal(t, 0, clock.PendingTimers())
+}
+
+func TestDelayedTransitionCancelledOnExit(t *testing.T) {
+	ctx := context.Background()
+	machine, clock := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	assert.NilError(t, machine.TriggerLoaded(ctx, EventLoaded{}))
+	assert.Equal(t, 0, clock.PendingTimers())
+	clock.Advance(time.Minute)
+	assert.Equal(t, PlayerStatePlaying, machine.CurrentState)
+}
+
+func TestDelayedTransitionRestartsOnReentry(t *testing.T) {
+	ctx := context.Background()
+	machine, clock := newMachine()
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	clock.Advance(20 * time.Second)
+	assert.NilError(t, machine.TriggerLoad(ctx, EventLoad{}))
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, PlayerStateInit, machine.CurrentState)
+}
+
+func TestDelayedTransitionError(t *testing.T) {
+	machine, clock := newMachine()
+	failure := errors.New("timeout failed")
+	machine.TimeoutAction = func(ctx PlayerMachineContext, state *State, ev EventTimeout) error {
+		return failure
+	}
+	var timerErr error
+	machine.TimerError = func(event PlayerEvent, err error) {
+		assert.Equal(t, PlayerEventTimeout, event)
+		timerErr = err
+	}
+	assert.NilError(t, machine.TriggerLoad(context.Background(), EventLoad{}))
+	clock.Advance(30 * time.Second)
+	assert.Assert(t, errors.Is(timerErr, failure))
+	assert.Equal(t, PlayerStateLoading, machine.CurrentState)
+}
+
+func TestSnapshotRestoresTimers(t *testing.T) {
+	machine, clock := newMachine()
+	assert.NilError(t, machine.TriggerLoad(context.Background(), EventLoad{}))
+	clock.Advance(20 * time.Second)
+	snapshot := machine.Snapshot()
+	assert.DeepEqual(t, []PlayerTimerSnapshot{
+		{State: PlayerStateLoading, Event: PlayerEventTimeout, Deadline: clock.Now().Add(10 * time.Second)},
+	}, snapshot.Timers)
+
+	restoredClock := runtime.NewFakeClock(clock.Now())
+	restored, err := NewPlayerMachineFromSnapshot(snapshot, Environment{FakeClock: restoredClock})
+	assert.NilError(t, err)
+	assert.Equal(t, PlayerStateLoading, restored.CurrentState)
+	restoredClock.Advance(10 * time.Second)
+	assert.Equal(t, PlayerStateInit, restored.CurrentState)
+
+	snapshot.Timers[0].State = PlayerStatePlaying
+	_, err = NewPlayerMachineFromSnapshot(snapshot, Environment{FakeClock: restoredClock})
+	assert.Error(t, err, "player snapshot has a timer for inactive state playing")
+}
@@ -0,0 +1,27 @@
+package timeout
+
+import (
+	"github.com/snikch/go-fsmgen/runtime"
+)
+
+//go:generate go run gen/gen.go
+type State struct{}
+
+// Environment supplies the clock used to schedule delayed transitions, so tests can inject a fake clock.
+type Environment struct {
+	FakeClock *runtime.FakeClock
+}
+
+func (env Environment) Clock() runtime.Clock {
+	return env.FakeClock
+}
+
+const (
+	StateInit    = "init"
+	StateLoading = "loading"
+	StatePlaying = "playing"
+)
+
+type EventLoad struct{}
+type EventLoaded struct{}
+type EventTimeout struct{}
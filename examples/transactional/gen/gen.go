@@ -0,0 +1,29 @@
+//go:build ignore
+
+package main
+
+import (
+	"log"
+
+	"github.com/snikch/go-fsmgen"
+	"github.com/snikch/go-fsmgen/examples/transactional"
+)
+
+func main() {
+	gen := fsmgen.New("order", transactional.Order{}, transactional.Environment{}, transactional.StatePending, transactional.StatePaid)
+	gen.PackageName = "transactional"
+	gen.AddEvent(fsmgen.NewEvent("pay", transactional.EventPay{}).From(transactional.StatePending).To(transactional.StatePaid))
+	err := gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// The same machine, committing the target state before the action runs.
+	gen.Name = "legacy_order"
+	gen.Filename = "legacy_order.generated.go"
+	gen.CommitBeforeAction = true
+	err = gen.Write()
+	if err != nil {
+		log.Panic(err)
+	}
+}
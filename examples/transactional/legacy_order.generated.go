@@ -0,0 +1,294 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package transactional
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// LegacyOrderState is a state the LegacyOrderMachine may be in.
+type LegacyOrderState string
+
+const (
+	LegacyOrderStatePending LegacyOrderState = "pending"
+	LegacyOrderStatePaid    LegacyOrderState = "paid"
+)
+
+// String returns the name of the state.
+func (state LegacyOrderState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state LegacyOrderState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseLegacyOrderState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *LegacyOrderState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseLegacyOrderState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseLegacyOrderState returns the LegacyOrderState with the supplied name.
+func ParseLegacyOrderState(str string) (LegacyOrderState, error) {
+	switch LegacyOrderState(str) {
+	case LegacyOrderStatePending, LegacyOrderStatePaid:
+		return LegacyOrderState(str), nil
+	}
+	return "", errors.New("unknown legacy_order state: " + str)
+}
+
+// LegacyOrderEvent is an event that may be triggered on the LegacyOrderMachine.
+type LegacyOrderEvent string
+
+const (
+	LegacyOrderEventPay LegacyOrderEvent = "pay"
+)
+
+// String returns the name of the event.
+func (event LegacyOrderEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event LegacyOrderEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseLegacyOrderEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *LegacyOrderEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseLegacyOrderEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseLegacyOrderEvent returns the LegacyOrderEvent with the supplied name.
+func ParseLegacyOrderEvent(str string) (LegacyOrderEvent, error) {
+	switch LegacyOrderEvent(str) {
+	case LegacyOrderEventPay:
+		return LegacyOrderEvent(str), nil
+	}
+	return "", errors.New("unknown legacy_order event: " + str)
+}
+
+// LegacyOrderMachine is the legacy_order state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type LegacyOrderMachine struct {
+	*fsmruntime.Machine[LegacyOrderState, LegacyOrderEvent, Order]
+
+	env Environment
+
+	PayAction func(ctx LegacyOrderMachineContext, state *Order, ev EventPay) error
+
+	OnStatePending func(ctx LegacyOrderMachineContext, env Environment, state Order) error
+	OnStatePaid    func(ctx LegacyOrderMachineContext, env Environment, state Order) error
+
+	OnExitPending func(ctx LegacyOrderMachineContext, env Environment, state Order) error
+	OnExitPaid    func(ctx LegacyOrderMachineContext, env Environment, state Order) error
+}
+
+// LegacyOrderTransition describes a transition of the LegacyOrderMachine.
+type LegacyOrderTransition = fsmruntime.Transition[LegacyOrderState, LegacyOrderEvent]
+
+// LegacyOrderSnapshot is a serializable copy of the LegacyOrderMachine's state, which
+// Restore resumes from.
+type LegacyOrderSnapshot = fsmruntime.Snapshot[LegacyOrderState, LegacyOrderEvent, Order]
+
+// LegacyOrderTransitionRule describes a transition declared by the LegacyOrderMachine's definition.
+type LegacyOrderTransitionRule = fsmruntime.TransitionRule[LegacyOrderState, LegacyOrderEvent]
+
+// LegacyOrderListener observes every event processed by a LegacyOrderMachine.
+type LegacyOrderListener = fsmruntime.Listener[LegacyOrderState, LegacyOrderEvent]
+
+// LegacyOrderBaseListener implements LegacyOrderListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type LegacyOrderBaseListener = fsmruntime.BaseListener[LegacyOrderState, LegacyOrderEvent]
+
+// LegacyOrderMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type LegacyOrderMachineContext interface {
+	Context() context.Context
+	TriggerPay(ev EventPay) error
+}
+
+type legacyOrderMachineContext struct {
+	ctx     context.Context
+	machine *LegacyOrderMachine
+}
+
+func newLegacyOrderContext(ctx context.Context, machine *LegacyOrderMachine) LegacyOrderMachineContext {
+	return &legacyOrderMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx legacyOrderMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx legacyOrderMachineContext) TriggerPay(ev EventPay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPay(ctx.ctx, ev)
+	})
+}
+
+// legacyOrderDefinition is the structure of the LegacyOrderMachine, shared by every instance.
+var legacyOrderDefinition = &fsmruntime.Definition[LegacyOrderState, LegacyOrderEvent]{
+	Name:   "legacy_order",
+	States: []LegacyOrderState{LegacyOrderStatePending, LegacyOrderStatePaid},
+	Events: []LegacyOrderEvent{LegacyOrderEventPay},
+	Transitions: map[LegacyOrderState]map[LegacyOrderEvent]LegacyOrderState{
+		"":                   {},
+		LegacyOrderStatePaid: {},
+		LegacyOrderStatePending: {
+			LegacyOrderEventPay: LegacyOrderStatePaid,
+		},
+	},
+	Parents: map[LegacyOrderState]LegacyOrderState{},
+	Initial: map[LegacyOrderState]LegacyOrderState{},
+	Regions: map[LegacyOrderState][]LegacyOrderState{},
+	Order: map[LegacyOrderState]int{
+		LegacyOrderStatePending: 0,
+		LegacyOrderStatePaid:    1,
+	},
+	Rules: []LegacyOrderTransitionRule{
+		{From: LegacyOrderStatePending, Event: LegacyOrderEventPay, To: LegacyOrderStatePaid},
+	},
+	MaxChainLength:     100,
+	CommitBeforeAction: true,
+}
+
+// NewLegacyOrderMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewLegacyOrderMachine(state *Order, env Environment) *LegacyOrderMachine {
+	machine := &LegacyOrderMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(legacyOrderDefinition, state, fsmruntime.Handlers[LegacyOrderState, LegacyOrderEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewLegacyOrderMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewLegacyOrderMachineFromSnapshot(snapshot LegacyOrderSnapshot, env Environment) (*LegacyOrderMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(Order)
+	}
+	machine := NewLegacyOrderMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *LegacyOrderMachine) eventFunc(event LegacyOrderEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case LegacyOrderEventPay:
+		ev, ok := payload.(EventPay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPay(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *LegacyOrderMachine) exitState(ctx context.Context, transition LegacyOrderTransition, state LegacyOrderState) error {
+	switch state {
+	case LegacyOrderStatePending:
+		if machine.OnExitPending == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPending", transition, machine.OnExitPending(newLegacyOrderContext(ctx, machine), machine.env, *machine.State))
+	case LegacyOrderStatePaid:
+		if machine.OnExitPaid == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaid", transition, machine.OnExitPaid(newLegacyOrderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *LegacyOrderMachine) enterState(ctx context.Context, transition LegacyOrderTransition, state LegacyOrderState) error {
+	switch state {
+	case LegacyOrderStatePending:
+		if machine.OnStatePending == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePending", transition, machine.OnStatePending(newLegacyOrderContext(ctx, machine), machine.env, *machine.State))
+	case LegacyOrderStatePaid:
+		if machine.OnStatePaid == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaid", transition, machine.OnStatePaid(newLegacyOrderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPay triggers the pay event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *LegacyOrderMachine) TriggerPay(ctx context.Context, ev EventPay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPay(ctx, ev)
+	})
+}
+
+func (machine *LegacyOrderMachine) triggerPay(ctx context.Context, ev EventPay) error {
+	return machine.Fire(ctx, LegacyOrderEventPay, ev, nil, func(ctx context.Context, transition LegacyOrderTransition) error {
+		if machine.PayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PayAction", transition, machine.PayAction(newLegacyOrderContext(ctx, machine), machine.State, ev))
+	})
+}
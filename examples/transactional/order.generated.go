@@ -0,0 +1,293 @@
+// Code generated by go-fsmgen. DO NOT EDIT.
+
+package transactional
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// OrderState is a state the OrderMachine may be in.
+type OrderState string
+
+const (
+	OrderStatePending OrderState = "pending"
+	OrderStatePaid    OrderState = "paid"
+)
+
+// String returns the name of the state.
+func (state OrderState) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state OrderState) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseOrderState(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *OrderState) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := ParseOrderState(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// ParseOrderState returns the OrderState with the supplied name.
+func ParseOrderState(str string) (OrderState, error) {
+	switch OrderState(str) {
+	case OrderStatePending, OrderStatePaid:
+		return OrderState(str), nil
+	}
+	return "", errors.New("unknown order state: " + str)
+}
+
+// OrderEvent is an event that may be triggered on the OrderMachine.
+type OrderEvent string
+
+const (
+	OrderEventPay OrderEvent = "pay"
+)
+
+// String returns the name of the event.
+func (event OrderEvent) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event OrderEvent) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := ParseOrderEvent(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *OrderEvent) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := ParseOrderEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// ParseOrderEvent returns the OrderEvent with the supplied name.
+func ParseOrderEvent(str string) (OrderEvent, error) {
+	switch OrderEvent(str) {
+	case OrderEventPay:
+		return OrderEvent(str), nil
+	}
+	return "", errors.New("unknown order event: " + str)
+}
+
+// OrderMachine is the order state machine. The transition engine is provided by the embedded
+// fsmruntime.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type OrderMachine struct {
+	*fsmruntime.Machine[OrderState, OrderEvent, Order]
+
+	env Environment
+
+	PayAction func(ctx OrderMachineContext, state *Order, ev EventPay) error
+
+	OnStatePending func(ctx OrderMachineContext, env Environment, state Order) error
+	OnStatePaid    func(ctx OrderMachineContext, env Environment, state Order) error
+
+	OnExitPending func(ctx OrderMachineContext, env Environment, state Order) error
+	OnExitPaid    func(ctx OrderMachineContext, env Environment, state Order) error
+}
+
+// OrderTransition describes a transition of the OrderMachine.
+type OrderTransition = fsmruntime.Transition[OrderState, OrderEvent]
+
+// OrderSnapshot is a serializable copy of the OrderMachine's state, which
+// Restore resumes from.
+type OrderSnapshot = fsmruntime.Snapshot[OrderState, OrderEvent, Order]
+
+// OrderTransitionRule describes a transition declared by the OrderMachine's definition.
+type OrderTransitionRule = fsmruntime.TransitionRule[OrderState, OrderEvent]
+
+// OrderListener observes every event processed by a OrderMachine.
+type OrderListener = fsmruntime.Listener[OrderState, OrderEvent]
+
+// OrderBaseListener implements OrderListener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type OrderBaseListener = fsmruntime.BaseListener[OrderState, OrderEvent]
+
+// OrderMachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type OrderMachineContext interface {
+	Context() context.Context
+	TriggerPay(ev EventPay) error
+}
+
+type orderMachineContext struct {
+	ctx     context.Context
+	machine *OrderMachine
+}
+
+func newOrderContext(ctx context.Context, machine *OrderMachine) OrderMachineContext {
+	return &orderMachineContext{
+		ctx:     ctx,
+		machine: machine,
+	}
+}
+
+func (ctx orderMachineContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// TriggerPay queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx orderMachineContext) TriggerPay(ev EventPay) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.triggerPay(ctx.ctx, ev)
+	})
+}
+
+// orderDefinition is the structure of the OrderMachine, shared by every instance.
+var orderDefinition = &fsmruntime.Definition[OrderState, OrderEvent]{
+	Name:   "order",
+	States: []OrderState{OrderStatePending, OrderStatePaid},
+	Events: []OrderEvent{OrderEventPay},
+	Transitions: map[OrderState]map[OrderEvent]OrderState{
+		"":             {},
+		OrderStatePaid: {},
+		OrderStatePending: {
+			OrderEventPay: OrderStatePaid,
+		},
+	},
+	Parents: map[OrderState]OrderState{},
+	Initial: map[OrderState]OrderState{},
+	Regions: map[OrderState][]OrderState{},
+	Order: map[OrderState]int{
+		OrderStatePending: 0,
+		OrderStatePaid:    1,
+	},
+	Rules: []OrderTransitionRule{
+		{From: OrderStatePending, Event: OrderEventPay, To: OrderStatePaid},
+	},
+	MaxChainLength: 100,
+}
+
+// NewOrderMachine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements fsmruntime.ClockProvider, or the system clock otherwise.
+func NewOrderMachine(state *Order, env Environment) *OrderMachine {
+	machine := &OrderMachine{env: env}
+	machine.Machine = fsmruntime.NewMachine(orderDefinition, state, fsmruntime.Handlers[OrderState, OrderEvent]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+		Event: machine.eventFunc,
+	})
+	if provider, ok := interface{}(env).(fsmruntime.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// NewOrderMachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func NewOrderMachineFromSnapshot(snapshot OrderSnapshot, env Environment) (*OrderMachine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new(Order)
+	}
+	machine := NewOrderMachine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *OrderMachine) eventFunc(event OrderEvent, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	case OrderEventPay:
+		ev, ok := payload.(EventPay)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected EventPay, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.triggerPay(ctx, ev)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+
+func (machine *OrderMachine) exitState(ctx context.Context, transition OrderTransition, state OrderState) error {
+	switch state {
+	case OrderStatePending:
+		if machine.OnExitPending == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPending", transition, machine.OnExitPending(newOrderContext(ctx, machine), machine.env, *machine.State))
+	case OrderStatePaid:
+		if machine.OnExitPaid == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnExitPaid", transition, machine.OnExitPaid(newOrderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+func (machine *OrderMachine) enterState(ctx context.Context, transition OrderTransition, state OrderState) error {
+	switch state {
+	case OrderStatePending:
+		if machine.OnStatePending == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePending", transition, machine.OnStatePending(newOrderContext(ctx, machine), machine.env, *machine.State))
+	case OrderStatePaid:
+		if machine.OnStatePaid == nil {
+			break
+		}
+		return fsmruntime.WrapHandlerError("OnStatePaid", transition, machine.OnStatePaid(newOrderContext(ctx, machine), machine.env, *machine.State))
+	}
+	return nil
+}
+
+// TriggerPay triggers the pay event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *OrderMachine) TriggerPay(ctx context.Context, ev EventPay) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.triggerPay(ctx, ev)
+	})
+}
+
+func (machine *OrderMachine) triggerPay(ctx context.Context, ev EventPay) error {
+	return machine.Fire(ctx, OrderEventPay, ev, nil, func(ctx context.Context, transition OrderTransition) error {
+		if machine.PayAction == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError("PayAction", transition, machine.PayAction(newOrderContext(ctx, machine), machine.State, ev))
+	})
+}
@@ -0,0 +1,125 @@
+package transactional
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+var errDeclined = errors.New("card declined")
+
+func pay(ctx OrderMachineContext, order *Order, ev EventPay) error {
+	order.Attempts++
+	if ev.Fail {
+		return errDeclined
+	}
+	order.Paid = true
+	return nil
+}
+
+func TestFailedActionDoesNotCommit(t *testing.T) {
+	ctx := context.Background()
+	entered := 0
+	machine := NewOrderMachine(&Order{}, Environment{})
+	machine.PayAction = pay
+	machine.OnStatePaid = func(ctx OrderMachineContext, env Environment, state Order) error {
+		entered++
+		return nil
+	}
+
+	err := machine.TriggerPay(ctx, EventPay{Fail: true})
+	assert.Assert(t, errors.Is(err, errDeclined))
+	assert.Equal(t, OrderStatePending, machine.CurrentState)
+	assert.Equal(t, 0, entered)
+	// Without a CloneState hook the action's changes to the state object remain.
+	assert.Equal(t, 1, machine.State.Attempts)
+
+	assert.NilError(t, machine.TriggerPay(ctx, EventPay{}))
+	assert.Equal(t, OrderStatePaid, machine.CurrentState)
+	assert.Equal(t, 1, entered)
+	assert.DeepEqual(t, &Order{Attempts: 2, Paid: true}, machine.State)
+}
+
+func TestFailedActionReentersSource(t *testing.T) {
+	ctx := context.Background()
+	trace := []string{}
+	machine := NewOrderMachine(&Order{}, Environment{})
+	machine.PayAction = pay
+	machine.OnStatePending = func(ctx OrderMachineContext, env Environment, state Order) error {
+		trace = append(trace, "enter pending")
+		return nil
+	}
+	machine.OnExitPending = func(ctx OrderMachineContext, env Environment, state Order) error {
+		trace = append(trace, "exit pending")
+		return nil
+	}
+
+	assert.Assert(t, errors.Is(machine.TriggerPay(ctx, EventPay{Fail: true}), errDeclined))
+	assert.Equal(t, OrderStatePending, machine.CurrentState)
+	// The source state was exited before the action ran, so its entry handler runs again once the action fails.
+	assert.DeepEqual(t, []string{"exit pending", "enter pending"}, trace)
+}
+
+func TestFailedActionRestoresClone(t *testing.T) {
+	ctx := context.Background()
+	order := &Order{}
+	machine := NewOrderMachine(order, Environment{})
+	machine.PayAction = pay
+	machine.CloneState = func(state *Order) *Order {
+		clone := *state
+		return &clone
+	}
+
+	assert.Assert(t, errors.Is(machine.TriggerPay(ctx, EventPay{Fail: true}), errDeclined))
+	assert.Equal(t, OrderStatePending, machine.CurrentState)
+	assert.DeepEqual(t, &Order{}, order)
+	assert.Assert(t, machine.State == order)
+}
+
+func TestCommitBeforeAction(t *testing.T) {
+	ctx := context.Background()
+	machine := NewLegacyOrderMachine(&Order{}, Environment{})
+	machine.PayAction = func(ctx LegacyOrderMachineContext, order *Order, ev EventPay) error {
+		return errDeclined
+	}
+	assert.Assert(t, errors.Is(machine.TriggerPay(ctx, EventPay{}), errDeclined))
+	assert.Equal(t, LegacyOrderStatePaid, machine.CurrentState)
+}
+
+func TestActionErrorWrapped(t *testing.T) {
+	machine := NewOrderMachine(&Order{}, Environment{})
+	machine.PayAction = pay
+	err := machine.TriggerPay(context.Background(), EventPay{Fail: true})
+	var handlerErr *fsmruntime.HandlerError
+	assert.Assert(t, errors.As(err, &handlerErr))
+	assert.Equal(t, fsmruntime.HandlerError{Handler: "PayAction", Event: "pay", From: "pending", To: "paid", Err: errDeclined}, *handlerErr)
+	assert.Error(t, err, "PayAction failed on pay from pending to paid: card declined")
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	machine := NewOrderMachine(&Order{}, Environment{})
+	machine.PayAction = pay
+	assert.NilError(t, machine.TriggerPay(context.Background(), EventPay{}))
+	data, err := json.Marshal(machine.Snapshot())
+	assert.NilError(t, err)
+	assert.Equal(t, `{"configuration":["paid"],"state":{"Attempts":1,"Paid":true}}`, string(data))
+
+	var snapshot OrderSnapshot
+	assert.NilError(t, json.Unmarshal(data, &snapshot))
+	restored, err := NewOrderMachineFromSnapshot(snapshot, Environment{})
+	assert.NilError(t, err)
+	restored.OnStatePaid = func(ctx OrderMachineContext, env Environment, state Order) error {
+		t.Fatal("restoring must not run entry handlers")
+		return nil
+	}
+	assert.Equal(t, OrderStatePaid, restored.CurrentState)
+	assert.DeepEqual(t, &Order{Attempts: 1, Paid: true}, restored.State)
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"configuration":["shipped"]}`), &snapshot), "unknown order state: shipped")
+	_, err = NewOrderMachineFromSnapshot(OrderSnapshot{}, Environment{})
+	assert.Error(t, err, "order snapshot has no active states")
+}
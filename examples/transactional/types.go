@@ -0,0 +1,18 @@
+package transactional
+
+//go:generate go run gen/gen.go
+type Order struct {
+	Attempts int
+	Paid     bool
+}
+
+type Environment struct{}
+
+const (
+	StatePending = "pending"
+	StatePaid    = "paid"
+)
+
+type EventPay struct {
+	Fail bool
+}
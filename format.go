@@ -0,0 +1,78 @@
+package fsmgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"strings"
+)
+
+// sourceContextLines is the number of lines either side of the offending line included in a FormatError.
+const sourceContextLines = 3
+
+// FormatError is returned when the rendered source is not valid Go and cannot be formatted. This indicates a bug in
+// the template or a type name that is not a valid Go expression.
+type FormatError struct {
+	// Err is the error returned by go/format.
+	Err error
+	// Line is the 1-indexed line of the rendered source the error was reported on, or 0 if unknown.
+	Line int
+	// Context contains the offending line of rendered source, numbered and surrounded by neighbouring lines.
+	Context string
+}
+
+func (err *FormatError) Error() string {
+	if err.Context == "" {
+		return "unable to format generated source: " + err.Err.Error()
+	}
+	return "unable to format generated source: " + err.Err.Error() + "\n" + err.Context
+}
+
+func (err *FormatError) Unwrap() error {
+	return err.Err
+}
+
+// formatSource runs the rendered source through go/format, returning a *FormatError on failure.
+func formatSource(src []byte) ([]byte, error) {
+	out, err := format.Source(src)
+	if err == nil {
+		return out, nil
+	}
+	line := 0
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		line = list[0].Pos.Line
+	}
+	return nil, &FormatError{
+		Err:     err,
+		Line:    line,
+		Context: sourceContext(src, line),
+	}
+}
+
+// sourceContext returns the numbered lines surrounding line, marking the line itself.
+func sourceContext(src []byte, line int) string {
+	if line <= 0 {
+		return ""
+	}
+	lines := bytes.Split(src, []byte("\n"))
+	if line > len(lines) {
+		return ""
+	}
+	start, end := line-sourceContextLines, line+sourceContextLines
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	out := &strings.Builder{}
+	for i := start; i <= end; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(out, "%s %4d | %s\n", marker, i, lines[i-1])
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,37 @@
+package fsmgen
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGenerateHeaderAndFormatting(t *testing.T) {
+	gen := New("formatted", testState{}, testEnv{}, "init", "running")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").To("running"))
+	src, err := gen.Generate()
+	assert.NilError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments|parser.PackageClauseOnly)
+	assert.NilError(t, err)
+	assert.Assert(t, ast.IsGenerated(file))
+	assert.Assert(t, strings.HasPrefix(string(src), "// Code generated by go-fsmgen. DO NOT EDIT.\n\npackage formatted\n"))
+	formatted, err := formatSource(src)
+	assert.NilError(t, err)
+	assert.Equal(t, string(formatted), string(src))
+}
+
+func TestGenerateFormatError(t *testing.T) {
+	gen := New("broken", "State{", testEnv{}, "init")
+	_, err := gen.Generate()
+	var ferr *FormatError
+	assert.Assert(t, errors.As(err, &ferr))
+	assert.Assert(t, ferr.Line > 0)
+	assert.ErrorContains(t, err, "Machine[BrokenState, BrokenEvent, State{]")
+	assert.Assert(t, strings.Contains(ferr.Context, ">"), ferr.Context)
+}
@@ -2,10 +2,15 @@ package fsmgen
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"reflect"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/iancoleman/strcase"
 )
@@ -16,38 +21,69 @@ type Generator struct {
 	Name string
 	// PackageName defines the name of the package the generated file belongs to. Defaults to Name.
 	PackageName string
+	// PackagePath is the import path of the package the generated file belongs to. Types from this package are
+	// referenced without a qualifier, and types from any other package are imported. When empty, types from a package
+	// whose last path element matches PackageName are treated as local.
+	PackagePath string
 	// Filename defines where the state machine file will be written to. Defaults to Name.generated.go
 	Filename string
-	// States contains all of the state names that the state machine may be in.
+	// DOTFilename optionally defines where Write also writes a Graphviz DOT diagram of the state machine.
+	DOTFilename string
+	// MermaidFilename optionally defines where Write also writes a Mermaid state diagram of the state machine.
+	MermaidFilename string
+	// States contains all of the state names that the state machine may be in, including compound and child states.
 	States []string
+	// Substates maps each compound state to its child states. The first child is the initial child, entered whenever
+	// the compound state is the target of a transition.
+	Substates map[string][]string
+	// ParallelStates contains the compound states whose children are orthogonal regions, which are all active while
+	// the parallel state is active.
+	ParallelStates map[string]bool
+	// FinalStates contains the states that, once entered, are never exited. The machine is done once a final state
+	// without a parent is entered, and a compound state completes once one of its final children is entered.
+	FinalStates map[string]bool
+	// Histories are pseudo-states that events may target to resume a compound state where it was last exited.
+	Histories []*History
 	// Events is a slice of all possible events that can occur in the state machine.
-	Events   []*Event
-	stateObj string
-	envObj   string
+	Events []*Event
+	// Hooks are named hooks that run on transitions between specific states.
+	Hooks []*TransitionHook
+	// CommitBeforeAction changes CurrentState to the target state before the event's action runs, leaving the machine
+	// in the target state if the action fails. By default the action runs against the source state, and the target
+	// state is only committed once the action succeeds. The source states' exit handlers still run before the action,
+	// so a failed action is not free of side effects: the source states' entry handlers run again to re-enter them,
+	// and transition hooks that already ran are not undone.
+	CommitBeforeAction bool
+	// MaxChainLength is the default maximum number of events that may be queued by handlers and processed during a
+	// single trigger before the machine gives up and returns an error. Defaults to DefaultMaxChainLength, and a negative
+	// value disables the limit.
+	MaxChainLength int
+	// Concurrency defines how the generated machine synchronises access from multiple goroutines. Defaults to
+	// ConcurrencyNone, which machines with delayed transitions combine with the mutex of ConcurrencyMutex.
+	Concurrency Concurrency
+	// FailOn lists the kinds of Analyze findings that make Write fail with an *AnalysisError.
+	FailOn   []Finding
+	stateObj objType
+	envObj   objType
 }
 
+// DefaultMaxChainLength is the maximum chain length used when Generator.MaxChainLength is zero.
+const DefaultMaxChainLength = 100
+
 // New returns a new Generator with the supplied name, types and states. The first supplied state is the initial state.
 // The stateObj and envObj values can be either a struct, pointer to a struct or a string naming a type. Unfortunately
-// you cannot pass an interface, so if this is required simply pass in the interface's name as a string.
+// you cannot pass an interface, so if this is required simply pass in the interface's name as a string. Type names from
+// other packages are qualified by their full import path, such as "github.com/org/events.Load", and may be prefixed by
+// "*" or "[]"; the generated file imports their packages. The machine always holds a pointer to the state object,
+// whether or not stateObj is a pointer, whereas the environment is passed to handlers with exactly the type of envObj.
 func New(name string, stateObj interface{}, envObj interface{}, states ...string) *Generator {
-	stateObjStr, envObjStr := "", ""
-	if str, ok := stateObj.(string); ok {
-		stateObjStr = str
-	} else {
-		stateObjStr = reflect.TypeOf(stateObj).Name()
-	}
-	if str, ok := envObj.(string); ok {
-		envObjStr = str
-	} else {
-		envObjStr = reflect.TypeOf(envObj).Name()
-	}
 	return &Generator{
 		Name:        name,
 		PackageName: name,
 		Filename:    name + ".generated.go",
 		States:      states,
-		stateObj:    stateObjStr,
-		envObj:      envObjStr,
+		stateObj:    newObjType(stateObj).elem(),
+		envObj:      newObjType(envObj),
 	}
 }
 
@@ -56,42 +92,653 @@ func (gen *Generator) AddEvent(ev *Event) {
 	gen.Events = append(gen.Events, ev)
 }
 
-// Write will generator and output the state machine to file.
+// AddSubstates declares the supplied states as children of the parent state, making it a compound state. The first
+// child is the initial child. Every state must also be declared in States. While a child is active its parent is also
+// active, so events from the parent apply to all of its descendants unless a descendant handles the event itself.
+func (gen *Generator) AddSubstates(parent string, children ...string) {
+	if gen.Substates == nil {
+		gen.Substates = map[string][]string{}
+	}
+	gen.Substates[parent] = append(gen.Substates[parent], children...)
+}
+
+// AddRegions declares the supplied states as the orthogonal regions of the parent state, making it a parallel state.
+// Entering the parallel state enters every region, each of which usually has child states of its own. An event may be
+// declared once for each region it applies to, and transitions every active region that handles it at once.
+func (gen *Generator) AddRegions(parent string, regions ...string) {
+	gen.AddSubstates(parent, regions...)
+	if gen.ParallelStates == nil {
+		gen.ParallelStates = map[string]bool{}
+	}
+	gen.ParallelStates[parent] = true
+}
+
+// Final declares the supplied states as final states, which may not have outgoing transitions. Entering a top-level
+// final state finishes the machine, closing its Done channel and rejecting any further event with
+// runtime.ErrMachineDone. Entering a final child of a compound state completes the compound state, triggering its
+// completion events, and a parallel state completes once all of its regions have.
+func (gen *Generator) Final(states ...string) {
+	if gen.FinalStates == nil {
+		gen.FinalStates = map[string]bool{}
+	}
+	for _, state := range states {
+		gen.FinalStates[state] = true
+	}
+}
+
+// History is a pseudo-state that, when targeted, re-enters its parent compound state in the configuration it was in
+// when it was last exited. Shallow history restores the parent's active children, while deep history restores every
+// active leaf state below the parent. Default is entered if the parent has not been exited before.
+type History struct {
+	Name    string
+	Parent  string
+	Default string
+	Deep    bool
+}
+
+// ShallowHistory adds a shallow history pseudo-state to the parent compound state, which events may use as a target.
+// When targeted, the children of parent that were active when it was last exited are entered, each in its default
+// configuration, or the default state if parent has not been exited before.
+func (gen *Generator) ShallowHistory(name, parent, defaultState string) {
+	gen.Histories = append(gen.Histories, &History{Name: name, Parent: parent, Default: defaultState})
+}
+
+// DeepHistory adds a deep history pseudo-state to the parent compound state, which events may use as a target. When
+// targeted, every leaf state below parent that was active when it was last exited is entered again, or the default
+// state if parent has not been exited before.
+func (gen *Generator) DeepHistory(name, parent, defaultState string) {
+	gen.Histories = append(gen.Histories, &History{Name: name, Parent: parent, Default: defaultState, Deep: true})
+}
+
+// parents returns the parent of every child state.
+func (gen *Generator) parents() map[string]string {
+	parents := map[string]string{}
+	for parent, children := range gen.Substates {
+		for _, child := range children {
+			parents[child] = parent
+		}
+	}
+	return parents
+}
+
+// initialLeaf returns the first leaf state entered when the supplied state is entered, following the first child of
+// each compound state, or the first region of each parallel state, until reaching a state without children.
+func (gen *Generator) initialLeaf(state string) string {
+	for seen := map[string]bool{}; len(gen.Substates[state]) > 0 && !seen[state]; {
+		seen[state] = true
+		state = gen.Substates[state][0]
+	}
+	return state
+}
+
+// completable returns whether the supplied compound state can complete: a compound state needs a final child, and a
+// parallel state needs every region to be completable.
+func (gen *Generator) completable(state string) bool {
+	return gen.completableFrom(state, map[string]bool{})
+}
+
+func (gen *Generator) completableFrom(state string, seen map[string]bool) bool {
+	if seen[state] {
+		return false
+	}
+	seen[state] = true
+	children := gen.Substates[state]
+	if gen.ParallelStates[state] {
+		for _, region := range children {
+			if !gen.completableFrom(region, seen) {
+				return false
+			}
+		}
+		return len(children) > 0
+	}
+	for _, child := range children {
+		if gen.FinalStates[child] {
+			return true
+		}
+	}
+	return false
+}
+
+// orthogonal returns whether the supplied states are in different regions of a parallel state, and so may be active at
+// the same time.
+func (gen *Generator) orthogonal(a, b string) bool {
+	ancestors := map[string]bool{a: true}
+	for _, state := range gen.ancestors(a) {
+		ancestors[state] = true
+	}
+	for _, state := range append([]string{b}, gen.ancestors(b)...) {
+		if ancestors[state] {
+			return state != a && state != b && gen.ParallelStates[state]
+		}
+	}
+	return false
+}
+
+// within returns whether the supplied state is a proper descendant of the ancestor, where the empty ancestor contains
+// every state.
+func (gen *Generator) within(state, ancestor string) bool {
+	if state == "" || state == ancestor {
+		return false
+	}
+	if ancestor == "" {
+		return true
+	}
+	for _, other := range gen.ancestors(state) {
+		if other == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// overrides returns a source state of the inner event declaration that is more specific than a source state of the
+// outer one, by being its descendant or the outer declaration being from any state, along with the overridden state,
+// which is empty for a declaration from any state. Transitions are looked up from the innermost active state first, so
+// the inner declaration takes precedence there.
+func (gen *Generator) overrides(inner, outer *Event) (from, overridden string, ok bool) {
+	for _, state := range sources(inner) {
+		for _, other := range sources(outer) {
+			if gen.within(state, other) {
+				return state, other, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// sources returns the source states of an event declaration, which is the empty state for events from any state.
+func sources(event *Event) []string {
+	if len(event.FromStates) == 0 {
+		return []string{""}
+	}
+	return event.FromStates
+}
+
+// ancestors returns the ancestors of the supplied state, innermost first. It stops early if the states form a cycle,
+// which validation reports.
+func (gen *Generator) ancestors(state string) []string {
+	parents := gen.parents()
+	ancestors := []string{}
+	seen := map[string]bool{state: true}
+	for state = parents[state]; state != "" && !seen[state]; state = parents[state] {
+		seen[state] = true
+		ancestors = append(ancestors, state)
+	}
+	return ancestors
+}
+
+// documentOrder returns every state in document order: each state is followed by its descendants, and children follow
+// the order they were declared in.
+func (gen *Generator) documentOrder() []string {
+	order := []string{}
+	var visit func(state string)
+	visit = func(state string) {
+		order = append(order, state)
+		for _, child := range gen.Substates[state] {
+			visit(child)
+		}
+	}
+	for _, state := range gen.roots() {
+		visit(state)
+	}
+	return order
+}
+
+// roots returns the states without a parent, in declaration order.
+func (gen *Generator) roots() []string {
+	parents := gen.parents()
+	roots := []string{}
+	for _, state := range gen.States {
+		if _, ok := parents[state]; !ok {
+			roots = append(roots, state)
+		}
+	}
+	return roots
+}
+
+// TransitionHook defines a named hook that runs whenever the machine transitions from FromState to ToState. An empty
+// FromState or ToState matches any state, and a compound state matches any of its descendants.
+type TransitionHook struct {
+	Name      string
+	FromState string
+	ToState   string
+}
+
+// AddTransitionHook adds a named hook that runs on every transition from the from state to the to state, by any event.
+// Either state may be empty to match any state. Each hook becomes a field on the generated machine, and hooks run after
+// the source state's exit handler and before the event's action, in the order they were added.
+func (gen *Generator) AddTransitionHook(name, from, to string) {
+	gen.Hooks = append(gen.Hooks, &TransitionHook{Name: name, FromState: from, ToState: to})
+}
+
+// Write will validate, generate and output the state machine to file, along with any diagrams that have a filename set.
+// No file is written if the definition is invalid, or if Analyze reports a finding of a kind listed in FailOn.
 func (gen *Generator) Write() error {
-	t, err := template.New("fsm").Parse(tmpl)
+	src, err := gen.Generate()
 	if err != nil {
 		return err
 	}
+	if len(gen.FailOn) > 0 {
+		problems := gen.Analyze().Problems(gen.FailOn...)
+		if len(problems) > 0 {
+			return &AnalysisError{Name: gen.Name, Problems: problems}
+		}
+	}
+	err = ioutil.WriteFile(gen.Filename, src, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+	if gen.DOTFilename != "" {
+		err = gen.writeDiagram(gen.DOTFilename, gen.WriteDOT)
+		if err != nil {
+			return err
+		}
+	}
+	if gen.MermaidFilename != "" {
+		err = gen.writeDiagram(gen.MermaidFilename, gen.WriteMermaid)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDiagram writes a diagram to the supplied file using one of the diagram writing methods.
+func (gen *Generator) writeDiagram(filename string, write func(w io.Writer) error) error {
 	out := &bytes.Buffer{}
-	err = t.Execute(out, &tmplGenerator{Generator: gen})
+	err := write(out)
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(gen.Filename, out.Bytes(), os.FileMode(0644))
-	return err
+	return ioutil.WriteFile(filename, out.Bytes(), os.FileMode(0644))
+}
+
+// Generate validates the state machine definition and returns the generated, gofmt'd source. If the rendered source is
+// not valid Go a *FormatError is returned that includes the offending line.
+func (gen *Generator) Generate() ([]byte, error) {
+	err := gen.Validate()
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.New("fsm").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	out := &bytes.Buffer{}
+	err = t.Execute(out, newTmplGenerator(gen))
+	if err != nil {
+		return nil, err
+	}
+	return formatSource(out.Bytes())
 }
 
 // tmplGenerator is a wrapper around the Generator type that provides methods only intended for the template to use.
 type tmplGenerator struct {
 	*Generator
+	imports   *imports
+	stateExpr string
+	envExpr   string
+	eventExpr map[*Event]string
+	runtime   string
+}
+
+// newTmplGenerator resolves the type expressions used by the template up front, so that the import block is complete
+// before the template is executed.
+func newTmplGenerator(gen *Generator) *tmplGenerator {
+	tg := &tmplGenerator{
+		Generator: gen,
+		imports:   newImports(gen.isLocalPackage, gen.stdImports()...),
+		eventExpr: map[*Event]string{},
+	}
+	tg.runtime = tg.imports.aliasAs(runtimePkgPath, "fsmruntime")
+	tg.stateExpr = tg.imports.objExpr(gen.stateObj)
+	tg.envExpr = tg.imports.objExpr(gen.envObj)
+	for _, event := range gen.Events {
+		tg.eventExpr[event] = tg.imports.objExpr(event.objType())
+	}
+	return tg
+}
+
+// stdImports returns the standard library packages used by the generated code.
+func (gen *Generator) stdImports() []string {
+	std := []string{"context", "errors", "fmt"}
+	if gen.delayed() {
+		std = append(std, "time")
+	}
+	return std
+}
+
+// completions returns whether any event is a completion transition.
+func (gen *Generator) completions() bool {
+	for _, event := range gen.Events {
+		if event != nil && event.Completion {
+			return true
+		}
+	}
+	return false
+}
+
+// internal returns whether any event is an internal transition.
+func (gen *Generator) internal() bool {
+	for _, event := range gen.Events {
+		if event != nil && event.InternalTransition {
+			return true
+		}
+	}
+	return false
+}
+
+// delayed returns whether any event is a delayed transition.
+func (gen *Generator) delayed() bool {
+	for _, event := range gen.Events {
+		if event != nil && event.Delay != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalPackage returns whether the supplied import path is the package the generated file belongs to.
+func (gen *Generator) isLocalPackage(pkgPath string) bool {
+	if gen.PackagePath != "" {
+		return pkgPath == gen.PackagePath
+	}
+	return path.Base(pkgPath) == gen.PackageName
+}
+
+func (gen *tmplGenerator) StdImports() []importSpec {
+	return gen.imports.StdSpecs()
+}
+
+func (gen *tmplGenerator) PkgImports() []importSpec {
+	return gen.imports.PkgSpecs()
 }
 
 func (gen *tmplGenerator) EnvObjName() string {
-	return gen.envObj
+	return gen.envExpr
 }
 
 func (gen *tmplGenerator) StateObjName() string {
-	return gen.stateObj
+	return gen.stateExpr
+}
+
+func (gen *tmplGenerator) EventObjName(event *Event) string {
+	return gen.eventExpr[event]
+}
+
+// Delayed returns whether the generated machine schedules delayed transitions.
+func (gen *tmplGenerator) Delayed() bool {
+	return gen.delayed()
+}
+
+// Internals returns whether the generated machine has internal transitions.
+func (gen *tmplGenerator) Internals() bool {
+	return gen.internal()
+}
+
+// InternalMap returns the names of the internal events declared on each state, keyed by the empty state for events
+// from any state.
+func (gen *tmplGenerator) InternalMap() map[string][]string {
+	internal := map[string][]string{}
+	for _, event := range gen.Events {
+		if !event.InternalTransition {
+			continue
+		}
+		for _, state := range sources(event) {
+			internal[state] = append(internal[state], event.Name)
+		}
+	}
+	return internal
+}
+
+// DelayedFrom returns the delayed events scheduled on entry to each state.
+func (gen *tmplGenerator) DelayedFrom() map[string][]*Event {
+	delayed := map[string][]*Event{}
+	for _, event := range gen.Events {
+		if event.Delay == 0 {
+			continue
+		}
+		for _, state := range event.FromStates {
+			delayed[state] = append(delayed[state], event)
+		}
+	}
+	return delayed
+}
+
+// Completions returns whether the generated machine triggers completion transitions.
+func (gen *tmplGenerator) Completions() bool {
+	return gen.completions()
+}
+
+// CompletionFrom returns the completion events triggered when each state completes.
+func (gen *tmplGenerator) CompletionFrom() map[string][]*Event {
+	completions := map[string][]*Event{}
+	for _, event := range gen.Events {
+		if !event.Completion {
+			continue
+		}
+		for _, state := range event.FromStates {
+			completions[state] = append(completions[state], event)
+		}
+	}
+	return completions
+}
+
+// TransitionRules returns every declared transition, in declaration order, from the empty state for events that may
+// occur from any state.
+func (gen *tmplGenerator) TransitionRules() []edge {
+	return gen.rules()
+}
+
+// Finals returns whether the definition declares any final states.
+func (gen *tmplGenerator) Finals() bool {
+	return len(gen.FinalStates) > 0
+}
+
+// AutomaticEvents returns the first delayed or completion declaration of each event name triggered by the machine
+// itself, in declaration order.
+func (gen *tmplGenerator) AutomaticEvents() []*Event {
+	events := []*Event{}
+	seen := map[string]bool{}
+	for _, event := range gen.Events {
+		if (event.Delay != 0 || event.Completion) && !seen[event.Name] {
+			seen[event.Name] = true
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// DurationExpr returns a Go expression for the supplied duration, in the largest unit that represents it exactly.
+func (gen *tmplGenerator) DurationExpr(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// ChainLimit returns the default maximum chain length of the generated machine.
+func (gen *tmplGenerator) ChainLimit() int {
+	switch {
+	case gen.MaxChainLength == 0:
+		return DefaultMaxChainLength
+	case gen.MaxChainLength < 0:
+		return 0
+	}
+	return gen.MaxChainLength
+}
+
+// ConcurrencyConst returns the name of the runtime constant for the generated machine's concurrency mode, or an empty
+// string if it performs no synchronisation.
+func (gen *tmplGenerator) ConcurrencyConst() string {
+	switch gen.Concurrency {
+	case ConcurrencyMutex:
+		return "ConcurrencyMutex"
+	case ConcurrencyActor:
+		return "ConcurrencyActor"
+	}
+	return ""
+}
+
+// HookField returns the name of the generated field for the supplied transition hook.
+func (gen *tmplGenerator) HookField(hook *TransitionHook) string {
+	return exportedName(hook.Name) + "Hook"
+}
+
+// Runtime returns the alias the runtime package is imported as.
+func (gen *tmplGenerator) Runtime() string {
+	return gen.runtime
+}
+
+// GuardNames returns the distinct guard names used by every declaration of the supplied event.
+func (gen *tmplGenerator) GuardNames(event *Event) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, decl := range gen.Declarations(event) {
+		for _, name := range decl.guardNames() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Guarded returns whether any declaration of the supplied event is guarded.
+func (gen *tmplGenerator) Guarded(event *Event) bool {
+	for _, decl := range gen.Declarations(event) {
+		if decl.Guarded() {
+			return true
+		}
+	}
+	return false
+}
+
+// GuardField returns the name of the generated hook field for the supplied event's guard.
+func (gen *tmplGenerator) GuardField(event *Event, guard string) string {
+	return exportedName(event.Name) + exportedName(guard) + "Guard"
+}
+
+// StateConst returns the name of the generated constant for the supplied state.
+func (gen *tmplGenerator) StateConst(state string) string {
+	return exportedName(gen.Name) + "State" + exportedName(state)
+}
+
+// StateValue returns the generated constant for the supplied state, or the zero value for an empty state.
+func (gen *tmplGenerator) StateValue(state string) string {
+	if state == "" {
+		return `""`
+	}
+	return gen.StateConst(state)
+}
+
+// EventConst returns the name of the generated constant for the supplied event.
+func (gen *tmplGenerator) EventConst(event string) string {
+	return exportedName(gen.Name) + "Event" + exportedName(event)
 }
 
 func (gen *tmplGenerator) ExportedName(str string) string {
-	return strcase.ToCamel(str)
+	return exportedName(str)
 }
 
 func (gen *tmplGenerator) UnexportedName(str string) string {
+	return unexportedName(str)
+}
+
+// exportedName returns the exported Go identifier used for the supplied state, event or machine name.
+func exportedName(str string) string {
+	return strcase.ToCamel(str)
+}
+
+// unexportedName returns the unexported Go identifier used for the supplied state, event or machine name.
+func unexportedName(str string) string {
 	return strcase.ToLowerCamel(str)
 }
 
+// ParentMap returns the parent of every child state and history pseudo-state.
+func (gen *tmplGenerator) ParentMap() map[string]string {
+	parents := gen.parents()
+	for _, history := range gen.Histories {
+		parents[history.Name] = history.Parent
+	}
+	return parents
+}
+
+// InitialMap returns the initial child of every compound state that is not a parallel state.
+func (gen *tmplGenerator) InitialMap() map[string]string {
+	initial := map[string]string{}
+	for parent, children := range gen.Substates {
+		if len(children) > 0 && !gen.ParallelStates[parent] {
+			initial[parent] = children[0]
+		}
+	}
+	return initial
+}
+
+// RegionMap returns the regions of every parallel state.
+func (gen *tmplGenerator) RegionMap() map[string][]string {
+	regions := map[string][]string{}
+	for parent := range gen.ParallelStates {
+		regions[parent] = gen.Substates[parent]
+	}
+	return regions
+}
+
+// DocumentOrder returns every state in document order.
+func (gen *tmplGenerator) DocumentOrder() []string {
+	return gen.documentOrder()
+}
+
+// DistinctEvents returns the first declaration of each event name, in declaration order.
+func (gen *tmplGenerator) DistinctEvents() []*Event {
+	events := []*Event{}
+	seen := map[string]bool{}
+	for _, event := range gen.Events {
+		if !seen[event.Name] {
+			seen[event.Name] = true
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Declarations returns every declaration of the supplied event's name, in declaration order.
+func (gen *tmplGenerator) Declarations(event *Event) []*Event {
+	events := []*Event{}
+	for _, other := range gen.Events {
+		if other.Name == event.Name {
+			events = append(events, other)
+		}
+	}
+	return events
+}
+
+// SourceCond returns a Go condition that is true when the from variable is the state an event declaration was found
+// on, which is the empty state for events from any state.
+func (gen *tmplGenerator) SourceCond(event *Event) string {
+	if len(event.FromStates) == 0 {
+		return `from == ""`
+	}
+	conds := make([]string, 0, len(event.FromStates))
+	for _, state := range event.FromStates {
+		conds = append(conds, "from == "+gen.StateConst(state))
+	}
+	return strings.Join(conds, " || ")
+}
+
 func (gen *tmplGenerator) TransitionMap() map[string]map[string]string {
 	out := map[string]map[string]string{
 		"": {},
@@ -111,20 +758,55 @@ func (gen *tmplGenerator) TransitionMap() map[string]map[string]string {
 	return out
 }
 
+// runtimePkgPath is the import path of the package containing the types shared by all generated machines.
+const runtimePkgPath = "github.com/snikch/go-fsmgen/runtime"
+
 // Event defines an Event that transitions from a set of states to a new state.
 type Event struct {
 	Name       string
 	FromStates []string
 	ToState    string
-	ObjName    reflect.Type
+	// ToGuard is the name of a guard that must pass for the event to transition to ToState. When empty, ToState is
+	// the target whenever none of the Branches are taken.
+	ToGuard string
+	// Branches are guarded alternate targets, evaluated in declaration order before ToState.
+	Branches []*Branch
+	// Delay makes the event a delayed transition, triggered automatically once a source state has been active for the
+	// duration.
+	Delay time.Duration
+	// Completion makes the event a completion transition, triggered automatically once a source state completes.
+	Completion bool
+	// Override marks the declaration as intentionally overriding another declaration of the event, from any state or
+	// from an ancestor of its source states. Overlapping declarations that are not marked are invalid.
+	Override bool
+	// InternalTransition makes the event an internal transition, which runs the event's action without exiting or
+	// entering any state.
+	InternalTransition bool
+	ObjName            reflect.Type
+	objName            string
 }
 
-// NewEvent returns a new event with the supplied name and Event object.
+// Branch defines a guarded target of an Event. The branch is taken if its guard passes.
+type Branch struct {
+	Guard   string
+	ToState string
+}
+
+// NewEvent returns a new event with the supplied name and Event object. As with New, the object may instead be a
+// string naming the type.
 func NewEvent(name string, obj interface{}) *Event {
-	return &Event{
-		Name:    name,
-		ObjName: reflect.TypeOf(obj),
+	ev := &Event{Name: name}
+	if str, ok := obj.(string); ok {
+		ev.objName = str
+	} else {
+		ev.ObjName = reflect.TypeOf(obj)
 	}
+	return ev
+}
+
+// objType returns the event's object type.
+func (ev *Event) objType() objType {
+	return objType{typ: ev.ObjName, name: ev.objName}
 }
 
 // FromAny defines all states as valid source states for this event.
@@ -139,124 +821,75 @@ func (ev *Event) From(from ...string) *Event {
 	return ev
 }
 
-// To defines the target state after this event.
+// To defines the target state after this event. If Branches are defined, this is the target when none of them pass.
 func (ev *Event) To(to string) *Event {
 	ev.ToState = to
 	return ev
 }
 
-const tmpl = `
-package {{ .PackageName }}
-
-// Code generated by go-fsmgen DO NOT EDIT.
-
-import (
-	"context"
-	"errors"
-)
-
-type {{ .ExportedName .Name }}Machine struct {
-	CurrentState string
-	State *{{ .StateObjName }}
-
-	env {{ .EnvObjName }}
-	transitions  map[string]map[string]string
-
-{{ range $event := .Events }}
-	{{ $.ExportedName $event.Name }}Action func(ctx {{ $.ExportedName $.Name }}MachineContext, state *{{ $.StateObjName }}, ev {{ $event.ObjName.Name }}) error
-{{- end }}
-{{ range $state := .States }}
-	OnState{{ $.ExportedName $state }} func(ctx {{ $.ExportedName $.Name }}MachineContext, env {{ $.EnvObjName }}, state {{ $.StateObjName }}) error
-{{- end }}
-}
-
-type {{ .ExportedName .Name }}MachineContext interface {
-	Context() context.Context
-{{- range $event := .Events }}
-	Trigger{{ $.ExportedName $event.Name }}(ev {{ $event.ObjName.Name }}) error
-{{- end }}
+// After makes the event a delayed transition: whenever one of its source states is entered, the machine schedules the
+// event to trigger with a zero value event object once d has elapsed, and cancels it if the state is exited first. The
+// event may still be triggered explicitly.
+func (ev *Event) After(d time.Duration) *Event {
+	ev.Delay = d
+	return ev
 }
 
-type {{ .UnexportedName .Name }}MachineContext struct {
-	ctx context.Context
-	machine *{{ .ExportedName .Name }}Machine
+// OnCompletion makes the event a completion transition: whenever one of its source states completes, by entering a
+// final child or, for a parallel state, once every region has entered a final state, the machine queues the event with
+// a zero value event object. The event may still be triggered explicitly.
+func (ev *Event) OnCompletion() *Event {
+	ev.Completion = true
+	return ev
 }
 
-func new{{ .ExportedName .Name }}Context(ctx context.Context, machine *{{ .ExportedName .Name }}Machine) {{ .ExportedName .Name }}MachineContext {
-	return &{{ .UnexportedName .Name }}MachineContext{
-		ctx: ctx,
-		machine: machine,
-	}
+// Overrides marks the event as a more specific declaration of an event that is also declared from any state, or from
+// an ancestor of its source states. While one of its source states is active, this declaration takes precedence.
+func (ev *Event) Overrides() *Event {
+	ev.Override = true
+	return ev
 }
 
-func (ctx {{ .UnexportedName .Name }}MachineContext) Context() context.Context {
-	return ctx.ctx
-}
-{{ range $event := .Events }}
-func (ctx {{ $.UnexportedName $.Name }}MachineContext) Trigger{{ $.ExportedName $event.Name }}(ev {{ $event.ObjName.Name }}) error {
-	return ctx.machine.Trigger{{ $.ExportedName $event.Name }}(ctx.ctx, ev)
+// Internal makes the event an internal transition, which only runs the event's action (after any guard passes): no
+// state is exited or entered, so no handlers or hooks run and delayed transitions keep their timers. An internal event
+// has no target, or targets its own source state. By contrast an event targeting its own source state without Internal
+// is an external self-transition, which exits and re-enters the state.
+func (ev *Event) Internal() *Event {
+	ev.InternalTransition = true
+	return ev
 }
-{{- end }}
 
-func New{{ .ExportedName .Name }}Machine(state *{{ .StateObjName }}, env {{ .EnvObjName }}) *{{ .ExportedName  .Name}}Machine{
-	return &{{ .ExportedName .Name }}Machine{
-		State:        state,
-		CurrentState: "{{ (index .States 0) }}",
-		env:          env,
-		transitions:  map[string]map[string]string{
-			{{- range $from, $events := .TransitionMap }}
-				"{{ $from }}": {
-				{{- range $event, $target := $events }}
-					"{{ $event }}": "{{ $target }}",
-				{{- end }}
-				},
-			{{- end }}
-		},
-	}
+// Guard defines the named guard that must pass for this event to transition to the state supplied to To. The
+// generated machine has a hook field for each guard, and a nil hook never passes.
+func (ev *Event) Guard(name string) *Event {
+	ev.ToGuard = name
+	return ev
 }
 
-func (machine *{{ .ExportedName .Name }}Machine) Start(ctx context.Context) (error) {
-	return machine.didEnterState(ctx)
+// Branch adds an alternate target state that is transitioned to if the named guard passes. Branches are evaluated in
+// the order they are declared, falling through to the state supplied to To.
+func (ev *Event) Branch(guard string, to string) *Event {
+	ev.Branches = append(ev.Branches, &Branch{Guard: guard, ToState: to})
+	return ev
 }
 
-func (machine *{{ .ExportedName .Name }}Machine) getState(event string) (string, error) {
-	target := machine.transitions[machine.CurrentState][event]
-	if target != "" {
-		return target, nil
-	}
-	target = machine.transitions[""][event]
-	if target != "" {
-		return target, nil
-	}
-	return "", errors.New("invalid transition: no transition target from " + machine.CurrentState + " via " + event)
+// Guarded returns whether the event's target depends on any guard.
+func (ev *Event) Guarded() bool {
+	return ev.ToGuard != "" || len(ev.Branches) > 0
 }
 
-func (machine *{{ .ExportedName .Name }}Machine) didEnterState(ctx context.Context) error {
-	switch machine.CurrentState {
-	{{- range $state := .States }}
-	case "{{ $state }}":
-		if machine.OnState{{ $.ExportedName $state }} == nil {
-			break
+// guardNames returns the distinct guard names used by the event, in declaration order.
+func (ev *Event) guardNames() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, branch := range ev.Branches {
+		if !seen[branch.Guard] {
+			seen[branch.Guard] = true
+			names = append(names, branch.Guard)
 		}
-		return machine.OnState{{ $.ExportedName $state }}(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.env, *machine.State)
-	{{- end }}
-	}
-	return nil
-}
-{{ range $event := .Events }}
-func (machine *{{ $.ExportedName $.Name }}Machine) Trigger{{ $.ExportedName $event.Name }} (ctx context.Context, ev {{ $event.ObjName.Name }}) error {
-	target, err := machine.getState("{{ $event.Name }}")
-	if err != nil {
-	return err
 	}
-	machine.CurrentState = target
-	if machine.{{ $.ExportedName $event.Name }}Action != nil {
-		err := machine.{{ $.ExportedName $event.Name }}Action(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.State, ev)
-		if err != nil {
-			return err
-		}
+	if ev.ToGuard != "" && !seen[ev.ToGuard] {
+		names = append(names, ev.ToGuard)
 	}
-	return machine.didEnterState(ctx)
+	return names
 }
-{{ end }}
-`
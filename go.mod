@@ -1,10 +1,11 @@
 module github.com/snikch/go-fsmgen
 
-go 1.15
+go 1.18
 
 require (
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/iancoleman/strcase v0.1.2
 	github.com/pkg/errors v0.9.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 	gotest.tools v2.2.0+incompatible
 )
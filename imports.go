@@ -0,0 +1,194 @@
+package fsmgen
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// objType refers to a Go type used by the generated code, either through reflection or by a name supplied verbatim.
+type objType struct {
+	typ  reflect.Type
+	name string
+}
+
+// newObjType returns an objType for the supplied value. Strings are treated as type names and used verbatim.
+func newObjType(obj interface{}) objType {
+	if str, ok := obj.(string); ok {
+		return objType{name: str}
+	}
+	return objType{typ: reflect.TypeOf(obj)}
+}
+
+// elem returns the objType with any pointer indirection removed.
+func (obj objType) elem() objType {
+	for obj.typ != nil && obj.typ.Kind() == reflect.Ptr {
+		obj.typ = obj.typ.Elem()
+	}
+	obj.name = strings.TrimLeft(obj.name, "*")
+	return obj
+}
+
+func (obj objType) missing() bool {
+	return obj.typ == nil && obj.name == ""
+}
+
+// importSpec is a single entry in the generated import block.
+type importSpec struct {
+	Alias string
+	Path  string
+}
+
+// imports tracks the packages referenced by the generated code, assigning each a unique alias.
+type imports struct {
+	local   func(pkgPath string) bool
+	std     []string
+	aliases map[string]string
+	taken   map[string]bool
+}
+
+func newImports(local func(pkgPath string) bool, std ...string) *imports {
+	im := &imports{
+		local:   local,
+		std:     std,
+		aliases: map[string]string{},
+		taken:   map[string]bool{},
+	}
+	for _, pkg := range std {
+		im.taken[path.Base(pkg)] = true
+	}
+	return im
+}
+
+// objExpr returns the Go expression for the supplied objType, importing any packages it references.
+func (im *imports) objExpr(obj objType) string {
+	if obj.typ != nil {
+		return im.typeExpr(obj.typ)
+	}
+	prefix, pkgPath, name := parseTypeName(obj.name)
+	if pkgPath == "" || im.local(pkgPath) {
+		return prefix + name
+	}
+	return prefix + im.alias(pkgPath) + "." + name
+}
+
+// parseTypeName splits a type name such as "*github.com/org/events.Load" into its "*" and "[]" prefix, import path and
+// unqualified name. The import path is empty for unqualified names.
+func parseTypeName(str string) (prefix, pkgPath, name string) {
+	name = str
+	for strings.HasPrefix(name, "*") || strings.HasPrefix(name, "[]") {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "*"), "[]")
+	}
+	prefix = str[:len(str)-len(name)]
+	i := strings.LastIndex(name, ".")
+	if i < 0 || strings.ContainsAny(name, " {}()") {
+		return prefix, "", name
+	}
+	return prefix, name[:i], name[i+1:]
+}
+
+// typeExpr returns the Go expression for the supplied type, importing any packages it references.
+func (im *imports) typeExpr(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || im.local(t.PkgPath()) {
+			return t.Name()
+		}
+		return im.alias(t.PkgPath()) + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + im.typeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + im.typeExpr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), im.typeExpr(t.Elem()))
+	case reflect.Map:
+		return "map[" + im.typeExpr(t.Key()) + "]" + im.typeExpr(t.Elem())
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + im.typeExpr(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + im.typeExpr(t.Elem())
+		}
+		return "chan " + im.typeExpr(t.Elem())
+	}
+	return t.String()
+}
+
+// alias returns the alias the supplied package is imported as, adding it to the import block if required.
+func (im *imports) alias(pkgPath string) string {
+	return im.aliasAs(pkgPath, packageIdent(pkgPath))
+}
+
+// aliasAs returns the alias the supplied package is imported as, preferring base if the package is not yet imported.
+func (im *imports) aliasAs(pkgPath, base string) string {
+	if alias, ok := im.aliases[pkgPath]; ok {
+		return alias
+	}
+	alias := base
+	for i := 2; im.taken[alias] || token.Lookup(alias).IsKeyword(); i++ {
+		alias = base + strconv.Itoa(i)
+	}
+	im.taken[alias] = true
+	im.aliases[pkgPath] = alias
+	return alias
+}
+
+// StdSpecs returns the standard library packages imported by the generated code.
+func (im *imports) StdSpecs() []importSpec {
+	specs := make([]importSpec, 0, len(im.std))
+	for _, pkg := range im.std {
+		specs = append(specs, importSpec{Path: pkg})
+	}
+	return specs
+}
+
+// PkgSpecs returns the aliased packages imported for the state, environment and event types, sorted by path.
+func (im *imports) PkgSpecs() []importSpec {
+	paths := make([]string, 0, len(im.aliases))
+	for pkgPath := range im.aliases {
+		paths = append(paths, pkgPath)
+	}
+	sort.Strings(paths)
+	specs := make([]importSpec, 0, len(paths))
+	for _, pkgPath := range paths {
+		specs = append(specs, importSpec{Alias: im.aliases[pkgPath], Path: pkgPath})
+	}
+	return specs
+}
+
+// packageIdent derives a valid identifier from an import path. The real package name cannot be discovered through
+// reflection, which is why every package is imported with an explicit alias.
+func packageIdent(pkgPath string) string {
+	elems := strings.Split(pkgPath, "/")
+	base := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(base) {
+		base = elems[len(elems)-2]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	base = strings.TrimSuffix(base, "-go")
+	ident := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, base)
+	if ident == "" || unicode.IsDigit(rune(ident[0])) {
+		ident = "pkg" + ident
+	}
+	return ident
+}
+
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
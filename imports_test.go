@@ -0,0 +1,86 @@
+package fsmgen
+
+import (
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+
+	"gotest.tools/assert"
+)
+
+func TestGenerateImports(t *testing.T) {
+	gen := New("imports", &testState{}, &url.URL{}, "init")
+	gen.PackagePath = "github.com/snikch/go-fsmgen"
+	gen.AddEvent(NewEvent("local", testEvent{}).FromAny().To("init"))
+	gen.AddEvent(NewEvent("pointer", &url.Userinfo{}).FromAny().To("init"))
+	gen.AddEvent(NewEvent("text", []*texttemplate.Template{}).FromAny().To("init"))
+	gen.AddEvent(NewEvent("html", map[string]htmltemplate.HTML{}).FromAny().To("init"))
+
+	src, err := gen.Generate()
+	assert.NilError(t, err)
+	out := string(src)
+	for _, expected := range []string{
+		`url "net/url"`,
+		`template "text/template"`,
+		`template2 "html/template"`,
+		"state *testState, ev testEvent)",
+		"env *url.URL",
+		"ev testEvent)",
+		"ev *url.Userinfo)",
+		"ev []*template.Template)",
+		"ev map[string]template2.HTML)",
+	} {
+		assert.Assert(t, strings.Contains(out, expected), "expected generated code to contain %q", expected)
+	}
+	assert.Equal(t, 1, strings.Count(out, `"net/url"`))
+}
+
+func TestPackageIdent(t *testing.T) {
+	for pkgPath, expected := range map[string]string{
+		"github.com/snikch/go-fsmgen": "fsmgen",
+		"gopkg.in/yaml.v3":            "yamlv3",
+		"github.com/foo/bar/v2":       "bar",
+		"example.com/my-events":       "myevents",
+		"example.com/2fa":             "pkg2fa",
+		"github.com/snikch/events-go": "events",
+	} {
+		assert.Equal(t, expected, packageIdent(pkgPath), pkgPath)
+	}
+}
+
+func TestParseTypeName(t *testing.T) {
+	for str, expected := range map[string][3]string{
+		"State":                         {"", "", "State"},
+		"*State":                        {"*", "", "State"},
+		"time.Duration":                 {"", "time", "Duration"},
+		"[]*github.com/org/events.Load": {"[]*", "github.com/org/events", "Load"},
+		"gopkg.in/yaml.v3.Node":         {"", "gopkg.in/yaml.v3", "Node"},
+		"struct{ a.B }":                 {"", "", "struct{ a.B }"},
+	} {
+		prefix, pkgPath, name := parseTypeName(str)
+		assert.DeepEqual(t, expected, [3]string{prefix, pkgPath, name})
+	}
+}
+
+func TestGenerateImportsFromTypeNames(t *testing.T) {
+	gen := New("names", "*github.com/org/domain.State", "Environment", "init")
+	gen.PackagePath = "github.com/org/machines"
+	gen.AddEvent(NewEvent("load", "[]github.com/org/events.Load").FromAny().To("init"))
+	gen.AddEvent(NewEvent("local", "*github.com/org/machines.Local").FromAny().To("init"))
+
+	src, err := gen.Generate()
+	assert.NilError(t, err)
+	out := string(src)
+	for _, expected := range []string{
+		`domain "github.com/org/domain"`,
+		`events "github.com/org/events"`,
+		"state *domain.State, ev []events.Load)",
+		"state *domain.State, ev *Local)",
+		"env Environment",
+	} {
+		assert.Assert(t, strings.Contains(out, expected), "expected generated code to contain %q", expected)
+	}
+	assert.Assert(t, !strings.Contains(out, `"github.com/org/machines"`))
+}
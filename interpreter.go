@@ -0,0 +1,361 @@
+package fsmgen
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+)
+
+// InterpreterAction is the action of an event run by an Interpreter. The state is the pointer to the state object the
+// interpreter was created with, and ev is the event object.
+type InterpreterAction func(ctx InterpreterContext, state interface{}, ev interface{}) error
+
+// InterpreterGuard is a guard of an event run by an Interpreter. The state is a copy of the state object, and ev is the
+// event object.
+type InterpreterGuard func(ctx InterpreterContext, state interface{}, ev interface{}) bool
+
+// InterpreterHandler is an entry or exit handler of a state run by an Interpreter. The state is a copy of the state
+// object.
+type InterpreterHandler func(ctx InterpreterContext, env interface{}, state interface{}) error
+
+// InterpreterHook is a transition hook run by an Interpreter. The state is a copy of the state object.
+type InterpreterHook func(ctx InterpreterContext, env interface{}, state interface{}, transition InterpreterTransition) error
+
+// InterpreterTransition describes a transition of an Interpreter.
+type InterpreterTransition = fsmruntime.Transition[string, string]
+
+// InterpreterSnapshot is a serializable copy of an Interpreter's state, which Restore resumes from. Its State points to
+// a pointer to a copy of the state object.
+type InterpreterSnapshot = fsmruntime.Snapshot[string, string, interface{}]
+
+// InterpreterContext is passed to an Interpreter's handlers, actions and guards. Events triggered through it are queued
+// and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type InterpreterContext interface {
+	Context() context.Context
+	Trigger(event string, payload interface{}) error
+}
+
+// Interpreter runs a Generator's definition directly, without generating code, for machines that are only known at
+// runtime. It runs on the same engine as generated machines, so it behaves identically: the methods of the embedded
+// runtime machine are promoted, with states and events named by strings, and Trigger checks that the payload is of the
+// event's object type where the definition declares it with a value rather than a type name. Handlers, actions, guards
+// and hooks are looked up by name when they run, and a missing guard never passes.
+type Interpreter struct {
+	*fsmruntime.Machine[string, string, interface{}]
+
+	// Actions are the actions of each event, keyed by event name.
+	Actions map[string]InterpreterAction
+	// Guards are the guards of each event, keyed by event name and then guard name.
+	Guards map[string]map[string]InterpreterGuard
+	// OnState are the entry handlers of each state, keyed by state name.
+	OnState map[string]InterpreterHandler
+	// OnExit are the exit handlers of each state, keyed by state name.
+	OnExit map[string]InterpreterHandler
+	// Hooks are the transition hooks, keyed by hook name.
+	Hooks map[string]InterpreterHook
+	// CloneState optionally returns a pointer to a copy of the state object. When set, the state object is restored
+	// from the copy if an action fails, so a failed transition leaves no trace.
+	CloneState func(state interface{}) interface{}
+
+	gen    *Generator
+	env    interface{}
+	events map[string]*Event
+	clone  interface{}
+}
+
+// Interpreter validates the definition and returns an interpreter running it, in its initial configuration. The state
+// must be a pointer to the state object, and the interpreter's Clock defaults to the environment's clock if it
+// implements runtime.ClockProvider. The definition must not be modified while the interpreter is in use.
+func (gen *Generator) Interpreter(state interface{}, env interface{}) (*Interpreter, error) {
+	err := gen.Validate()
+	if err != nil {
+		return nil, err
+	}
+	value := reflect.ValueOf(state)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil, fmt.Errorf("%s interpreter state must be a non-nil pointer, got %T", gen.Name, state)
+	}
+	if typ := gen.stateObj.typ; typ != nil && value.Type() != reflect.PtrTo(typ) {
+		return nil, fmt.Errorf("%s interpreter state must be a *%s, got %T", gen.Name, typ, state)
+	}
+	interp := &Interpreter{
+		Actions: map[string]InterpreterAction{},
+		Guards:  map[string]map[string]InterpreterGuard{},
+		OnState: map[string]InterpreterHandler{},
+		OnExit:  map[string]InterpreterHandler{},
+		Hooks:   map[string]InterpreterHook{},
+		gen:     gen,
+		env:     env,
+		events:  map[string]*Event{},
+	}
+	for _, event := range gen.Events {
+		if _, ok := interp.events[event.Name]; !ok {
+			interp.events[event.Name] = event
+			interp.Guards[event.Name] = map[string]InterpreterGuard{}
+		}
+	}
+	interp.Machine = fsmruntime.NewMachine(gen.definition(), &state, fsmruntime.Handlers[string, string]{
+		Enter:     interp.enterState,
+		Exit:      interp.exitState,
+		Hooks:     interp.runTransitionHooks,
+		Event:     interp.eventFunc,
+		Automatic: interp.triggerAutomatic,
+	})
+	interp.Machine.CloneState = interp.cloneState
+	if provider, ok := env.(fsmruntime.ClockProvider); ok {
+		interp.Clock = provider.Clock()
+	}
+	return interp, nil
+}
+
+// definition returns the runtime definition of the state machine, as generated for a compiled machine.
+func (gen *Generator) definition() *fsmruntime.Definition[string, string] {
+	tg := &tmplGenerator{Generator: gen}
+	def := &fsmruntime.Definition[string, string]{
+		Name:               gen.Name,
+		States:             append([]string{}, gen.States...),
+		Transitions:        tg.TransitionMap(),
+		Internal:           map[string]map[string]bool{},
+		Parents:            tg.ParentMap(),
+		Initial:            tg.InitialMap(),
+		Regions:            tg.RegionMap(),
+		Order:              map[string]int{},
+		Final:              map[string]bool{},
+		Histories:          map[string]fsmruntime.History[string]{},
+		Delayed:            map[string][]fsmruntime.Delay[string]{},
+		Completions:        map[string][]string{},
+		MaxChainLength:     tg.ChainLimit(),
+		CommitBeforeAction: gen.CommitBeforeAction,
+	}
+	switch gen.Concurrency {
+	case ConcurrencyMutex:
+		def.Concurrency = fsmruntime.ConcurrencyMutex
+	case ConcurrencyActor:
+		def.Concurrency = fsmruntime.ConcurrencyActor
+	}
+	for _, event := range tg.DistinctEvents() {
+		def.Events = append(def.Events, event.Name)
+	}
+	for state, events := range tg.InternalMap() {
+		def.Internal[state] = map[string]bool{}
+		for _, event := range events {
+			def.Internal[state][event] = true
+		}
+	}
+	for i, state := range tg.DocumentOrder() {
+		def.Order[state] = i
+	}
+	for state, final := range gen.FinalStates {
+		def.Final[state] = final
+	}
+	for _, history := range gen.Histories {
+		def.Histories[history.Name] = fsmruntime.History[string]{Parent: history.Parent, Default: history.Default, Deep: history.Deep}
+	}
+	for state, events := range tg.DelayedFrom() {
+		for _, event := range events {
+			def.Delayed[state] = append(def.Delayed[state], fsmruntime.Delay[string]{Event: event.Name, After: event.Delay})
+		}
+	}
+	for state, events := range tg.CompletionFrom() {
+		for _, event := range events {
+			def.Completions[state] = append(def.Completions[state], event.Name)
+		}
+	}
+	for _, rule := range gen.rules() {
+		def.Rules = append(def.Rules, fsmruntime.TransitionRule[string, string]{From: rule.From, Event: rule.Event, To: rule.To, Guard: rule.Guard, Internal: rule.Internal})
+	}
+	return def
+}
+
+// StateObject returns the pointer to the state object.
+func (interp *Interpreter) StateObject() interface{} {
+	return *interp.Machine.State
+}
+
+// Snapshot returns a serializable copy of the interpreter's active configuration, recorded history, pending delayed
+// transitions and state object.
+func (interp *Interpreter) Snapshot() InterpreterSnapshot {
+	snapshot := interp.Machine.Snapshot()
+	state := copyState(*snapshot.State)
+	snapshot.State = &state
+	return snapshot
+}
+
+// Restore resumes the interpreter from the supplied snapshot without running any handlers, as for a generated machine.
+// If the snapshot has a state object, it must be a pointer of the same type as the interpreter's. To decode a snapshot
+// from JSON, set its State to a pointer to a new state object first.
+func (interp *Interpreter) Restore(snapshot InterpreterSnapshot) error {
+	if snapshot.State != nil && reflect.TypeOf(*snapshot.State) != reflect.TypeOf(*interp.Machine.State) {
+		return fmt.Errorf("%s snapshot state must be a %T, got %T", interp.gen.Name, *interp.Machine.State, *snapshot.State)
+	}
+	return interp.Machine.Restore(snapshot)
+}
+
+// copyState returns a pointer to a shallow copy of the object the supplied pointer points to.
+func copyState(state interface{}) interface{} {
+	value := reflect.ValueOf(state)
+	clone := reflect.New(value.Type().Elem())
+	clone.Elem().Set(value.Elem())
+	return clone.Interface()
+}
+
+// stateValue returns a copy of the state object, as passed to handlers and guards.
+func (interp *Interpreter) stateValue() interface{} {
+	return reflect.ValueOf(*interp.Machine.State).Elem().Interface()
+}
+
+// cloneState calls CloneState, remembering the copy so that a failed transition can restore the state object in
+// place once the machine has replaced it with the copy.
+func (interp *Interpreter) cloneState(state *interface{}) *interface{} {
+	if interp.CloneState == nil {
+		return nil
+	}
+	clone := interp.CloneState(*state)
+	interp.clone = clone
+	return &clone
+}
+
+// restoreState copies the state object restored from CloneState into the original state object, so the pointer the
+// interpreter was created with always holds the current state.
+func (interp *Interpreter) restoreState(original interface{}) {
+	current := *interp.Machine.State
+	if interp.clone == nil || current != interp.clone || current == original {
+		return
+	}
+	reflect.ValueOf(original).Elem().Set(reflect.ValueOf(current).Elem())
+	*interp.Machine.State = original
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (interp *Interpreter) eventFunc(event string, payload interface{}) (func(ctx context.Context) error, error) {
+	decl, ok := interp.events[event]
+	if !ok {
+		return nil, fmt.Errorf("unknown event %s", event)
+	}
+	if decl.ObjName != nil && reflect.TypeOf(payload) != decl.ObjName {
+		return nil, fmt.Errorf("invalid payload for event %s: expected %s, got %T", event, decl.ObjName, payload)
+	}
+	return func(ctx context.Context) error {
+		return interp.trigger(ctx, event, payload)
+	}, nil
+}
+
+// triggerAutomatic processes the supplied delayed or completion event with a zero value event object.
+func (interp *Interpreter) triggerAutomatic(ctx context.Context, event string) error {
+	var ev interface{}
+	if typ := interp.events[event].ObjName; typ != nil {
+		ev = reflect.Zero(typ).Interface()
+	}
+	return interp.trigger(ctx, event, ev)
+}
+
+func (interp *Interpreter) trigger(ctx context.Context, event string, ev interface{}) error {
+	guardCtx := interp.newContext(ctx)
+	resolve := func(from, target string) (string, error) {
+		for _, decl := range interp.gen.Events {
+			if decl.Name != event || !decl.Guarded() || !sourceMatches(decl, from) {
+				continue
+			}
+			for _, branch := range decl.Branches {
+				if interp.guard(guardCtx, event, branch.Guard, ev) {
+					return branch.ToState, nil
+				}
+			}
+			if decl.ToGuard != "" && interp.guard(guardCtx, event, decl.ToGuard, ev) {
+				return decl.ToState, nil
+			}
+			if decl.ToGuard != "" || decl.ToState == "" {
+				return "", fmt.Errorf("%w: no guard passed for %s from %s", fsmruntime.ErrGuardRejected, event, interp.CurrentState)
+			}
+		}
+		return target, nil
+	}
+	interp.clone = nil
+	original := *interp.Machine.State
+	err := interp.Fire(ctx, event, ev, resolve, func(ctx context.Context, transition InterpreterTransition) error {
+		action, ok := interp.Actions[event]
+		if !ok || action == nil {
+			return nil
+		}
+		return fsmruntime.WrapHandlerError(exportedName(event)+"Action", transition, action(interp.newContext(ctx), *interp.Machine.State, ev))
+	})
+	interp.restoreState(original)
+	return err
+}
+
+// sourceMatches returns whether from is the state the event declaration was found on, which is the empty state for
+// events from any state.
+func sourceMatches(event *Event, from string) bool {
+	for _, state := range sources(event) {
+		if state == from {
+			return true
+		}
+	}
+	return false
+}
+
+// guard returns whether the named guard of the event passes. Missing guards never pass.
+func (interp *Interpreter) guard(ctx InterpreterContext, event, name string, ev interface{}) bool {
+	guard := interp.Guards[event][name]
+	return guard != nil && guard(ctx, interp.stateValue(), ev)
+}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition, in declaration order. A hook's states
+// match the transition's states and any of their ancestors.
+func (interp *Interpreter) runTransitionHooks(ctx context.Context, transition InterpreterTransition) error {
+	def := interp.Definition()
+	for _, hook := range interp.gen.Hooks {
+		fn := interp.Hooks[hook.Name]
+		if fn == nil || (hook.FromState != "" && !def.Within(transition.From, hook.FromState)) || (hook.ToState != "" && !def.Within(transition.To, hook.ToState)) {
+			continue
+		}
+		err := fn(interp.newContext(ctx), interp.env, interp.stateValue(), transition)
+		if err != nil {
+			return fsmruntime.WrapHandlerError(exportedName(hook.Name)+"Hook", transition, err)
+		}
+	}
+	return nil
+}
+
+func (interp *Interpreter) exitState(ctx context.Context, transition InterpreterTransition, state string) error {
+	handler := interp.OnExit[state]
+	if handler == nil {
+		return nil
+	}
+	return fsmruntime.WrapHandlerError("OnExit"+exportedName(state), transition, handler(interp.newContext(ctx), interp.env, interp.stateValue()))
+}
+
+func (interp *Interpreter) enterState(ctx context.Context, transition InterpreterTransition, state string) error {
+	handler := interp.OnState[state]
+	if handler == nil {
+		return nil
+	}
+	return fsmruntime.WrapHandlerError("OnState"+exportedName(state), transition, handler(interp.newContext(ctx), interp.env, interp.stateValue()))
+}
+
+type interpreterContext struct {
+	ctx    context.Context
+	interp *Interpreter
+}
+
+func (interp *Interpreter) newContext(ctx context.Context) InterpreterContext {
+	return &interpreterContext{ctx: ctx, interp: interp}
+}
+
+func (ctx *interpreterContext) Context() context.Context {
+	return ctx.ctx
+}
+
+// Trigger queues the event, to be processed once the current event and any events queued before it have completed.
+// Errors are returned by the outermost trigger.
+func (ctx *interpreterContext) Trigger(event string, payload interface{}) error {
+	fn, err := ctx.interp.eventFunc(event, payload)
+	if err != nil {
+		return err
+	}
+	return ctx.interp.Process(func() error {
+		return fn(ctx.ctx)
+	})
+}
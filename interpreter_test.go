@@ -0,0 +1,129 @@
+package fsmgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	fsmruntime "github.com/snikch/go-fsmgen/runtime"
+	"gotest.tools/assert"
+)
+
+type counterState struct {
+	Count int
+}
+
+func newCounterGenerator() *Generator {
+	gen := New("counter", counterState{}, testEnv{}, "idle", "counting", "full")
+	gen.AddEvent(NewEvent("add", testEvent{}).From("idle", "counting").Branch("full", "full").To("counting"))
+	gen.AddEvent(NewEvent("reset", "Reset").FromAny().To("idle"))
+	return gen
+}
+
+func TestInterpreter(t *testing.T) {
+	ctx := context.Background()
+	state := &counterState{}
+	interp, err := newCounterGenerator().Interpreter(state, testEnv{})
+	assert.NilError(t, err)
+	entered := []string{}
+	interp.OnState["counting"] = func(ctx InterpreterContext, env interface{}, state interface{}) error {
+		entered = append(entered, "counting")
+		return nil
+	}
+	interp.Actions["add"] = func(ctx InterpreterContext, state interface{}, ev interface{}) error {
+		state.(*counterState).Count++
+		return nil
+	}
+	interp.Guards["add"]["full"] = func(ctx InterpreterContext, state interface{}, ev interface{}) bool {
+		return state.(counterState).Count >= 2
+	}
+	assert.NilError(t, interp.Start(ctx))
+	assert.NilError(t, interp.Trigger(ctx, "add", testEvent{}))
+	assert.NilError(t, interp.Trigger(ctx, "add", testEvent{}))
+	assert.Equal(t, "counting", interp.CurrentState)
+	assert.NilError(t, interp.Trigger(ctx, "add", testEvent{}))
+	assert.Equal(t, "full", interp.CurrentState)
+	assert.Equal(t, 3, state.Count)
+	assert.DeepEqual(t, []string{"counting", "counting"}, entered)
+
+	// Events declared with a type name accept any payload.
+	assert.NilError(t, interp.Trigger(ctx, "reset", nil))
+	assert.Equal(t, "idle", interp.CurrentState)
+	assert.Error(t, interp.Trigger(ctx, "add", "one"), "invalid payload for event add: expected fsmgen.testEvent, got string")
+	assert.Error(t, interp.Trigger(ctx, "remove", nil), "unknown event remove")
+}
+
+func TestInterpreterQueue(t *testing.T) {
+	ctx := context.Background()
+	interp, err := newCounterGenerator().Interpreter(&counterState{}, testEnv{})
+	assert.NilError(t, err)
+	interp.OnState["full"] = func(ctx InterpreterContext, env interface{}, state interface{}) error {
+		return ctx.Trigger("reset", nil)
+	}
+	interp.Guards["add"]["full"] = func(ctx InterpreterContext, state interface{}, ev interface{}) bool {
+		return true
+	}
+	assert.NilError(t, interp.Trigger(ctx, "add", testEvent{}))
+	assert.Equal(t, "idle", interp.CurrentState)
+}
+
+func TestInterpreterCloneState(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("failed")
+	state := &counterState{}
+	interp, err := newCounterGenerator().Interpreter(state, testEnv{})
+	assert.NilError(t, err)
+	interp.CloneState = func(state interface{}) interface{} {
+		clone := *state.(*counterState)
+		return &clone
+	}
+	interp.Actions["add"] = func(ctx InterpreterContext, state interface{}, ev interface{}) error {
+		state.(*counterState).Count++
+		return failure
+	}
+	err = interp.Trigger(ctx, "add", testEvent{})
+	assert.Error(t, err, "AddAction failed on add from idle to counting: failed")
+	// The state object is restored in place, so the pointer the interpreter was created with remains current.
+	assert.Equal(t, 0, state.Count)
+	assert.Equal(t, interface{}(state), interp.StateObject())
+}
+
+func TestInterpreterSnapshot(t *testing.T) {
+	ctx := context.Background()
+	state := &counterState{}
+	interp, err := newCounterGenerator().Interpreter(state, testEnv{})
+	assert.NilError(t, err)
+	assert.NilError(t, interp.Trigger(ctx, "add", testEvent{}))
+	snapshot := interp.Snapshot()
+	state.Count = 5
+	assert.DeepEqual(t, &counterState{}, *snapshot.State)
+
+	var other interface{} = &testState{}
+	assert.Error(t, interp.Restore(InterpreterSnapshot{Configuration: []string{"idle"}, State: &other}),
+		"counter snapshot state must be a *fsmgen.counterState, got *fsmgen.testState")
+	assert.NilError(t, interp.Restore(snapshot))
+	assert.Equal(t, "counting", interp.Current())
+	assert.Equal(t, 0, interp.StateObject().(*counterState).Count)
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	gen := newCounterGenerator()
+	_, err := gen.Interpreter(counterState{}, testEnv{})
+	assert.Error(t, err, "counter interpreter state must be a non-nil pointer, got fsmgen.counterState")
+	_, err = gen.Interpreter(&testState{}, testEnv{})
+	assert.Error(t, err, "counter interpreter state must be a *fsmgen.counterState, got *fsmgen.testState")
+
+	gen.AddEvent(NewEvent("add", testEvent{}).From("full").To("missing"))
+	_, err = gen.Interpreter(&counterState{}, testEnv{})
+	var verr *ValidationError
+	assert.Assert(t, errors.As(err, &verr))
+
+	// Missing guards never pass.
+	gen = New("guarded", counterState{}, testEnv{}, "idle", "busy")
+	gen.AddEvent(NewEvent("work", testEvent{}).From("idle").Guard("ready").To("busy"))
+	interp, err := gen.Interpreter(&counterState{}, testEnv{})
+	assert.NilError(t, err)
+	err = interp.Trigger(context.Background(), "work", testEvent{})
+	assert.Error(t, err, "guard rejected transition: no guard passed for work from idle")
+	assert.Assert(t, errors.Is(err, fsmruntime.ErrGuardRejected))
+}
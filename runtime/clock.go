@@ -0,0 +1,135 @@
+package runtime
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock schedules the delayed transitions of generated machines. Inject a FakeClock to control time in tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f on its own goroutine once d has elapsed, unless the returned timer is stopped first.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled by a Clock.
+type Timer interface {
+	// Stop prevents the timer from firing, returning false if it has already fired or been stopped.
+	Stop() bool
+}
+
+// ClockProvider may be implemented by a machine's environment to supply the machine's default Clock.
+type ClockProvider interface {
+	Clock() Clock
+}
+
+// SystemClock is a Clock backed by the time package.
+type SystemClock struct{}
+
+// Now returns the current time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f on its own goroutine once d has elapsed.
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock is a Clock that only moves when advanced, so tests can trigger delayed transitions deterministically
+// without sleeping. It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    int
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set to the supplied time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (clock *FakeClock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.now
+}
+
+// AfterFunc schedules f to be called once the clock has been advanced by d.
+func (clock *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.seq++
+	timer := &fakeTimer{clock: clock, when: clock.now.Add(d), seq: clock.seq, f: f}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward by d, calling every timer that becomes due in the order they are due, on the calling
+// goroutine. Timers scheduled by those calls also fire if they are due before the clock reaches its new time.
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	end := clock.now.Add(d)
+	clock.mu.Unlock()
+	for {
+		clock.mu.Lock()
+		timer := clock.next(end)
+		if timer == nil {
+			clock.now = end
+			clock.mu.Unlock()
+			return
+		}
+		clock.now = timer.when
+		clock.mu.Unlock()
+		timer.f()
+	}
+}
+
+// PendingTimers returns the number of timers that have neither fired nor been stopped.
+func (clock *FakeClock) PendingTimers() int {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return len(clock.timers)
+}
+
+// next removes and returns the earliest timer due at or before end, or nil if there is none. The clock must be locked.
+func (clock *FakeClock) next(end time.Time) *fakeTimer {
+	sort.Slice(clock.timers, func(i, j int) bool {
+		a, b := clock.timers[i], clock.timers[j]
+		if a.when.Equal(b.when) {
+			return a.seq < b.seq
+		}
+		return a.when.Before(b.when)
+	})
+	if len(clock.timers) == 0 || clock.timers[0].when.After(end) {
+		return nil
+	}
+	timer := clock.timers[0]
+	clock.timers = clock.timers[1:]
+	return timer
+}
+
+// fakeTimer is a timer scheduled on a FakeClock.
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	seq   int
+	f     func()
+}
+
+// Stop removes the timer from the clock, returning false if it has already fired or been stopped.
+func (timer *fakeTimer) Stop() bool {
+	clock := timer.clock
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	for i, pending := range clock.timers {
+		if pending == timer {
+			clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 11, 13, 6, 35, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	fired := []string{}
+	clock.AfterFunc(2*time.Second, func() {
+		fired = append(fired, "second")
+		clock.AfterFunc(time.Second, func() {
+			fired = append(fired, "chained")
+		})
+	})
+	clock.AfterFunc(time.Second, func() {
+		fired = append(fired, "first")
+	})
+	stopped := clock.AfterFunc(time.Second, func() {
+		fired = append(fired, "stopped")
+	})
+	assert.Assert(t, stopped.Stop())
+	assert.Assert(t, !stopped.Stop())
+
+	clock.Advance(time.Second - time.Nanosecond)
+	assert.Equal(t, 0, len(fired))
+	clock.Advance(2*time.Second + time.Nanosecond)
+	assert.DeepEqual(t, []string{"first", "second", "chained"}, fired)
+	assert.Equal(t, start.Add(3*time.Second), clock.Now())
+	assert.Equal(t, 0, clock.PendingTimers())
+}
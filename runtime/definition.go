@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"time"
+)
+
+// Concurrency defines how a Machine synchronises access from multiple goroutines.
+type Concurrency int
+
+const (
+	// ConcurrencyNone performs no synchronisation, so the machine must only be used from one goroutine at a time.
+	// Machines with delayed transitions also serialise events with a mutex, as their timers may fire on other
+	// goroutines, but still queue events triggered while another is processed rather than waiting for it.
+	ConcurrencyNone Concurrency = iota
+	// ConcurrencyMutex serialises events with a mutex, so every method is safe to call from any goroutine.
+	ConcurrencyMutex
+	// ConcurrencyActor processes every event on the goroutine calling Run, which must be running for any trigger to
+	// complete. Every method is safe to call from any goroutine.
+	ConcurrencyActor
+)
+
+// Definition is the structure of a state machine, shared by every Machine running it. Maps are keyed by state, and
+// the zero state stands for "any state" where noted. A definition must not be modified once a Machine uses it.
+type Definition[S, E comparable] struct {
+	// Name is the machine's name, used in error messages.
+	Name string
+	// States are every state in declaration order, the first of which is the initial state.
+	States []S
+	// Events are every event in declaration order.
+	Events []E
+	// Transitions maps each state, or the zero state for events from any state, to the target of each event declared
+	// on it.
+	Transitions map[S]map[E]S
+	// Internal marks the events declared on each state, or the zero state, that are internal transitions.
+	Internal map[S]map[E]bool
+	// Parents maps each child state to the compound state containing it.
+	Parents map[S]S
+	// Initial maps each compound state to its initial child.
+	Initial map[S]S
+	// Regions maps each parallel state to its orthogonal regions.
+	Regions map[S][]S
+	// Order is the position of every state in document order, where each state is followed by its descendants.
+	Order map[S]int
+	// Final marks the final states.
+	Final map[S]bool
+	// Histories defines each history pseudo-state, which may be the target of a transition but is never active.
+	Histories map[S]History[S]
+	// Delayed lists the delayed transitions scheduled on entry to each state.
+	Delayed map[S][]Delay[E]
+	// Completions lists the completion events queued once each state completes.
+	Completions map[S][]E
+	// Rules describes every declared transition, in declaration order.
+	Rules []TransitionRule[S, E]
+	// MaxChainLength is the default MaxChainLength of machines running the definition.
+	MaxChainLength int
+	// CommitBeforeAction makes machines change state before running the event's action, rather than only once the
+	// action succeeds.
+	CommitBeforeAction bool
+	// Concurrency defines how machines running the definition synchronise access from multiple goroutines.
+	Concurrency Concurrency
+}
+
+// History defines a history pseudo-state, which re-enters its parent compound state in the configuration it was in
+// when last exited, or its default state if it has not been exited yet.
+type History[S comparable] struct {
+	Parent  S
+	Default S
+	// Deep histories record every active descendant of the parent, rather than only its active children.
+	Deep bool
+}
+
+// Delay is a delayed transition, triggered once its state has been active for the duration.
+type Delay[E comparable] struct {
+	Event E
+	After time.Duration
+}
+
+// Transition describes a transition of a Machine.
+type Transition[S, E comparable] struct {
+	From  S
+	Event E
+	To    S
+}
+
+// TransitionRule describes a transition declared by a machine's definition.
+type TransitionRule[S, E comparable] struct {
+	// From is the state the event is declared on, or the zero state for events that may occur from any state.
+	From  S
+	Event E
+	// To is the target state, which is From for internal transitions.
+	To S
+	// Guard is the name of the guard that must pass for the transition to be taken, or empty if it is unguarded.
+	Guard string
+	// Internal is true for internal transitions, which run the event's action without exiting or entering any state.
+	Internal bool
+}
+
+// Within returns whether state is the ancestor state or one of its descendants.
+func (def *Definition[S, E]) Within(state, ancestor S) bool {
+	var zero S
+	for ; state != zero; state = def.Parents[state] {
+		if state == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// lineage returns the supplied state followed by each of its ancestors, innermost first.
+func (def *Definition[S, E]) lineage(state S) []S {
+	var zero S
+	states := []S{}
+	for ; state != zero; state = def.Parents[state] {
+		states = append(states, state)
+	}
+	return states
+}
+
+// root returns the top-level state containing the supplied state.
+func (def *Definition[S, E]) root(state S) S {
+	var zero S
+	for def.Parents[state] != zero {
+		state = def.Parents[state]
+	}
+	return state
+}
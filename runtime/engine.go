@@ -0,0 +1,371 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// step is a transition selected for an event.
+type step[S, E comparable] struct {
+	transition Transition[S, E]
+	// domain is the innermost state containing the transition that is not exited, or the zero state if every active
+	// state is exited.
+	domain  S
+	entries []S
+	// internal is set for internal transitions, which exit and enter no states. Their domain is the state the event
+	// was found on, so that the transition is only taken once however many of its descendants are active.
+	internal bool
+}
+
+// selectTransitions selects the transitions taken by the supplied event. Each active leaf state is checked in document
+// order, looking for the event on the leaf, then on each of its ancestors, innermost first, and finally on the events
+// that may occur from any state. A transition is skipped if it would exit a state exited by a transition selected
+// before it. The resolve function returns each transition's target, evaluating any guards. A leaf whose guards all
+// fail has no enabled transition, so the other regions of a parallel state still take theirs, and the guard's error is
+// only returned if no transition is selected at all. Every event is rejected once the machine is done.
+func (machine *Machine[S, E, T]) selectTransitions(event E, resolve func(from, target S) (S, error)) ([]step[S, E], error) {
+	if machine.isDone() {
+		return nil, fmt.Errorf("%w: %v cannot be triggered from final state %v", ErrMachineDone, event, machine.CurrentState)
+	}
+	def := machine.def
+	var zero S
+	steps := []step[S, E]{}
+	var rejected error
+	for _, leaf := range machine.leaves() {
+		from, source, ok := machine.findTransition(leaf, event)
+		if !ok {
+			continue
+		}
+		target := def.Transitions[from][event]
+		if resolve != nil {
+			var err error
+			target, err = resolve(from, target)
+			if errors.Is(err, ErrGuardRejected) {
+				if rejected == nil {
+					rejected = err
+				}
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		targets := []S{target}
+		if history, ok := def.Histories[target]; ok {
+			targets = machine.history[target]
+			if len(targets) == 0 {
+				targets = []S{history.Default}
+			}
+		}
+		domain := machine.domain(source, target)
+		internal := def.Internal[from][event]
+		if internal {
+			domain = source
+		}
+		preempted := false
+		for _, step := range steps {
+			if domain == zero || step.domain == zero || def.Within(domain, step.domain) || def.Within(step.domain, domain) {
+				preempted = true
+				break
+			}
+		}
+		if preempted {
+			continue
+		}
+		if internal {
+			steps = append(steps, step[S, E]{
+				transition: Transition[S, E]{From: leaf, Event: event, To: leaf},
+				domain:     domain,
+				internal:   true,
+			})
+			continue
+		}
+		top := targets[0]
+		for def.Parents[top] != domain {
+			top = def.Parents[top]
+		}
+		entries := machine.entries(nil, top, targets)
+		steps = append(steps, step[S, E]{
+			transition: Transition[S, E]{From: leaf, Event: event, To: machine.innermost(domain, entries)},
+			domain:     domain,
+			entries:    entries,
+		})
+	}
+	if len(steps) == 0 && rejected != nil {
+		return nil, rejected
+	}
+	if len(steps) == 0 {
+		return nil, &InvalidTransitionError{From: name(machine.CurrentState), Event: name(event)}
+	}
+	return steps, nil
+}
+
+// findTransition returns the state the supplied event is declared on for the active leaf state, which is the zero
+// state for events from any state, and the state the transition leaves from.
+func (machine *Machine[S, E, T]) findTransition(leaf S, event E) (from, source S, ok bool) {
+	var zero S
+	for _, state := range machine.def.lineage(leaf) {
+		if _, ok := machine.def.Transitions[state][event]; ok {
+			return state, state, true
+		}
+	}
+	if _, ok := machine.def.Transitions[zero][event]; ok {
+		return zero, leaf, true
+	}
+	return zero, zero, false
+}
+
+// domain returns the innermost compound state that properly contains both source and target, which is neither exited
+// nor entered by a transition between them. It returns the zero state if there is none.
+func (machine *Machine[S, E, T]) domain(source, target S) S {
+	def := machine.def
+	var zero S
+	for state := def.Parents[source]; state != zero; state = def.Parents[state] {
+		if _, parallel := def.Regions[state]; !parallel && target != state && def.Within(target, state) {
+			return state
+		}
+	}
+	return zero
+}
+
+// entries appends the supplied state and the descendants entered along with it to states, in document order. Every
+// region of a parallel state is entered, and a compound state enters the child containing one of the targets, or its
+// initial child if none of the targets are its descendants.
+func (machine *Machine[S, E, T]) entries(states []S, state S, targets []S) []S {
+	def := machine.def
+	states = append(states, state)
+	if regions, ok := def.Regions[state]; ok {
+		for _, region := range regions {
+			states = machine.entries(states, region, targets)
+		}
+		return states
+	}
+	child, ok := def.Initial[state]
+	if !ok {
+		return states
+	}
+	for _, target := range targets {
+		if target != state && def.Within(target, state) {
+			child = target
+			for def.Parents[child] != state {
+				child = def.Parents[child]
+			}
+			break
+		}
+	}
+	return machine.entries(states, child, targets)
+}
+
+// transitionStates returns the states exited by the supplied transitions in reverse document order, and the states
+// entered in document order.
+func (machine *Machine[S, E, T]) transitionStates(steps []step[S, E]) (exits, entries []S) {
+	var zero S
+	for i := len(machine.configuration) - 1; i >= 0; i-- {
+		state := machine.configuration[i]
+		for _, step := range steps {
+			if step.internal {
+				continue
+			}
+			if step.domain == zero || (state != step.domain && machine.def.Within(state, step.domain)) {
+				exits = append(exits, state)
+				break
+			}
+		}
+	}
+	for _, step := range steps {
+		entries = append(entries, step.entries...)
+	}
+	machine.sortStates(entries)
+	return exits, entries
+}
+
+// commit replaces the exited states with the entered states in the active configuration, and updates CurrentState.
+// The machine is done once the configuration is a top-level final state.
+func (machine *Machine[S, E, T]) commit(exits, entries []S) {
+	var zero S
+	machine.recordHistory(exits)
+	machine.configuration = machine.next(exits, entries)
+	machine.CurrentState = machine.innermost(zero, machine.configuration)
+	if machine.def.Final[machine.CurrentState] && machine.def.Parents[machine.CurrentState] == zero && !machine.isDone() {
+		close(machine.done)
+	}
+}
+
+// next returns the active configuration in document order once the exited states are replaced with the entered states.
+func (machine *Machine[S, E, T]) next(exits, entries []S) []S {
+	exited := map[S]bool{}
+	for _, state := range exits {
+		exited[state] = true
+	}
+	configuration := []S{}
+	for _, state := range machine.configuration {
+		if !exited[state] {
+			configuration = append(configuration, state)
+		}
+	}
+	configuration = append(configuration, entries...)
+	machine.sortStates(configuration)
+	return configuration
+}
+
+// recordHistory records the active configuration below each exited state that has a history pseudo-state. It must be
+// called before the exited states are removed from the configuration.
+func (machine *Machine[S, E, T]) recordHistory(exits []S) {
+	if len(machine.def.Histories) == 0 {
+		return
+	}
+	exited := map[S]bool{}
+	for _, state := range exits {
+		exited[state] = true
+	}
+	leaves := machine.leaves()
+	for name, history := range machine.def.Histories {
+		if !exited[history.Parent] {
+			continue
+		}
+		recorded := []S{}
+		states := machine.configuration
+		if history.Deep {
+			states = leaves
+		}
+		for _, state := range states {
+			if (history.Deep && state != history.Parent && machine.def.Within(state, history.Parent)) || (!history.Deep && machine.def.Parents[state] == history.Parent) {
+				recorded = append(recorded, state)
+			}
+		}
+		machine.history[name] = recorded
+	}
+}
+
+// innermost returns the innermost of the supplied states, in document order, that contains every other state below
+// outer: the single leaf state, or a parallel state while more than one of its descendants is a leaf.
+func (machine *Machine[S, E, T]) innermost(outer S, states []S) S {
+	for _, state := range states {
+		if machine.def.Parents[state] != outer {
+			continue
+		}
+		outer = state
+		if _, parallel := machine.def.Regions[state]; parallel {
+			break
+		}
+	}
+	return outer
+}
+
+// leaves returns the active states without an active child, in document order.
+func (machine *Machine[S, E, T]) leaves() []S {
+	parents := map[S]bool{}
+	for _, state := range machine.configuration {
+		parents[machine.def.Parents[state]] = true
+	}
+	leaves := []S{}
+	for _, state := range machine.configuration {
+		if !parents[state] {
+			leaves = append(leaves, state)
+		}
+	}
+	return leaves
+}
+
+// sortStates sorts the supplied states into document order.
+func (machine *Machine[S, E, T]) sortStates(states []S) {
+	sort.Slice(states, func(i, j int) bool {
+		return machine.def.Order[states[i]] < machine.def.Order[states[j]]
+	})
+}
+
+// currentListeners returns the listeners added so far, which may be added to by the listeners themselves.
+func (machine *Machine[S, E, T]) currentListeners() []Listener[S, E] {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.listeners
+}
+
+// notifyTransition calls every listener's OnTransition method.
+func (machine *Machine[S, E, T]) notifyTransition(ctx context.Context, transition Transition[S, E], payload interface{}) {
+	for _, listener := range machine.currentListeners() {
+		listener.OnTransition(ctx, transition.From, transition.To, transition.Event, payload)
+	}
+}
+
+// notifyRejected calls every listener's OnRejected method.
+func (machine *Machine[S, E, T]) notifyRejected(ctx context.Context, event E, payload interface{}, err error) {
+	for _, listener := range machine.currentListeners() {
+		listener.OnRejected(ctx, machine.CurrentState, event, payload, err)
+	}
+}
+
+// notifyError calls every listener's OnError method if err is not nil, and returns err.
+func (machine *Machine[S, E, T]) notifyError(ctx context.Context, transition Transition[S, E], payload interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, listener := range machine.currentListeners() {
+		listener.OnError(ctx, transition.From, transition.To, transition.Event, payload, err)
+	}
+	return err
+}
+
+// complete queues the completion events of every active state completed by entering the supplied states, children
+// before their parents. Completion events are processed after the entry handlers of the transition that completed the
+// state, and are skipped if the state is no longer active by then.
+func (machine *Machine[S, E, T]) complete(ctx context.Context, entries []S) {
+	if len(machine.def.Completions) == 0 {
+		return
+	}
+	for i := len(machine.configuration) - 1; i >= 0; i-- {
+		state := machine.configuration[i]
+		if !machine.completedBy(state, entries) {
+			continue
+		}
+		for _, event := range machine.def.Completions[state] {
+			event := event
+			machine.enqueue(func() error {
+				if !machine.isIn(state) {
+					return nil
+				}
+				return machine.automatic(ctx, event)
+			})
+		}
+	}
+}
+
+// completedBy returns whether the supplied state has completed, and one of the entered states is a final state below
+// it, so that it was completed by entering them.
+func (machine *Machine[S, E, T]) completedBy(state S, entries []S) bool {
+	for _, entry := range entries {
+		if machine.def.Final[entry] && entry != state && machine.def.Within(entry, state) {
+			return machine.completed(state)
+		}
+	}
+	return false
+}
+
+// completed returns whether the supplied state has completed: a compound state whose active child is a final state, or
+// a parallel state whose regions have all completed.
+func (machine *Machine[S, E, T]) completed(state S) bool {
+	if regions, ok := machine.def.Regions[state]; ok {
+		for _, region := range regions {
+			if !machine.completed(region) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, active := range machine.configuration {
+		if machine.def.Final[active] && machine.def.Parents[active] == state {
+			return true
+		}
+	}
+	return false
+}
+
+// automatic processes the supplied delayed or completion event with a zero value event object.
+func (machine *Machine[S, E, T]) automatic(ctx context.Context, event E) error {
+	if machine.handlers.Automatic == nil {
+		return fmt.Errorf("unknown automatic event %v", event)
+	}
+	return machine.handlers.Automatic(ctx, event)
+}
@@ -0,0 +1,67 @@
+// Package runtime contains the types shared by every generated state machine, so that callers can handle errors from
+// any machine in the same way.
+package runtime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGuardRejected is returned when an event is valid from the current state but none of its guards passed, so there
+// was no target state to transition to.
+var ErrGuardRejected = errors.New("guard rejected transition")
+
+// ErrMaxChainLength is returned when more events are queued by handlers during a single trigger than the machine's
+// MaxChainLength allows, which usually indicates an infinite loop of automatic transitions.
+var ErrMaxChainLength = errors.New("maximum event chain length exceeded")
+
+// ErrMachineDone is returned when an event is triggered after the machine has entered a top-level final state, from
+// which it never transitions again.
+var ErrMachineDone = errors.New("machine is done")
+
+// ErrInvalidTransition matches every *InvalidTransitionError with errors.Is.
+var ErrInvalidTransition = errors.New("invalid transition")
+
+// InvalidTransitionError is returned when an event has no transition from any of the machine's active states.
+type InvalidTransitionError struct {
+	// From is the machine's current state when the event was rejected.
+	From string
+	// Event is the rejected event.
+	Event string
+}
+
+func (err *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s: no transition target from %s via %s", ErrInvalidTransition, err.From, err.Event)
+}
+
+// Is reports whether target is ErrInvalidTransition.
+func (err *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// HandlerError wraps an error returned by an action, entry or exit handler, or transition hook with the transition it
+// was handling, so that the failure can be located.
+type HandlerError struct {
+	// Handler is the name of the machine's field holding the handler, such as "PlayAction" or "OnStateLoading".
+	Handler string
+	// Event is the event being processed, which is empty for the entry handlers run by Start.
+	Event string
+	// From is the machine's current state before the transition.
+	From string
+	// To is the machine's current state once the transition completes.
+	To string
+	// Err is the error returned by the handler.
+	Err error
+}
+
+func (err *HandlerError) Error() string {
+	if err.Event == "" {
+		return fmt.Sprintf("%s failed entering %s: %v", err.Handler, err.To, err.Err)
+	}
+	return fmt.Sprintf("%s failed on %s from %s to %s: %v", err.Handler, err.Event, err.From, err.To, err.Err)
+}
+
+// Unwrap returns the error returned by the handler.
+func (err *HandlerError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,24 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestInvalidTransitionError(t *testing.T) {
+	var err error = &InvalidTransitionError{From: "paused", Event: "pause"}
+	assert.Error(t, err, "invalid transition: no transition target from paused via pause")
+	assert.Assert(t, errors.Is(err, ErrInvalidTransition))
+	assert.Assert(t, !errors.Is(err, ErrGuardRejected))
+}
+
+func TestHandlerError(t *testing.T) {
+	cause := errors.New("no file")
+	err := &HandlerError{Handler: "OnStateLoading", Event: "load", From: "init", To: "loading", Err: cause}
+	assert.Error(t, err, "OnStateLoading failed on load from init to loading: no file")
+	assert.Assert(t, errors.Is(err, cause))
+	err = &HandlerError{Handler: "OnStateInit", To: "init", Err: cause}
+	assert.Error(t, err, "OnStateInit failed entering init: no file")
+}
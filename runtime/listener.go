@@ -0,0 +1,31 @@
+package runtime
+
+import (
+	"context"
+)
+
+// Listener observes every event processed by a Machine, for cross-cutting concerns such as logging, metrics and
+// auditing. Listeners are called on the goroutine processing the event, and the payload is the event object, or nil
+// for the entry handlers run by Start.
+type Listener[S, E comparable] interface {
+	// OnTransition is called once the machine has changed state, before the entry handlers of the entered states run.
+	OnTransition(ctx context.Context, from, to S, event E, payload interface{})
+	// OnRejected is called when an event is rejected without changing state, because it has no transition from the
+	// active states, none of its guards passed or the machine is done.
+	OnRejected(ctx context.Context, from S, event E, payload interface{}, err error)
+	// OnError is called when an action, handler or hook fails while transitioning between the supplied states.
+	OnError(ctx context.Context, from, to S, event E, payload interface{}, err error)
+}
+
+// BaseListener implements Listener with methods that do nothing, so listeners can embed it and only implement the
+// methods they need.
+type BaseListener[S, E comparable] struct{}
+
+func (BaseListener[S, E]) OnTransition(ctx context.Context, from, to S, event E, payload interface{}) {
+}
+
+func (BaseListener[S, E]) OnRejected(ctx context.Context, from S, event E, payload interface{}, err error) {
+}
+
+func (BaseListener[S, E]) OnError(ctx context.Context, from, to S, event E, payload interface{}, err error) {
+}
@@ -0,0 +1,661 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handlers connects a Machine to the typed handlers of the generated machine wrapping it. Every function is optional.
+type Handlers[S, E comparable] struct {
+	// Enter runs the entry handler of the supplied state, returning its error wrapped by WrapHandlerError.
+	Enter func(ctx context.Context, transition Transition[S, E], state S) error
+	// Exit runs the exit handler of the supplied state, returning its error wrapped by WrapHandlerError.
+	Exit func(ctx context.Context, transition Transition[S, E], state S) error
+	// Hooks runs the transition hooks matching the supplied transition, in declaration order.
+	Hooks func(ctx context.Context, transition Transition[S, E]) error
+	// Event returns a function processing the supplied event, or an error if the payload is not of the event's object
+	// type. It is used by Trigger and Send.
+	Event func(event E, payload interface{}) (func(ctx context.Context) error, error)
+	// Automatic processes a delayed or completion event with a zero value event object.
+	Automatic func(ctx context.Context, event E) error
+}
+
+// Machine runs a Definition, implementing the transition engine shared by every generated machine: transition
+// selection, the event queue, handlers and hooks, listeners, timers, snapshots and concurrency. S is the state type, E
+// the event type and T the type of the state object.
+type Machine[S, E comparable, T any] struct {
+	// CurrentState is the innermost state containing every active state: the active leaf state, or a parallel state
+	// while its regions are active.
+	CurrentState S
+	State        *T
+
+	// MaxChainLength is the maximum number of events that handlers may queue during a single trigger. Zero disables
+	// the limit.
+	MaxChainLength int
+	// Clock schedules delayed transitions. Defaults to the system clock.
+	Clock Clock
+	// TimerError is called with the error returned by a delayed transition, as there is no caller to return it to.
+	TimerError func(event E, err error)
+	// CloneState optionally returns a copy of the state object. When set, the state object is restored from the copy
+	// if an action fails, so a failed transition leaves no trace. It is unused if the definition commits before the
+	// action.
+	CloneState func(state *T) *T
+
+	def           *Definition[S, E]
+	handlers      Handlers[S, E]
+	configuration []S
+	done          chan struct{}
+	history       map[S][]S
+	timers        map[S][]timer[E]
+	epochs        map[S]int
+	listeners     []Listener[S, E]
+	concurrency   Concurrency
+	mu            sync.Mutex
+	mailbox       chan request
+
+	// queueMu guards the event queue, and whether the machine is processing an event, so that events dispatched from
+	// other goroutines while an event is being processed can be queued behind it.
+	queueMu    sync.Mutex
+	queue      []func() error
+	processing bool
+
+	// view guards the fields read by the queries, which may be called from any goroutine, including from the
+	// machine's own handlers and listeners. It is only held while the machine changes state, never while a handler
+	// runs.
+	view sync.RWMutex
+	// viewState is the state object as of the last change of state, which Snapshot returns while the state object
+	// may be changed by an action.
+	viewState T
+}
+
+// request is an event sent to the goroutine running an actor machine. The done function is called once the event
+// and every event it queues have been processed.
+type request struct {
+	fn     func() error
+	done   func()
+	result chan error
+}
+
+// dispatchKey is the context key marking the contexts passed to the handlers of a machine.
+type dispatchKey struct {
+	machine interface{}
+}
+
+// timer is a delayed transition scheduled on the machine's Clock.
+type timer[E comparable] struct {
+	timer    Timer
+	event    E
+	deadline time.Time
+}
+
+// NewMachine returns a machine running the supplied definition in its initial configuration, without running any
+// handlers.
+func NewMachine[S, E comparable, T any](def *Definition[S, E], state *T, handlers Handlers[S, E]) *Machine[S, E, T] {
+	machine := &Machine[S, E, T]{
+		State:          state,
+		MaxChainLength: def.MaxChainLength,
+		Clock:          SystemClock{},
+		def:            def,
+		handlers:       handlers,
+		done:           make(chan struct{}),
+		history:        map[S][]S{},
+		timers:         map[S][]timer[E]{},
+		epochs:         map[S]int{},
+		concurrency:    def.Concurrency,
+	}
+	if machine.concurrency == ConcurrencyNone && len(def.Delayed) > 0 {
+		// Timers may fire on their own goroutine, so delayed transitions are always serialised with other events.
+		machine.concurrency = ConcurrencyMutex
+	}
+	if def.Concurrency == ConcurrencyActor {
+		machine.mailbox = make(chan request)
+	}
+	if len(def.States) > 0 {
+		initial := def.States[0]
+		machine.commit(nil, machine.entries(nil, def.root(initial), []S{initial}))
+	}
+	return machine
+}
+
+// Definition returns the definition the machine runs.
+func (machine *Machine[S, E, T]) Definition() *Definition[S, E] {
+	return machine.def
+}
+
+// lock locks the machine, unless it performs no synchronisation.
+func (machine *Machine[S, E, T]) lock() {
+	if machine.concurrency != ConcurrencyNone {
+		machine.mu.Lock()
+	}
+}
+
+func (machine *Machine[S, E, T]) unlock() {
+	if machine.concurrency != ConcurrencyNone {
+		machine.mu.Unlock()
+	}
+}
+
+// Start runs the entry handlers of the initially active states, outermost first, along with any events they trigger.
+// The delayed transitions of the initially active states are scheduled by Start, so it must be called for them to fire.
+func (machine *Machine[S, E, T]) Start(ctx context.Context) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		machine.view.Lock()
+		machine.startTimers(machine.configuration)
+		machine.view.Unlock()
+		machine.complete(ctx, machine.configuration)
+		transition := Transition[S, E]{To: machine.CurrentState}
+		return machine.notifyError(ctx, transition, nil, machine.enterStates(ctx, transition, machine.configuration))
+	})
+}
+
+// AddListener adds a listener that is called for every event processed by the machine, after any listeners added
+// before it. It may be called from any goroutine, including from the machine's handlers and listeners, in which case
+// the listener is called from the next notification on.
+func (machine *Machine[S, E, T]) AddListener(listener Listener[S, E]) {
+	machine.view.Lock()
+	defer machine.view.Unlock()
+	machine.listeners = append(machine.listeners, listener)
+}
+
+// Current returns the current state. Like the other queries, it may be called from any goroutine, including from the
+// machine's handlers and listeners.
+func (machine *Machine[S, E, T]) Current() S {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.CurrentState
+}
+
+// ActivePath returns the active states from the outermost compound state down to the current state.
+func (machine *Machine[S, E, T]) ActivePath() []S {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	lineage := machine.def.lineage(machine.CurrentState)
+	path := make([]S, len(lineage))
+	for i, state := range lineage {
+		path[len(lineage)-1-i] = state
+	}
+	return path
+}
+
+// Configuration returns every active state in document order, including the active states of every region.
+func (machine *Machine[S, E, T]) Configuration() []S {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return append([]S{}, machine.configuration...)
+}
+
+// IsIn returns whether the supplied state is active.
+func (machine *Machine[S, E, T]) IsIn(state S) bool {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.isIn(state)
+}
+
+func (machine *Machine[S, E, T]) isIn(state S) bool {
+	for _, active := range machine.configuration {
+		if active == state {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTrigger returns whether the supplied event has a transition from the active states, found in the same order a
+// trigger looks for it. Guards are not evaluated, as they need the event object, so a guarded event may still be
+// rejected.
+func (machine *Machine[S, E, T]) CanTrigger(event E) bool {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.canTrigger(event)
+}
+
+func (machine *Machine[S, E, T]) canTrigger(event E) bool {
+	if machine.isDone() {
+		return false
+	}
+	for _, leaf := range machine.leaves() {
+		if _, _, ok := machine.findTransition(leaf, event); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableEvents returns the events that CanTrigger reports may be triggered from the active states, in declaration
+// order.
+func (machine *Machine[S, E, T]) AvailableEvents() []E {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	events := []E{}
+	for _, event := range machine.def.Events {
+		if machine.canTrigger(event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// States returns every state the machine may be in, in declaration order.
+func (machine *Machine[S, E, T]) States() []S {
+	return append([]S{}, machine.def.States...)
+}
+
+// TransitionTable returns every transition declared by the machine's definition, in declaration order, with a rule per
+// source state and per guarded target.
+func (machine *Machine[S, E, T]) TransitionTable() []TransitionRule[S, E] {
+	return append([]TransitionRule[S, E]{}, machine.def.Rules...)
+}
+
+// Done returns a channel that is closed once the machine enters a top-level final state, after which every event is
+// rejected with ErrMachineDone.
+func (machine *Machine[S, E, T]) Done() <-chan struct{} {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.done
+}
+
+// IsDone returns whether the machine has entered a top-level final state.
+func (machine *Machine[S, E, T]) IsDone() bool {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	return machine.isDone()
+}
+
+func (machine *Machine[S, E, T]) isDone() bool {
+	select {
+	case <-machine.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Trigger triggers the supplied event, returning once it and every event queued by its handlers have been processed.
+// The payload must be of the event's object type.
+func (machine *Machine[S, E, T]) Trigger(ctx context.Context, event E, payload interface{}) error {
+	fn, err := machine.eventFunc(event, payload)
+	if err != nil {
+		return err
+	}
+	return machine.Dispatch(ctx, fn)
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *Machine[S, E, T]) eventFunc(event E, payload interface{}) (func(ctx context.Context) error, error) {
+	if machine.handlers.Event == nil {
+		return nil, fmt.Errorf("unknown event %v", event)
+	}
+	return machine.handlers.Event(event, payload)
+}
+
+// Run processes events sent to an actor machine until the context is done, and must be running for any trigger to
+// complete. Every event is processed on the goroutine calling Run, along with the events queued by its handlers. It
+// returns an error immediately unless the definition uses ConcurrencyActor.
+func (machine *Machine[S, E, T]) Run(ctx context.Context) error {
+	if machine.mailbox == nil {
+		return fmt.Errorf("%s machine does not use ConcurrencyActor", machine.def.Name)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case req := <-machine.mailbox:
+			machine.mu.Lock()
+			err := machine.Process(req.fn)
+			machine.mu.Unlock()
+			req.done()
+			req.result <- err
+		}
+	}
+}
+
+// Send sends the supplied event to the goroutine running an actor machine without waiting for it to be processed. The
+// returned channel receives the result of the transition once it has been processed, or an error if the event could
+// not be sent before the context was done. Other machines, and actor machines sent an event from their own handlers,
+// dispatch the event before Send returns. The payload must be of the event's object type.
+func (machine *Machine[S, E, T]) Send(ctx context.Context, event E, payload interface{}) <-chan error {
+	fn, err := machine.eventFunc(event, payload)
+	if err != nil {
+		result := make(chan error, 1)
+		result <- err
+		return result
+	}
+	if machine.mailbox == nil || machine.dispatching(ctx) {
+		result := make(chan error, 1)
+		result <- machine.Dispatch(ctx, fn)
+		return result
+	}
+	ctx, done := machine.dispatchContext(ctx)
+	return machine.send(ctx, func() error {
+		return fn(ctx)
+	}, done)
+}
+
+// SendAndWait sends the supplied event to the goroutine running the machine and waits for the transition to complete,
+// returning its error. The payload must be of the event's object type.
+func (machine *Machine[S, E, T]) SendAndWait(ctx context.Context, event E, payload interface{}) error {
+	return machine.Trigger(ctx, event, payload)
+}
+
+// send sends fn to the goroutine running the machine, returning a channel that receives its result. The done function
+// is called once fn has been processed, or could not be sent.
+func (machine *Machine[S, E, T]) send(ctx context.Context, fn func() error, done func()) <-chan error {
+	result := make(chan error, 1)
+	select {
+	case machine.mailbox <- request{fn: fn, done: done, result: result}:
+	case <-ctx.Done():
+		done()
+		result <- ctx.Err()
+	}
+	return result
+}
+
+// Dispatch processes fn to completion, along with every event it queues, synchronising with other goroutines as the
+// definition's Concurrency requires. Actor machines send fn to the goroutine running the machine and wait for it to be
+// processed. The context passed to fn is marked as belonging to the machine until fn and the events it queues have been
+// processed, so a handler that dispatches an event with the context it was passed, such as by calling a generated
+// trigger method, queues the event as its machine context would rather than waiting for the machine. Events dispatched
+// with the context from other goroutines are queued the same way while the machine is still processing, and otherwise
+// dispatched as usual.
+func (machine *Machine[S, E, T]) Dispatch(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Machines upgraded from ConcurrencyNone still queue events triggered while processing, as handlers may trigger
+	// events without their machine context.
+	queue := machine.dispatching(ctx) || machine.def.Concurrency == ConcurrencyNone
+	if queue && machine.enqueue(func() error {
+		return fn(ctx)
+	}) {
+		return nil
+	}
+	ctx, done := machine.dispatchContext(ctx)
+	defer done()
+	process := func() error {
+		return fn(ctx)
+	}
+	switch machine.concurrency {
+	case ConcurrencyMutex:
+		machine.mu.Lock()
+		defer machine.mu.Unlock()
+	case ConcurrencyActor:
+		select {
+		case err := <-machine.send(ctx, process, done):
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return machine.Process(process)
+}
+
+// dispatchContext returns a context marking the events dispatched with it as dispatched by a handler of the machine,
+// until the returned function is called.
+func (machine *Machine[S, E, T]) dispatchContext(ctx context.Context) (context.Context, func()) {
+	active := int32(1)
+	return context.WithValue(ctx, dispatchKey{machine}, &active), func() {
+		atomic.StoreInt32(&active, 0)
+	}
+}
+
+// dispatching returns whether the supplied context was passed to a handler by an event the machine is processing.
+func (machine *Machine[S, E, T]) dispatching(ctx context.Context) bool {
+	active, ok := ctx.Value(dispatchKey{machine}).(*int32)
+	return ok && atomic.LoadInt32(active) == 1
+}
+
+// Process processes fn to completion, followed by every event queued while processing it. If the machine is already
+// processing an event, fn is queued instead, so handlers never recursively transition the machine. It must only be
+// called from a handler, or by Dispatch.
+func (machine *Machine[S, E, T]) Process(fn func() error) error {
+	if !machine.start(fn) {
+		return nil
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			machine.stop()
+			panic(recovered)
+		}
+	}()
+	err := fn()
+	for chain := 1; err == nil; chain++ {
+		var next func() error
+		next, err = machine.dequeue(chain)
+		if next == nil {
+			break
+		}
+		err = next()
+	}
+	if err != nil {
+		machine.stop()
+	}
+	return err
+}
+
+// start marks the machine as processing an event, returning false if it already is, in which case fn is queued.
+func (machine *Machine[S, E, T]) start(fn func() error) bool {
+	machine.queueMu.Lock()
+	defer machine.queueMu.Unlock()
+	if machine.processing {
+		machine.queue = append(machine.queue, fn)
+		return false
+	}
+	machine.setProcessing(true)
+	return true
+}
+
+// enqueue queues fn if the machine is processing an event, returning whether it did.
+func (machine *Machine[S, E, T]) enqueue(fn func() error) bool {
+	machine.queueMu.Lock()
+	defer machine.queueMu.Unlock()
+	if machine.processing {
+		machine.queue = append(machine.queue, fn)
+	}
+	return machine.processing
+}
+
+// dequeue returns the next queued event, failing if more than MaxChainLength events have been queued. Once the queue
+// is empty it returns nil, and the machine stops processing.
+func (machine *Machine[S, E, T]) dequeue(chain int) (func() error, error) {
+	machine.queueMu.Lock()
+	defer machine.queueMu.Unlock()
+	if len(machine.queue) == 0 {
+		machine.setProcessing(false)
+		return nil, nil
+	}
+	if machine.MaxChainLength > 0 && chain > machine.MaxChainLength {
+		return nil, fmt.Errorf("%w: more than %d queued events", ErrMaxChainLength, machine.MaxChainLength)
+	}
+	next := machine.queue[0]
+	machine.queue = machine.queue[1:]
+	return next, nil
+}
+
+// stop discards the queued events, and the machine stops processing.
+func (machine *Machine[S, E, T]) stop() {
+	machine.queueMu.Lock()
+	defer machine.queueMu.Unlock()
+	machine.queue = nil
+	machine.setProcessing(false)
+}
+
+// setProcessing records whether the machine is processing an event, copying the state object for Snapshot when it
+// starts. It must be called with queueMu held.
+func (machine *Machine[S, E, T]) setProcessing(processing bool) {
+	machine.view.Lock()
+	defer machine.view.Unlock()
+	machine.processing = processing
+	if processing && machine.State != nil {
+		machine.viewState = *machine.State
+	}
+}
+
+// Fire processes the supplied event, taking the transitions it selects from the active states. The resolve function
+// returns each transition's target, evaluating any guards, and may be nil for unguarded events. The action runs once
+// the transitions are selected, either before or after the machine changes state depending on the definition's
+// CommitBeforeAction. It must be called by a function passed to Process or Dispatch.
+func (machine *Machine[S, E, T]) Fire(ctx context.Context, event E, payload interface{}, resolve func(from, target S) (S, error), action func(ctx context.Context, transition Transition[S, E]) error) error {
+	if machine.def.CommitBeforeAction {
+		return machine.fireCommitted(ctx, event, payload, resolve, action)
+	}
+	return machine.fireTransactional(ctx, event, payload, resolve, action)
+}
+
+// fireCommitted takes the transitions selected for the event. The exit handlers of every exited state run first,
+// followed by the transition hooks matching each transition, then the machine changes to the target states before
+// running the action. The entry handlers of every entered state run last.
+func (machine *Machine[S, E, T]) fireCommitted(ctx context.Context, event E, payload interface{}, resolve func(from, target S) (S, error), action func(ctx context.Context, transition Transition[S, E]) error) error {
+	steps, err := machine.selectTransitions(event, resolve)
+	if err != nil {
+		machine.notifyRejected(ctx, event, payload, err)
+		return err
+	}
+	exits, entries := machine.transitionStates(steps)
+	var zero S
+	transition := Transition[S, E]{From: machine.CurrentState, Event: event, To: machine.innermost(zero, machine.next(exits, entries))}
+	_, err = machine.exitStates(ctx, transition, exits)
+	if err != nil {
+		return machine.notifyError(ctx, transition, payload, err)
+	}
+	for _, step := range steps {
+		if step.internal {
+			continue
+		}
+		err = machine.runTransitionHooks(ctx, step.transition)
+		if err != nil {
+			return machine.notifyError(ctx, transition, payload, err)
+		}
+	}
+	machine.change(exits, entries)
+	machine.complete(ctx, entries)
+	machine.notifyTransition(ctx, transition, payload)
+	err = action(ctx, transition)
+	if err == nil {
+		err = machine.enterStates(ctx, transition, entries)
+	}
+	return machine.notifyError(ctx, transition, payload, err)
+}
+
+// fireTransactional takes the transitions selected for the event. The exit handlers of every exited state, the
+// transition hooks matching each transition and the action run against the source states, and the target states are
+// only committed once they all succeed. If any fail, the state object is restored from a CloneState snapshot and the
+// states whose exit handlers ran are re-entered, running their entry handlers again. The entry handlers of every
+// entered state run last.
+func (machine *Machine[S, E, T]) fireTransactional(ctx context.Context, event E, payload interface{}, resolve func(from, target S) (S, error), action func(ctx context.Context, transition Transition[S, E]) error) error {
+	steps, err := machine.selectTransitions(event, resolve)
+	if err != nil {
+		machine.notifyRejected(ctx, event, payload, err)
+		return err
+	}
+	exits, entries := machine.transitionStates(steps)
+	var zero S
+	transition := Transition[S, E]{From: machine.CurrentState, Event: event, To: machine.innermost(zero, machine.next(exits, entries))}
+	var snapshot *T
+	if machine.CloneState != nil {
+		snapshot = machine.CloneState(machine.State)
+	}
+	exited, err := machine.exitStates(ctx, transition, exits)
+	for _, step := range steps {
+		if err != nil {
+			break
+		}
+		if step.internal {
+			continue
+		}
+		err = machine.runTransitionHooks(ctx, step.transition)
+	}
+	if err == nil {
+		err = action(ctx, transition)
+	}
+	if err != nil {
+		if snapshot != nil {
+			*machine.State = *snapshot
+		}
+		return machine.notifyError(ctx, transition, payload, machine.reenterStates(ctx, event, exits[:exited], err))
+	}
+	machine.change(exits, entries)
+	machine.complete(ctx, entries)
+	machine.notifyTransition(ctx, transition, payload)
+	return machine.notifyError(ctx, transition, payload, machine.enterStates(ctx, transition, entries))
+}
+
+// change commits the transition from the exited states to the entered states and reschedules their delayed transitions,
+// holding the view lock so that queries never see the machine part way through the change.
+func (machine *Machine[S, E, T]) change(exits, entries []S) {
+	machine.view.Lock()
+	defer machine.view.Unlock()
+	machine.commit(exits, entries)
+	machine.stopTimers(exits)
+	machine.startTimers(entries)
+	if machine.State != nil {
+		machine.viewState = *machine.State
+	}
+}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition.
+func (machine *Machine[S, E, T]) runTransitionHooks(ctx context.Context, transition Transition[S, E]) error {
+	if machine.handlers.Hooks == nil {
+		return nil
+	}
+	return machine.handlers.Hooks(ctx, transition)
+}
+
+// exitStates runs the exit handlers of the supplied states in order, stopping at the first error. It returns the
+// number of states exited before the error.
+func (machine *Machine[S, E, T]) exitStates(ctx context.Context, transition Transition[S, E], states []S) (int, error) {
+	if machine.handlers.Exit == nil {
+		return len(states), nil
+	}
+	for i, state := range states {
+		err := machine.handlers.Exit(ctx, transition, state)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(states), nil
+}
+
+// reenterStates runs the entry handlers of the supplied exited states again, outermost first, after the transition
+// exiting them failed with err. It returns err, noting any error from re-entering the states.
+func (machine *Machine[S, E, T]) reenterStates(ctx context.Context, event E, exited []S, err error) error {
+	states := make([]S, len(exited))
+	for i, state := range exited {
+		states[len(exited)-1-i] = state
+	}
+	transition := Transition[S, E]{From: machine.CurrentState, Event: event, To: machine.CurrentState}
+	if reenterErr := machine.enterStates(ctx, transition, states); reenterErr != nil {
+		return fmt.Errorf("%w (re-entering the source states also failed: %v)", err, reenterErr)
+	}
+	return err
+}
+
+// enterStates runs the entry handlers of the supplied states in order, stopping at the first error.
+func (machine *Machine[S, E, T]) enterStates(ctx context.Context, transition Transition[S, E], states []S) error {
+	if machine.handlers.Enter == nil {
+		return nil
+	}
+	for _, state := range states {
+		err := machine.handlers.Enter(ctx, transition, state)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WrapHandlerError wraps a non-nil error returned by the named handler with the transition it was handling.
+func WrapHandlerError[S, E comparable](handler string, transition Transition[S, E], err error) error {
+	if err == nil {
+		return nil
+	}
+	return &HandlerError{Handler: handler, Event: name(transition.Event), From: name(transition.From), To: name(transition.To), Err: err}
+}
+
+// name returns the name of the supplied state or event, which is empty for the zero value.
+func name[V comparable](value V) string {
+	var zero V
+	if value == zero {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
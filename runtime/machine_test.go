@@ -0,0 +1,353 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type testState struct {
+	Count int
+}
+
+// newTestDefinition returns a definition where off and on alternate on power, and on is a compound state whose idle
+// child works until done.
+func newTestDefinition() *Definition[string, string] {
+	return &Definition[string, string]{
+		Name:   "test",
+		States: []string{"off", "on", "idle", "busy", "done"},
+		Events: []string{"power", "work", "rest", "finish", "tick"},
+		Transitions: map[string]map[string]string{
+			"":     {"finish": "done"},
+			"off":  {"power": "on"},
+			"on":   {"power": "off", "tick": "on"},
+			"idle": {"work": "busy"},
+			"busy": {"rest": "idle"},
+		},
+		Internal: map[string]map[string]bool{"on": {"tick": true}},
+		Parents:  map[string]string{"idle": "on", "busy": "on"},
+		Initial:  map[string]string{"on": "idle"},
+		Order:    map[string]int{"off": 0, "on": 1, "idle": 2, "busy": 3, "done": 4},
+		Final:    map[string]bool{"done": true},
+		Rules: []TransitionRule[string, string]{
+			{From: "off", Event: "power", To: "on"},
+		},
+	}
+}
+
+// testMachine runs a definition, recording the handlers and actions it runs.
+type testMachine struct {
+	*Machine[string, string, testState]
+	trace  []string
+	action func(ctx context.Context, transition Transition[string, string]) error
+}
+
+func newTestMachine(def *Definition[string, string]) *testMachine {
+	machine := &testMachine{}
+	machine.Machine = NewMachine(def, &testState{}, Handlers[string, string]{
+		Enter: func(ctx context.Context, transition Transition[string, string], state string) error {
+			machine.trace = append(machine.trace, "enter "+state)
+			return nil
+		},
+		Exit: func(ctx context.Context, transition Transition[string, string], state string) error {
+			machine.trace = append(machine.trace, "exit "+state)
+			return nil
+		},
+		Event: func(event string, payload interface{}) (func(ctx context.Context) error, error) {
+			return func(ctx context.Context) error {
+				return machine.fire(ctx, event, payload)
+			}, nil
+		},
+		Automatic: func(ctx context.Context, event string) error {
+			return machine.fire(ctx, event, nil)
+		},
+	})
+	return machine
+}
+
+func (machine *testMachine) fire(ctx context.Context, event string, payload interface{}) error {
+	return machine.Fire(ctx, event, payload, nil, func(ctx context.Context, transition Transition[string, string]) error {
+		machine.trace = append(machine.trace, "action "+transition.Event)
+		if machine.action != nil {
+			return machine.action(ctx, transition)
+		}
+		return nil
+	})
+}
+
+func TestMachineTransitions(t *testing.T) {
+	ctx := context.Background()
+	machine := newTestMachine(newTestDefinition())
+	assert.Equal(t, "off", machine.CurrentState)
+	assert.NilError(t, machine.Start(ctx))
+	assert.DeepEqual(t, []string{"enter off"}, machine.trace)
+
+	machine.trace = nil
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	assert.DeepEqual(t, []string{"exit off", "action power", "enter on", "enter idle"}, machine.trace)
+	assert.DeepEqual(t, []string{"on", "idle"}, machine.Configuration())
+	assert.DeepEqual(t, []string{"on", "idle"}, machine.ActivePath())
+	assert.DeepEqual(t, []string{"power", "work", "finish", "tick"}, machine.AvailableEvents())
+
+	machine.trace = nil
+	assert.NilError(t, machine.Trigger(ctx, "work", nil))
+	assert.NilError(t, machine.Trigger(ctx, "tick", nil))
+	assert.DeepEqual(t, []string{"exit idle", "action work", "enter busy", "action tick"}, machine.trace)
+	assert.Equal(t, "busy", machine.Current())
+	assert.Assert(t, machine.IsIn("on"))
+
+	err := machine.Trigger(ctx, "work", nil)
+	assert.Error(t, err, "invalid transition: no transition target from busy via work")
+	assert.Assert(t, errors.Is(err, ErrInvalidTransition))
+
+	machine.trace = nil
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	assert.DeepEqual(t, []string{"exit busy", "exit on", "action power", "enter off"}, machine.trace)
+}
+
+func TestMachineFinal(t *testing.T) {
+	ctx := context.Background()
+	machine := newTestMachine(newTestDefinition())
+	assert.NilError(t, machine.Trigger(ctx, "finish", nil))
+	assert.Assert(t, machine.IsDone())
+	select {
+	case <-machine.Done():
+	default:
+		t.Fatal("expected the done channel to be closed")
+	}
+	assert.Assert(t, !machine.CanTrigger("power"))
+	err := machine.Trigger(ctx, "power", nil)
+	assert.Error(t, err, "machine is done: power cannot be triggered from final state done")
+	assert.Assert(t, errors.Is(err, ErrMachineDone))
+}
+
+func TestMachineTransactional(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("failed")
+	machine := newTestMachine(newTestDefinition())
+	machine.CloneState = func(state *testState) *testState {
+		clone := *state
+		return &clone
+	}
+	machine.action = func(ctx context.Context, transition Transition[string, string]) error {
+		machine.State.Count++
+		return failure
+	}
+	err := machine.Trigger(ctx, "power", nil)
+	assert.Assert(t, errors.Is(err, failure))
+	assert.Equal(t, "off", machine.CurrentState)
+	assert.Equal(t, 0, machine.State.Count)
+
+	def := newTestDefinition()
+	def.CommitBeforeAction = true
+	machine = newTestMachine(def)
+	machine.action = func(ctx context.Context, transition Transition[string, string]) error {
+		return failure
+	}
+	err = machine.Trigger(ctx, "power", nil)
+	assert.Assert(t, errors.Is(err, failure))
+	assert.Equal(t, "idle", machine.CurrentState)
+}
+
+func TestMachineQueue(t *testing.T) {
+	ctx := context.Background()
+	def := newTestDefinition()
+	def.MaxChainLength = 3
+	machine := newTestMachine(def)
+	machine.action = func(ctx context.Context, transition Transition[string, string]) error {
+		return machine.Process(func() error {
+			return machine.fire(ctx, "power", nil)
+		})
+	}
+	err := machine.Trigger(ctx, "power", nil)
+	assert.Error(t, err, "maximum event chain length exceeded: more than 3 queued events")
+	assert.Assert(t, errors.Is(err, ErrMaxChainLength))
+	assert.DeepEqual(t, []string{
+		"exit off", "action power", "enter on", "enter idle",
+		"exit idle", "exit on", "action power", "enter off",
+		"exit off", "action power", "enter on", "enter idle",
+		"exit idle", "exit on", "action power", "enter off",
+	}, machine.trace)
+}
+
+func TestMachineDelayed(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2020, 11, 13, 6, 35, 0, 0, time.UTC))
+	def := newTestDefinition()
+	def.Delayed = map[string][]Delay[string]{"idle": {{Event: "work", After: time.Minute}}}
+	machine := newTestMachine(def)
+	machine.Clock = clock
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	assert.Equal(t, 1, clock.PendingTimers())
+
+	snapshot := machine.Snapshot()
+	assert.DeepEqual(t, []TimerSnapshot[string, string]{
+		{State: "idle", Event: "work", Deadline: clock.Now().Add(time.Minute)},
+	}, snapshot.Timers)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, "busy", machine.CurrentState)
+
+	assert.NilError(t, machine.Restore(snapshot))
+	assert.Equal(t, "idle", machine.CurrentState)
+	clock.Advance(time.Second)
+	assert.Equal(t, "busy", machine.CurrentState)
+
+	snapshot.Timers[0].State = "off"
+	assert.Error(t, machine.Restore(snapshot), "test snapshot has a timer for inactive state off")
+}
+
+func TestMachineDelayedSystemClock(t *testing.T) {
+	ctx := context.Background()
+	def := newTestDefinition()
+	def.Delayed = map[string][]Delay[string]{
+		"idle": {{Event: "work", After: time.Microsecond}},
+		"busy": {{Event: "rest", After: time.Microsecond}},
+	}
+	// Timers fire on their own goroutines while events are triggered, so the machine must serialise them even
+	// without a Concurrency.
+	machine := newTestMachine(def)
+	deadline := time.Now().Add(20 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		assert.NilError(t, machine.Trigger(ctx, "power", nil))
+		time.Sleep(10 * time.Microsecond)
+	}
+	assert.NilError(t, machine.Trigger(ctx, "finish", nil))
+	assert.Assert(t, machine.IsDone())
+}
+
+func TestMachineDispatchFromHandlerGoroutine(t *testing.T) {
+	ctx := context.Background()
+	def := newTestDefinition()
+	def.Concurrency = ConcurrencyMutex
+	machine := newTestMachine(def)
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	// The handler's context is used from another goroutine, which may trigger while the machine is still processing
+	// or after it is done.
+	errs := make(chan error, 1)
+	machine.action = func(ctx context.Context, transition Transition[string, string]) error {
+		if transition.Event == "work" {
+			started := make(chan struct{})
+			go func() {
+				close(started)
+				errs <- machine.Trigger(ctx, "rest", nil)
+			}()
+			<-started
+		}
+		return nil
+	}
+	for i := 0; i < 100; i++ {
+		assert.NilError(t, machine.Trigger(ctx, "work", nil))
+		assert.NilError(t, <-errs)
+		assert.Equal(t, "idle", machine.Current())
+	}
+}
+
+func TestMachineDelayedReentrant(t *testing.T) {
+	ctx := context.Background()
+	def := newTestDefinition()
+	def.Delayed = map[string][]Delay[string]{"busy": {{Event: "rest", After: time.Minute}}}
+	machine := newTestMachine(def)
+	machine.Clock = NewFakeClock(time.Date(2020, 11, 13, 6, 35, 0, 0, time.UTC))
+	// Without a Concurrency, handlers may trigger events without their machine context, which must be queued rather
+	// than wait for the mutex that serialises the timers.
+	machine.action = func(_ context.Context, transition Transition[string, string]) error {
+		if transition.Event == "power" {
+			return machine.Trigger(context.Background(), "work", nil)
+		}
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- machine.Trigger(ctx, "power", nil)
+	}()
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("trigger from a handler deadlocked")
+	}
+	assert.Equal(t, "busy", machine.Current())
+}
+
+func TestMachineWithoutState(t *testing.T) {
+	ctx := context.Background()
+	var machine *Machine[string, string, testState]
+	machine = NewMachine[string, string, testState](newTestDefinition(), nil, Handlers[string, string]{
+		Event: func(event string, payload interface{}) (func(ctx context.Context) error, error) {
+			return func(ctx context.Context) error {
+				return machine.Fire(ctx, event, payload, nil, func(ctx context.Context, transition Transition[string, string]) error {
+					assert.Check(t, machine.Snapshot().State == nil)
+					return nil
+				})
+			}, nil
+		},
+	})
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	assert.Equal(t, "idle", machine.Current())
+	snapshot := machine.Snapshot()
+	assert.Assert(t, snapshot.State == nil)
+	assert.NilError(t, machine.Restore(snapshot))
+}
+
+func TestMachineListeners(t *testing.T) {
+	ctx := context.Background()
+	machine := newTestMachine(newTestDefinition())
+	listener := &testListener{}
+	machine.AddListener(listener)
+	assert.NilError(t, machine.Trigger(ctx, "power", "payload"))
+	assert.Assert(t, machine.Trigger(ctx, "rest", nil) != nil)
+	assert.DeepEqual(t, []string{"off -> idle on power", "rest rejected from idle"}, listener.events)
+}
+
+func TestMachineAddListenerFromHandler(t *testing.T) {
+	ctx := context.Background()
+	def := newTestDefinition()
+	def.Concurrency = ConcurrencyMutex
+	machine := newTestMachine(def)
+	listener := &testListener{}
+	machine.action = func(ctx context.Context, transition Transition[string, string]) error {
+		machine.AddListener(listener)
+		return nil
+	}
+	assert.NilError(t, machine.Trigger(ctx, "power", nil))
+	assert.DeepEqual(t, []string{"off -> idle on power"}, listener.events)
+}
+
+type testListener struct {
+	BaseListener[string, string]
+	events []string
+}
+
+func (listener *testListener) OnTransition(ctx context.Context, from, to string, event string, payload interface{}) {
+	listener.events = append(listener.events, from+" -> "+to+" on "+event)
+}
+
+func (listener *testListener) OnRejected(ctx context.Context, from string, event string, payload interface{}, err error) {
+	listener.events = append(listener.events, event+" rejected from "+from)
+}
+
+func TestMachineActor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	def := newTestDefinition()
+	def.Concurrency = ConcurrencyActor
+	machine := newTestMachine(def)
+	go machine.Run(ctx)
+	assert.NilError(t, machine.SendAndWait(ctx, "power", nil))
+	assert.NilError(t, <-machine.Send(ctx, "work", nil))
+	assert.Equal(t, "busy", machine.Current())
+
+	assert.ErrorContains(t, newTestMachine(newTestDefinition()).Run(ctx), "does not use ConcurrencyActor")
+}
+
+func TestWrapHandlerError(t *testing.T) {
+	cause := errors.New("no file")
+	assert.NilError(t, WrapHandlerError("OnStateInit", Transition[string, string]{To: "init"}, nil))
+	err := WrapHandlerError("OnStateInit", Transition[string, string]{To: "init"}, cause)
+	assert.Error(t, err, "OnStateInit failed entering init: no file")
+	assert.Assert(t, errors.Is(err, cause))
+}
@@ -0,0 +1,212 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Snapshot is a serializable copy of a Machine's state, which Restore resumes from.
+type Snapshot[S, E comparable, T any] struct {
+	// Configuration is every active state, in document order.
+	Configuration []S `json:"configuration"`
+	// History is the configuration recorded by each history pseudo-state.
+	History map[S][]S `json:"history,omitempty"`
+	// Timers are the pending delayed transitions.
+	Timers []TimerSnapshot[S, E] `json:"timers,omitempty"`
+	// State is a shallow copy of the machine's state object, or nil if the machine has none.
+	State *T `json:"state"`
+}
+
+// TimerSnapshot is a pending delayed transition of a Snapshot.
+type TimerSnapshot[S, E comparable] struct {
+	// State is the active state the delayed transition was scheduled from.
+	State S `json:"state"`
+	Event E `json:"event"`
+	// Deadline is the time the delayed transition is due, according to the machine's Clock.
+	Deadline time.Time `json:"deadline"`
+}
+
+// Snapshot returns a serializable copy of the machine's active configuration, recorded history, pending delayed
+// transitions and state object. While the machine is processing an event, such as when called from a handler, the
+// state object is copied as of the last change of state, as an action may be changing it.
+func (machine *Machine[S, E, T]) Snapshot() Snapshot[S, E, T] {
+	machine.view.RLock()
+	defer machine.view.RUnlock()
+	snapshot := Snapshot[S, E, T]{
+		Configuration: append([]S{}, machine.configuration...),
+	}
+	if machine.State != nil {
+		state := machine.viewState
+		if !machine.processing {
+			state = *machine.State
+		}
+		snapshot.State = &state
+	}
+	if len(machine.history) > 0 {
+		snapshot.History = map[S][]S{}
+		for name, states := range machine.history {
+			snapshot.History[name] = append([]S{}, states...)
+		}
+	}
+	for _, state := range machine.configuration {
+		for _, timer := range machine.timers[state] {
+			snapshot.Timers = append(snapshot.Timers, TimerSnapshot[S, E]{State: state, Event: timer.event, Deadline: timer.deadline})
+		}
+	}
+	return snapshot
+}
+
+// Restore resumes the machine from the supplied snapshot without running any handlers, replacing its active
+// configuration, recorded history, pending delayed transitions and, if the snapshot has one, state object. It must not
+// be called from a handler, or while another goroutine is waiting on the machine's Done channel.
+func (machine *Machine[S, E, T]) Restore(snapshot Snapshot[S, E, T]) error {
+	machine.lock()
+	defer machine.unlock()
+	def := machine.def
+	if len(snapshot.Configuration) == 0 {
+		return fmt.Errorf("%s snapshot has no active states", def.Name)
+	}
+	active := map[S]bool{}
+	for _, state := range snapshot.Configuration {
+		if _, ok := def.Order[state]; !ok {
+			return fmt.Errorf("unknown %s state in snapshot: %v", def.Name, state)
+		}
+		active[state] = true
+	}
+	if err := machine.validateConfiguration(snapshot.Configuration, active); err != nil {
+		return err
+	}
+	for name, states := range snapshot.History {
+		history, ok := def.Histories[name]
+		if !ok {
+			return fmt.Errorf("unknown %s history in snapshot: %v", def.Name, name)
+		}
+		for _, state := range states {
+			if state == history.Parent || !def.Within(state, history.Parent) {
+				return fmt.Errorf("%s snapshot has history %v recording state %v outside %v", def.Name, name, state, history.Parent)
+			}
+		}
+	}
+	events := map[E]bool{}
+	for _, event := range def.Events {
+		events[event] = true
+	}
+	for _, timer := range snapshot.Timers {
+		if !active[timer.State] {
+			return fmt.Errorf("%s snapshot has a timer for inactive state %v", def.Name, timer.State)
+		}
+		if !events[timer.Event] {
+			return fmt.Errorf("unknown %s event: %v", def.Name, timer.Event)
+		}
+	}
+	machine.view.Lock()
+	defer machine.view.Unlock()
+	machine.stopTimers(machine.configuration)
+	if snapshot.State != nil {
+		machine.State = snapshot.State
+	}
+	if machine.isDone() {
+		machine.done = make(chan struct{})
+	}
+	machine.configuration = nil
+	machine.commit(nil, append([]S{}, snapshot.Configuration...))
+	machine.history = map[S][]S{}
+	for name, states := range snapshot.History {
+		machine.history[name] = append([]S{}, states...)
+	}
+	for _, state := range machine.configuration {
+		machine.epochs[state]++
+	}
+	for _, timer := range snapshot.Timers {
+		machine.timers[timer.State] = append(machine.timers[timer.State], machine.startTimer(timer.State, timer.Event, timer.Deadline))
+	}
+	return nil
+}
+
+// validateConfiguration returns an error unless the supplied active states form a configuration the machine could be in:
+// a single top-level state, the parent of every other state, one child of each compound state and every region of
+// each parallel state.
+func (machine *Machine[S, E, T]) validateConfiguration(configuration []S, active map[S]bool) error {
+	def := machine.def
+	var zero S
+	roots := 0
+	children := map[S]int{}
+	for _, state := range configuration {
+		parent := def.Parents[state]
+		if parent == zero {
+			roots++
+			continue
+		}
+		if !active[parent] {
+			return fmt.Errorf("%s snapshot has state %v without its parent %v", def.Name, state, parent)
+		}
+		children[parent]++
+	}
+	if roots != 1 {
+		return fmt.Errorf("%s snapshot has %d top-level states", def.Name, roots)
+	}
+	for _, state := range configuration {
+		if regions, ok := def.Regions[state]; ok {
+			for _, region := range regions {
+				if !active[region] {
+					return fmt.Errorf("%s snapshot has parallel state %v without its region %v", def.Name, state, region)
+				}
+			}
+			continue
+		}
+		if _, ok := def.Initial[state]; ok && children[state] != 1 {
+			return fmt.Errorf("%s snapshot has compound state %v with %d active children", def.Name, state, children[state])
+		}
+	}
+	return nil
+}
+
+// startTimers schedules the delayed transitions from each of the supplied states.
+func (machine *Machine[S, E, T]) startTimers(states []S) {
+	if len(machine.def.Delayed) == 0 {
+		return
+	}
+	now := machine.Clock.Now()
+	for _, state := range states {
+		machine.epochs[state]++
+		delays, ok := machine.def.Delayed[state]
+		if !ok {
+			continue
+		}
+		timers := make([]timer[E], 0, len(delays))
+		for _, delay := range delays {
+			timers = append(timers, machine.startTimer(state, delay.Event, now.Add(delay.After)))
+		}
+		machine.timers[state] = timers
+	}
+}
+
+// startTimer schedules the delayed event to be processed at the deadline, as long as the state has remained active
+// since it was entered.
+func (machine *Machine[S, E, T]) startTimer(state S, event E, deadline time.Time) timer[E] {
+	epoch := machine.epochs[state]
+	t := machine.Clock.AfterFunc(deadline.Sub(machine.Clock.Now()), func() {
+		ctx := context.Background()
+		err := machine.Dispatch(ctx, func(ctx context.Context) error {
+			if machine.epochs[state] != epoch || !machine.isIn(state) {
+				return nil
+			}
+			return machine.automatic(ctx, event)
+		})
+		if err != nil && machine.TimerError != nil {
+			machine.TimerError(event, err)
+		}
+	})
+	return timer[E]{timer: t, event: event, deadline: deadline}
+}
+
+// stopTimers cancels the delayed transitions scheduled from each of the supplied states.
+func (machine *Machine[S, E, T]) stopTimers(states []S) {
+	for _, state := range states {
+		for _, timer := range machine.timers[state] {
+			timer.timer.Stop()
+		}
+		delete(machine.timers, state)
+	}
+}
@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestMachineRestoreInvalid(t *testing.T) {
+	def := newTestDefinition()
+	def.States = append(def.States, "duo", "left", "right", "up", "down", "history")
+	def.Parents["left"] = "duo"
+	def.Parents["right"] = "duo"
+	def.Parents["up"] = "left"
+	def.Parents["down"] = "right"
+	def.Parents["history"] = "on"
+	def.Initial["left"] = "up"
+	def.Initial["right"] = "down"
+	def.Regions = map[string][]string{"duo": {"left", "right"}}
+	def.Histories = map[string]History[string]{"history": {Parent: "on", Default: "idle"}}
+	for _, state := range []string{"duo", "left", "up", "right", "down", "history"} {
+		def.Order[state] = len(def.Order)
+	}
+
+	tests := []struct {
+		name          string
+		configuration []string
+		history       []string
+		err           string
+	}{
+		{
+			name:          "valid",
+			configuration: []string{"on", "busy"},
+			history:       []string{"idle"},
+		},
+		{
+			name:          "valid parallel",
+			configuration: []string{"duo", "left", "up", "right", "down"},
+		},
+		{
+			name:          "several roots",
+			configuration: []string{"off", "on", "idle"},
+			err:           "test snapshot has 2 top-level states",
+		},
+		{
+			name:          "missing parent",
+			configuration: []string{"idle"},
+			err:           "test snapshot has state idle without its parent on",
+		},
+		{
+			name:          "no active child",
+			configuration: []string{"on"},
+			err:           "test snapshot has compound state on with 0 active children",
+		},
+		{
+			name:          "several active children",
+			configuration: []string{"on", "idle", "busy"},
+			err:           "test snapshot has compound state on with 2 active children",
+		},
+		{
+			name:          "inactive region",
+			configuration: []string{"duo", "left", "up"},
+			err:           "test snapshot has parallel state duo without its region right",
+		},
+		{
+			name:          "history outside its parent",
+			configuration: []string{"off"},
+			history:       []string{"off"},
+			err:           "test snapshot has history history recording state off outside on",
+		},
+		{
+			name:          "history recording its parent",
+			configuration: []string{"off"},
+			history:       []string{"on"},
+			err:           "test snapshot has history history recording state on outside on",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			machine := newTestMachine(def)
+			snapshot := Snapshot[string, string, testState]{Configuration: test.configuration}
+			if test.history != nil {
+				snapshot.History = map[string][]string{"history": test.history}
+			}
+			err := machine.Restore(snapshot)
+			if test.err == "" {
+				assert.NilError(t, err)
+				assert.DeepEqual(t, test.configuration, machine.Configuration())
+				return
+			}
+			assert.Error(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package fsmgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a declarative state machine definition, read from a YAML or JSON file. Types are referred to by name, and
+// names from other packages are qualified by their full import path, such as "github.com/org/events.Load".
+type Spec struct {
+	Name               string              `json:"name" yaml:"name"`
+	Package            string              `json:"package,omitempty" yaml:"package,omitempty"`
+	PackagePath        string              `json:"package_path,omitempty" yaml:"package_path,omitempty"`
+	Filename           string              `json:"filename,omitempty" yaml:"filename,omitempty"`
+	DOTFilename        string              `json:"dot_filename,omitempty" yaml:"dot_filename,omitempty"`
+	MermaidFilename    string              `json:"mermaid_filename,omitempty" yaml:"mermaid_filename,omitempty"`
+	State              string              `json:"state" yaml:"state"`
+	Environment        string              `json:"environment" yaml:"environment"`
+	States             []string            `json:"states" yaml:"states"`
+	Substates          map[string][]string `json:"substates,omitempty" yaml:"substates,omitempty"`
+	Regions            map[string][]string `json:"regions,omitempty" yaml:"regions,omitempty"`
+	Final              []string            `json:"final,omitempty" yaml:"final,omitempty"`
+	Histories          []HistorySpec       `json:"histories,omitempty" yaml:"histories,omitempty"`
+	Events             []EventSpec         `json:"events,omitempty" yaml:"events,omitempty"`
+	Hooks              []HookSpec          `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	CommitBeforeAction bool                `json:"commit_before_action,omitempty" yaml:"commit_before_action,omitempty"`
+	MaxChainLength     int                 `json:"max_chain_length,omitempty" yaml:"max_chain_length,omitempty"`
+	Concurrency        string              `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	FailOn             []string            `json:"fail_on,omitempty" yaml:"fail_on,omitempty"`
+}
+
+// EventSpec is the declarative definition of an Event. An event without From states may occur from any state.
+type EventSpec struct {
+	Name     string       `json:"name" yaml:"name"`
+	Type     string       `json:"type" yaml:"type"`
+	From     []string     `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string       `json:"to,omitempty" yaml:"to,omitempty"`
+	Guard    string       `json:"guard,omitempty" yaml:"guard,omitempty"`
+	Branches []BranchSpec `json:"branches,omitempty" yaml:"branches,omitempty"`
+	// After is the delay of a delayed transition, such as "30s", in the format accepted by time.ParseDuration.
+	After string `json:"after,omitempty" yaml:"after,omitempty"`
+	// Completion makes the event a completion transition, triggered once a source state completes.
+	Completion bool `json:"completion,omitempty" yaml:"completion,omitempty"`
+	// Override marks the declaration as intentionally overriding the event's declaration from any state or from an
+	// ancestor of its source states.
+	Override bool `json:"override,omitempty" yaml:"override,omitempty"`
+	// Internal makes the event an internal transition, which runs its action without exiting or entering any state.
+	Internal bool `json:"internal,omitempty" yaml:"internal,omitempty"`
+}
+
+// BranchSpec is the declarative definition of a Branch.
+type BranchSpec struct {
+	Guard string `json:"guard" yaml:"guard"`
+	To    string `json:"to" yaml:"to"`
+}
+
+// HistorySpec is the declarative definition of a History pseudo-state.
+type HistorySpec struct {
+	Name    string `json:"name" yaml:"name"`
+	Parent  string `json:"parent" yaml:"parent"`
+	Default string `json:"default" yaml:"default"`
+	Deep    bool   `json:"deep,omitempty" yaml:"deep,omitempty"`
+}
+
+// HookSpec is the declarative definition of a TransitionHook.
+type HookSpec struct {
+	Name string `json:"name" yaml:"name"`
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	To   string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// ReadSpec reads a Spec from the supplied file. Files with a .json extension are decoded as JSON, and any other file as
+// YAML. Unknown fields are rejected.
+func ReadSpec(filename string) (*Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if filepath.Ext(filename) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(spec)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spec %s: %w", filename, err)
+	}
+	return spec, nil
+}
+
+// Generator returns a Generator for the state machine defined by the spec.
+func (spec *Spec) Generator() (*Generator, error) {
+	concurrency, err := ParseConcurrency(spec.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	gen := New(spec.Name, spec.State, spec.Environment, spec.States...)
+	if spec.Package != "" {
+		gen.PackageName = spec.Package
+	}
+	if spec.Filename != "" {
+		gen.Filename = spec.Filename
+	}
+	gen.PackagePath = spec.PackagePath
+	gen.DOTFilename = spec.DOTFilename
+	gen.MermaidFilename = spec.MermaidFilename
+	gen.CommitBeforeAction = spec.CommitBeforeAction
+	gen.MaxChainLength = spec.MaxChainLength
+	gen.Concurrency = concurrency
+	for _, name := range spec.FailOn {
+		finding, err := ParseFinding(name)
+		if err != nil {
+			return nil, err
+		}
+		gen.FailOn = append(gen.FailOn, finding)
+	}
+	for parent, children := range spec.Substates {
+		gen.AddSubstates(parent, children...)
+	}
+	for parent, regions := range spec.Regions {
+		gen.AddRegions(parent, regions...)
+	}
+	if len(spec.Final) > 0 {
+		gen.Final(spec.Final...)
+	}
+	for _, history := range spec.Histories {
+		gen.Histories = append(gen.Histories, &History{Name: history.Name, Parent: history.Parent, Default: history.Default, Deep: history.Deep})
+	}
+	for _, eventSpec := range spec.Events {
+		ev := NewEvent(eventSpec.Name, eventSpec.Type).From(eventSpec.From...).To(eventSpec.To).Guard(eventSpec.Guard)
+		for _, branch := range eventSpec.Branches {
+			ev.Branch(branch.Guard, branch.To)
+		}
+		if eventSpec.After != "" {
+			delay, err := time.ParseDuration(eventSpec.After)
+			if err != nil {
+				return nil, fmt.Errorf("event %q: invalid delay: %w", eventSpec.Name, err)
+			}
+			ev.After(delay)
+		}
+		if eventSpec.Completion {
+			ev.OnCompletion()
+		}
+		if eventSpec.Override {
+			ev.Overrides()
+		}
+		if eventSpec.Internal {
+			ev.Internal()
+		}
+		gen.AddEvent(ev)
+	}
+	for _, hook := range spec.Hooks {
+		gen.AddTransitionHook(hook.Name, hook.From, hook.To)
+	}
+	return gen, nil
+}
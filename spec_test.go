@@ -0,0 +1,95 @@
+package fsmgen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func writeSpec(t *testing.T, name, contents string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	assert.NilError(t, ioutil.WriteFile(filename, []byte(contents), 0644))
+	return filename
+}
+
+func TestReadSpecYAML(t *testing.T) {
+	spec, err := ReadSpec(writeSpec(t, "door.yaml", `
+name: door
+package: doors
+state: "*github.com/org/doors.Door"
+environment: Env
+states: [closed, open, locked]
+concurrency: mutex
+events:
+  - name: open
+    type: github.com/org/doors/events.Open
+    from: [closed]
+    branches:
+      - guard: unlocked
+        to: open
+  - name: lock
+    type: Lock
+    from: [closed]
+    to: locked
+    after: 5m
+  - name: reset
+    type: Reset
+    to: closed
+  - name: reset
+    type: Reset
+    from: [locked]
+    to: locked
+    override: true
+hooks:
+  - name: audit
+    to: locked
+`))
+	assert.NilError(t, err)
+	gen, err := spec.Generator()
+	assert.NilError(t, err)
+	assert.Equal(t, "doors", gen.PackageName)
+	assert.Equal(t, ConcurrencyMutex, gen.Concurrency)
+	assert.DeepEqual(t, []string{"closed", "open", "locked"}, gen.States)
+	assert.Equal(t, 4, len(gen.Events))
+	assert.DeepEqual(t, []*Branch{{Guard: "unlocked", ToState: "open"}}, gen.Events[0].Branches)
+	assert.Equal(t, 5*time.Minute, gen.Events[1].Delay)
+	assert.Assert(t, !gen.Events[2].Override && gen.Events[3].Override)
+	assert.DeepEqual(t, &TransitionHook{Name: "audit", ToState: "locked"}, gen.Hooks[0])
+	src, err := gen.Generate()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(src), `events "github.com/org/doors/events"`))
+}
+
+func TestReadSpecJSON(t *testing.T) {
+	spec, err := ReadSpec(writeSpec(t, "door.json", `{
+		"name": "door", "state": "Door", "environment": "Env", "states": ["closed", "open"], "final": ["open"],
+		"events": [{"name": "open", "type": "Open", "from": ["closed"], "to": "open"}]
+	}`))
+	assert.NilError(t, err)
+	gen, err := spec.Generator()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]bool{"open": true}, gen.FinalStates)
+	assert.NilError(t, gen.Validate())
+}
+
+func TestReadSpecUnknownField(t *testing.T) {
+	_, err := ReadSpec(writeSpec(t, "door.yaml", "name: door\nstaets: [closed]\n"))
+	assert.ErrorContains(t, err, "staets")
+	_, err = ReadSpec(writeSpec(t, "door.json", `{"name": "door", "staets": ["closed"]}`))
+	assert.ErrorContains(t, err, "staets")
+}
+
+func TestSpecUnknownConcurrency(t *testing.T) {
+	_, err := (&Spec{Name: "door", Concurrency: "threads"}).Generator()
+	assert.Error(t, err, `unknown concurrency mode "threads"`)
+}
+
+func TestSpecInvalidDelay(t *testing.T) {
+	spec := &Spec{Name: "door", Events: []EventSpec{{Name: "lock", After: "soon"}}}
+	_, err := spec.Generator()
+	assert.ErrorContains(t, err, `event "lock": invalid delay`)
+}
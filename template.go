@@ -0,0 +1,454 @@
+package fsmgen
+
+const tmpl = `// Code generated by go-fsmgen. DO NOT EDIT.
+
+package {{ .PackageName }}
+
+import (
+{{- range .StdImports }}
+	"{{ .Path }}"
+{{- end }}
+{{- if .PkgImports }}
+{{ range .PkgImports }}
+	{{ .Alias }} "{{ .Path }}"
+{{- end }}
+{{- end }}
+)
+
+// {{ .ExportedName .Name }}State is a state the {{ .ExportedName .Name }}Machine may be in.
+type {{ .ExportedName .Name }}State string
+
+const (
+{{- range $state := .States }}
+	{{ $.StateConst $state }} {{ $.ExportedName $.Name }}State = "{{ $state }}"
+{{- end }}
+)
+{{- if .Histories }}
+
+// History pseudo-states, which may be the target of a transition but are never active.
+const (
+{{- range $history := .Histories }}
+	{{ $.StateConst $history.Name }} {{ $.ExportedName $.Name }}State = "{{ $history.Name }}"
+{{- end }}
+)
+{{- end }}
+
+// String returns the name of the state.
+func (state {{ .ExportedName .Name }}State) String() string {
+	return string(state)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown states. The zero value, such as the
+// From of a transition from any state, marshals to an empty string.
+func (state {{ .ExportedName .Name }}State) MarshalText() ([]byte, error) {
+	if state == "" {
+		return []byte{}, nil
+	}
+	_, err := Parse{{ .ExportedName .Name }}State(string(state))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(state), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown states. An empty string
+// unmarshals to the zero value.
+func (state *{{ .ExportedName .Name }}State) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*state = ""
+		return nil
+	}
+	parsed, err := Parse{{ .ExportedName .Name }}State(string(text))
+	if err != nil {
+		return err
+	}
+	*state = parsed
+	return nil
+}
+
+// Parse{{ .ExportedName .Name }}State returns the {{ .ExportedName .Name }}State with the supplied name.
+func Parse{{ .ExportedName .Name }}State(str string) ({{ .ExportedName .Name }}State, error) {
+	switch {{ .ExportedName .Name }}State(str) {
+	case {{ range $i, $state := .States }}{{ if $i }}, {{ end }}{{ $.StateConst $state }}{{ end }}{{ range $history := .Histories }}, {{ $.StateConst $history.Name }}{{ end }}:
+		return {{ .ExportedName .Name }}State(str), nil
+	}
+	return "", errors.New("unknown {{ .Name }} state: " + str)
+}
+
+// {{ .ExportedName .Name }}Event is an event that may be triggered on the {{ .ExportedName .Name }}Machine.
+type {{ .ExportedName .Name }}Event string
+
+const (
+{{- range $event := .DistinctEvents }}
+	{{ $.EventConst $event.Name }} {{ $.ExportedName $.Name }}Event = "{{ $event.Name }}"
+{{- end }}
+)
+
+// String returns the name of the event.
+func (event {{ .ExportedName .Name }}Event) String() string {
+	return string(event)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning an error for unknown events. The zero value, such as the
+// Event of the transition that starts the machine, marshals to an empty string.
+func (event {{ .ExportedName .Name }}Event) MarshalText() ([]byte, error) {
+	if event == "" {
+		return []byte{}, nil
+	}
+	_, err := Parse{{ .ExportedName .Name }}Event(string(event))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(event), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, returning an error for unknown events. An empty string
+// unmarshals to the zero value.
+func (event *{{ .ExportedName .Name }}Event) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*event = ""
+		return nil
+	}
+	parsed, err := Parse{{ .ExportedName .Name }}Event(string(text))
+	if err != nil {
+		return err
+	}
+	*event = parsed
+	return nil
+}
+
+// Parse{{ .ExportedName .Name }}Event returns the {{ .ExportedName .Name }}Event with the supplied name.
+func Parse{{ .ExportedName .Name }}Event(str string) ({{ .ExportedName .Name }}Event, error) {
+	switch {{ .ExportedName .Name }}Event(str) {
+{{- if .DistinctEvents }}
+	case {{ range $i, $event := .DistinctEvents }}{{ if $i }}, {{ end }}{{ $.EventConst $event.Name }}{{ end }}:
+		return {{ .ExportedName .Name }}Event(str), nil
+{{- end }}
+	}
+	return "", errors.New("unknown {{ .Name }} event: " + str)
+}
+
+// {{ .ExportedName .Name }}Machine is the {{ .Name }} state machine. The transition engine is provided by the embedded
+// {{ .Runtime }}.Machine, which the fields and methods for inspecting and driving the machine are promoted from.
+type {{ .ExportedName .Name }}Machine struct {
+	*{{ .Runtime }}.Machine[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event, {{ .StateObjName }}]
+
+	env {{ .EnvObjName }}
+{{ range $event := .DistinctEvents }}
+	{{ $.ExportedName $event.Name }}Action func(ctx {{ $.ExportedName $.Name }}MachineContext, state *{{ $.StateObjName }}, ev {{ $.EventObjName $event }}) error
+{{- end }}
+{{ range $event := .DistinctEvents }}
+{{- range $guard := $.GuardNames $event }}
+	{{ $.GuardField $event $guard }} func(ctx {{ $.ExportedName $.Name }}MachineContext, state {{ $.StateObjName }}, ev {{ $.EventObjName $event }}) bool
+{{- end }}
+{{- end }}
+{{ range $state := .States }}
+	OnState{{ $.ExportedName $state }} func(ctx {{ $.ExportedName $.Name }}MachineContext, env {{ $.EnvObjName }}, state {{ $.StateObjName }}) error
+{{- end }}
+{{ range $state := .States }}
+	OnExit{{ $.ExportedName $state }} func(ctx {{ $.ExportedName $.Name }}MachineContext, env {{ $.EnvObjName }}, state {{ $.StateObjName }}) error
+{{- end }}
+{{- if .Hooks }}
+{{ range $hook := .Hooks }}
+	{{ $.HookField $hook }} func(ctx {{ $.ExportedName $.Name }}MachineContext, env {{ $.EnvObjName }}, state {{ $.StateObjName }}, transition {{ $.ExportedName $.Name }}Transition) error
+{{- end }}
+{{- end }}
+}
+
+// {{ .ExportedName .Name }}Transition describes a transition of the {{ .ExportedName .Name }}Machine.
+type {{ .ExportedName .Name }}Transition = {{ .Runtime }}.Transition[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]
+
+// {{ .ExportedName .Name }}Snapshot is a serializable copy of the {{ .ExportedName .Name }}Machine's state, which
+// Restore resumes from.
+type {{ .ExportedName .Name }}Snapshot = {{ .Runtime }}.Snapshot[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event, {{ .StateObjName }}]
+{{- if .Delayed }}
+
+// {{ .ExportedName .Name }}TimerSnapshot is a pending delayed transition of a {{ .ExportedName .Name }}Snapshot.
+type {{ .ExportedName .Name }}TimerSnapshot = {{ .Runtime }}.TimerSnapshot[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]
+{{- end }}
+
+// {{ .ExportedName .Name }}TransitionRule describes a transition declared by the {{ .ExportedName .Name }}Machine's definition.
+type {{ .ExportedName .Name }}TransitionRule = {{ .Runtime }}.TransitionRule[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]
+
+// {{ .ExportedName .Name }}Listener observes every event processed by a {{ .ExportedName .Name }}Machine.
+type {{ .ExportedName .Name }}Listener = {{ .Runtime }}.Listener[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]
+
+// {{ .ExportedName .Name }}BaseListener implements {{ .ExportedName .Name }}Listener with methods that do nothing, so
+// listeners can embed it and only implement the methods they need.
+type {{ .ExportedName .Name }}BaseListener = {{ .Runtime }}.BaseListener[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]
+
+// {{ .ExportedName .Name }}MachineContext is passed to handlers, actions and guards. Events triggered through it are
+// queued and processed once the current event completes. It must not be used once the handler it was passed to returns.
+type {{ .ExportedName .Name }}MachineContext interface {
+	Context() context.Context
+{{- range $event := .DistinctEvents }}
+	Trigger{{ $.ExportedName $event.Name }}(ev {{ $.EventObjName $event }}) error
+{{- end }}
+}
+
+type {{ .UnexportedName .Name }}MachineContext struct {
+	ctx context.Context
+	machine *{{ .ExportedName .Name }}Machine
+}
+
+func new{{ .ExportedName .Name }}Context(ctx context.Context, machine *{{ .ExportedName .Name }}Machine) {{ .ExportedName .Name }}MachineContext {
+	return &{{ .UnexportedName .Name }}MachineContext{
+		ctx: ctx,
+		machine: machine,
+	}
+}
+
+func (ctx {{ .UnexportedName .Name }}MachineContext) Context() context.Context {
+	return ctx.ctx
+}
+{{ range $event := .DistinctEvents }}
+// Trigger{{ $.ExportedName $event.Name }} queues the event, to be processed once the current event and any events
+// queued before it have completed. Errors are returned by the outermost trigger.
+func (ctx {{ $.UnexportedName $.Name }}MachineContext) Trigger{{ $.ExportedName $event.Name }}(ev {{ $.EventObjName $event }}) error {
+	return ctx.machine.Process(func() error {
+		return ctx.machine.trigger{{ $.ExportedName $event.Name }}(ctx.ctx, ev)
+	})
+}
+{{ end }}
+// {{ .UnexportedName .Name }}Definition is the structure of the {{ .ExportedName .Name }}Machine, shared by every instance.
+var {{ .UnexportedName .Name }}Definition = &{{ .Runtime }}.Definition[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]{
+	Name:   "{{ .Name }}",
+	States: []{{ .ExportedName .Name }}State{ {{- range $i, $state := .States }}{{ if $i }}, {{ end }}{{ $.StateConst $state }}{{ end -}} },
+	Events: []{{ .ExportedName .Name }}Event{ {{- range $i, $event := .DistinctEvents }}{{ if $i }}, {{ end }}{{ $.EventConst $event.Name }}{{ end -}} },
+	Transitions: map[{{ .ExportedName .Name }}State]map[{{ .ExportedName .Name }}Event]{{ .ExportedName .Name }}State{
+		{{- range $from, $events := .TransitionMap }}
+		{{ $.StateValue $from }}: {
+			{{- range $event, $target := $events }}
+			{{ $.EventConst $event }}: {{ $.StateValue $target }},
+			{{- end }}
+		},
+		{{- end }}
+	},
+{{- if .Internals }}
+	Internal: map[{{ .ExportedName .Name }}State]map[{{ .ExportedName .Name }}Event]bool{
+		{{- range $from, $events := .InternalMap }}
+		{{ $.StateValue $from }}: {
+			{{- range $event := $events }}
+			{{ $.EventConst $event }}: true,
+			{{- end }}
+		},
+		{{- end }}
+	},
+{{- end }}
+	Parents: map[{{ .ExportedName .Name }}State]{{ .ExportedName .Name }}State{
+		{{- range $child, $parent := .ParentMap }}
+		{{ $.StateConst $child }}: {{ $.StateConst $parent }},
+		{{- end }}
+	},
+	Initial: map[{{ .ExportedName .Name }}State]{{ .ExportedName .Name }}State{
+		{{- range $parent, $child := .InitialMap }}
+		{{ $.StateConst $parent }}: {{ $.StateConst $child }},
+		{{- end }}
+	},
+	Regions: map[{{ .ExportedName .Name }}State][]{{ .ExportedName .Name }}State{
+		{{- range $parent, $regions := .RegionMap }}
+		{{ $.StateConst $parent }}: { {{- range $i, $region := $regions }}{{ if $i }}, {{ end }}{{ $.StateConst $region }}{{ end -}} },
+		{{- end }}
+	},
+	Order: map[{{ .ExportedName .Name }}State]int{
+		{{- range $i, $state := .DocumentOrder }}
+		{{ $.StateConst $state }}: {{ $i }},
+		{{- end }}
+	},
+{{- if .Finals }}
+	Final: map[{{ .ExportedName .Name }}State]bool{
+		{{- range $state, $final := .FinalStates }}
+		{{ $.StateConst $state }}: true,
+		{{- end }}
+	},
+{{- end }}
+{{- if .Histories }}
+	Histories: map[{{ .ExportedName .Name }}State]{{ .Runtime }}.History[{{ .ExportedName .Name }}State]{
+		{{- range $history := .Histories }}
+		{{ $.StateConst $history.Name }}: {Parent: {{ $.StateConst $history.Parent }}, Default: {{ $.StateConst $history.Default }}, Deep: {{ $history.Deep }}},
+		{{- end }}
+	},
+{{- end }}
+{{- if .Delayed }}
+	Delayed: map[{{ .ExportedName .Name }}State][]{{ .Runtime }}.Delay[{{ .ExportedName .Name }}Event]{
+		{{- range $state, $events := .DelayedFrom }}
+		{{ $.StateConst $state }}: {
+			{{- range $event := $events }}
+			{Event: {{ $.EventConst $event.Name }}, After: {{ $.DurationExpr $event.Delay }}},
+			{{- end }}
+		},
+		{{- end }}
+	},
+{{- end }}
+{{- if .Completions }}
+	Completions: map[{{ .ExportedName .Name }}State][]{{ .ExportedName .Name }}Event{
+		{{- range $state, $events := .CompletionFrom }}
+		{{ $.StateConst $state }}: { {{- range $i, $event := $events }}{{ if $i }}, {{ end }}{{ $.EventConst $event.Name }}{{ end -}} },
+		{{- end }}
+	},
+{{- end }}
+	Rules: []{{ .ExportedName .Name }}TransitionRule{
+	{{- range $rule := .TransitionRules }}
+		{From: {{ $.StateValue $rule.From }}, Event: {{ $.EventConst $rule.Event }}, To: {{ $.StateValue $rule.To }}{{ if $rule.Guard }}, Guard: {{ printf "%q" $rule.Guard }}{{ end }}{{ if $rule.Internal }}, Internal: true{{ end }}},
+	{{- end }}
+	},
+	MaxChainLength: {{ .ChainLimit }},
+{{- if .CommitBeforeAction }}
+	CommitBeforeAction: true,
+{{- end }}
+{{- with .ConcurrencyConst }}
+	Concurrency: {{ $.Runtime }}.{{ . }},
+{{- end }}
+}
+
+// New{{ .ExportedName .Name }}Machine returns a machine in its initial configuration. Its Clock defaults to the
+// environment's clock if it implements {{ .Runtime }}.ClockProvider, or the system clock otherwise.
+func New{{ .ExportedName .Name }}Machine(state *{{ .StateObjName }}, env {{ .EnvObjName }}) *{{ .ExportedName .Name }}Machine {
+	machine := &{{ .ExportedName .Name }}Machine{env: env}
+	machine.Machine = {{ .Runtime }}.NewMachine({{ .UnexportedName .Name }}Definition, state, {{ .Runtime }}.Handlers[{{ .ExportedName .Name }}State, {{ .ExportedName .Name }}Event]{
+		Enter: machine.enterState,
+		Exit:  machine.exitState,
+{{- if .Hooks }}
+		Hooks: machine.runTransitionHooks,
+{{- end }}
+		Event: machine.eventFunc,
+{{- if .AutomaticEvents }}
+		Automatic: machine.triggerAutomatic,
+{{- end }}
+	})
+	if provider, ok := interface{}(env).({{ .Runtime }}.ClockProvider); ok {
+		machine.Clock = provider.Clock()
+	}
+	return machine
+}
+
+// New{{ .ExportedName .Name }}MachineFromSnapshot returns a machine resumed from the supplied snapshot, without running
+// any handlers. It must not be started.
+func New{{ .ExportedName .Name }}MachineFromSnapshot(snapshot {{ .ExportedName .Name }}Snapshot, env {{ .EnvObjName }}) (*{{ .ExportedName .Name }}Machine, error) {
+	state := snapshot.State
+	if state == nil {
+		state = new({{ .StateObjName }})
+	}
+	machine := New{{ .ExportedName .Name }}Machine(state, env)
+	err := machine.Restore(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// eventFunc returns a function that processes the supplied event, checking the payload is of the event's object type.
+func (machine *{{ .ExportedName .Name }}Machine) eventFunc(event {{ .ExportedName .Name }}Event, payload interface{}) (func(ctx context.Context) error, error) {
+	switch event {
+	{{- range $event := .DistinctEvents }}
+	case {{ $.EventConst $event.Name }}:
+		ev, ok := payload.({{ $.EventObjName $event }})
+		if !ok {
+			return nil, fmt.Errorf("invalid payload for event %s: expected {{ $.EventObjName $event }}, got %T", event, payload)
+		}
+		return func(ctx context.Context) error {
+			return machine.trigger{{ $.ExportedName $event.Name }}(ctx, ev)
+		}, nil
+	{{- end }}
+	}
+	return nil, fmt.Errorf("unknown event %s", event)
+}
+{{- if .AutomaticEvents }}
+
+// triggerAutomatic processes the supplied delayed or completion event with a zero value event object.
+func (machine *{{ .ExportedName .Name }}Machine) triggerAutomatic(ctx context.Context, event {{ .ExportedName .Name }}Event) error {
+	switch event {
+	{{- range $event := .AutomaticEvents }}
+	case {{ $.EventConst $event.Name }}:
+		var ev {{ $.EventObjName $event }}
+		return machine.trigger{{ $.ExportedName $event.Name }}(ctx, ev)
+	{{- end }}
+	}
+	return fmt.Errorf("unknown automatic event %s", event)
+}
+{{- end }}
+{{- if .Hooks }}
+
+// runTransitionHooks runs the transition hooks matching the supplied transition, in declaration order. A hook's states
+// match the transition's states and any of their ancestors.
+func (machine *{{ .ExportedName .Name }}Machine) runTransitionHooks(ctx context.Context, transition {{ .ExportedName .Name }}Transition) error {
+{{- range $hook := .Hooks }}
+	if machine.{{ $.HookField $hook }} != nil{{ if $hook.FromState }} && {{ $.UnexportedName $.Name }}Definition.Within(transition.From, {{ $.StateConst $hook.FromState }}){{ end }}{{ if $hook.ToState }} && {{ $.UnexportedName $.Name }}Definition.Within(transition.To, {{ $.StateConst $hook.ToState }}){{ end }} {
+		err := machine.{{ $.HookField $hook }}(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.env, *machine.State, transition)
+		if err != nil {
+			return {{ $.Runtime }}.WrapHandlerError("{{ $.HookField $hook }}", transition, err)
+		}
+	}
+{{- end }}
+	return nil
+}
+{{- end }}
+
+func (machine *{{ .ExportedName .Name }}Machine) exitState(ctx context.Context, transition {{ .ExportedName .Name }}Transition, state {{ .ExportedName .Name }}State) error {
+	switch state {
+	{{- range $state := .States }}
+	case {{ $.StateConst $state }}:
+		if machine.OnExit{{ $.ExportedName $state }} == nil {
+			break
+		}
+		return {{ $.Runtime }}.WrapHandlerError("OnExit{{ $.ExportedName $state }}", transition, machine.OnExit{{ $.ExportedName $state }}(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.env, *machine.State))
+	{{- end }}
+	}
+	return nil
+}
+
+func (machine *{{ .ExportedName .Name }}Machine) enterState(ctx context.Context, transition {{ .ExportedName .Name }}Transition, state {{ .ExportedName .Name }}State) error {
+	switch state {
+	{{- range $state := .States }}
+	case {{ $.StateConst $state }}:
+		if machine.OnState{{ $.ExportedName $state }} == nil {
+			break
+		}
+		return {{ $.Runtime }}.WrapHandlerError("OnState{{ $.ExportedName $state }}", transition, machine.OnState{{ $.ExportedName $state }}(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.env, *machine.State))
+	{{- end }}
+	}
+	return nil
+}
+{{ range $event := .DistinctEvents }}
+// Trigger{{ $.ExportedName $event.Name }} triggers the {{ $event.Name }} event, returning once it and every event queued
+// by its handlers have been processed.
+func (machine *{{ $.ExportedName $.Name }}Machine) Trigger{{ $.ExportedName $event.Name }}(ctx context.Context, ev {{ $.EventObjName $event }}) error {
+	return machine.Dispatch(ctx, func(ctx context.Context) error {
+		return machine.trigger{{ $.ExportedName $event.Name }}(ctx, ev)
+	})
+}
+
+func (machine *{{ $.ExportedName $.Name }}Machine) trigger{{ $.ExportedName $event.Name }}(ctx context.Context, ev {{ $.EventObjName $event }}) error {
+{{- if $.Guarded $event }}
+	guardCtx := new{{ $.ExportedName $.Name }}Context(ctx, machine)
+	resolve := func(from, target {{ $.ExportedName $.Name }}State) ({{ $.ExportedName $.Name }}State, error) {
+	{{- range $decl := $.Declarations $event }}
+	{{- if $decl.Guarded }}
+		if {{ $.SourceCond $decl }} {
+			switch {
+			{{- range $branch := $decl.Branches }}
+			case machine.{{ $.GuardField $decl $branch.Guard }} != nil && machine.{{ $.GuardField $decl $branch.Guard }}(guardCtx, *machine.State, ev):
+				return {{ $.StateConst $branch.ToState }}, nil
+			{{- end }}
+			{{- if $decl.ToGuard }}
+			case machine.{{ $.GuardField $decl $decl.ToGuard }} != nil && machine.{{ $.GuardField $decl $decl.ToGuard }}(guardCtx, *machine.State, ev):
+				return {{ $.StateValue $decl.ToState }}, nil
+			{{- end }}
+			}
+			{{- if or $decl.ToGuard (not $decl.ToState) }}
+			return "", fmt.Errorf("%w: no guard passed for %s from %s", {{ $.Runtime }}.ErrGuardRejected, {{ $.EventConst $decl.Name }}, machine.CurrentState)
+			{{- end }}
+		}
+	{{- end }}
+	{{- end }}
+		return target, nil
+	}
+{{- end }}
+	return machine.Fire(ctx, {{ $.EventConst $event.Name }}, ev, {{ if $.Guarded $event }}resolve{{ else }}nil{{ end }}, func(ctx context.Context, transition {{ $.ExportedName $.Name }}Transition) error {
+		if machine.{{ $.ExportedName $event.Name }}Action == nil {
+			return nil
+		}
+		return {{ $.Runtime }}.WrapHandlerError("{{ $.ExportedName $event.Name }}Action", transition, machine.{{ $.ExportedName $event.Name }}Action(new{{ $.ExportedName $.Name }}Context(ctx, machine), machine.State, ev))
+	})
+}
+{{ end }}`
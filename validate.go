@@ -0,0 +1,453 @@
+package fsmgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem describes a single issue found while validating a Generator definition.
+type Problem struct {
+	// Event is the name of the offending event, if the problem relates to an event.
+	Event string
+	// State is the name of the offending state, if the problem relates to a state.
+	State string
+	// Message describes the problem.
+	Message string
+}
+
+func (p *Problem) String() string {
+	switch {
+	case p.Event != "":
+		return fmt.Sprintf("event %q: %s", p.Event, p.Message)
+	case p.State != "":
+		return fmt.Sprintf("state %q: %s", p.State, p.Message)
+	}
+	return p.Message
+}
+
+// ValidationError is returned when a Generator definition is invalid. It lists every problem found, not just the first.
+type ValidationError struct {
+	Name     string
+	Problems []*Problem
+}
+
+func (err *ValidationError) Error() string {
+	lines := make([]string, 0, len(err.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid state machine %q: %d problem(s)", err.Name, len(err.Problems)))
+	for _, problem := range err.Problems {
+		lines = append(lines, "\t"+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks the Generator definition for problems that would otherwise result in generated code that fails to
+// compile or never transitions. It returns a *ValidationError listing every problem found, or nil.
+func (gen *Generator) Validate() error {
+	v := &validator{gen: gen}
+	v.validate()
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Name: gen.Name, Problems: v.problems}
+}
+
+// validator accumulates the problems found in a Generator definition.
+type validator struct {
+	gen      *Generator
+	problems []*Problem
+}
+
+func (v *validator) stateProblem(state, format string, args ...interface{}) {
+	v.problems = append(v.problems, &Problem{State: state, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) eventProblem(event, format string, args ...interface{}) {
+	v.problems = append(v.problems, &Problem{Event: event, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate() {
+	gen := v.gen
+	if gen.Name == "" {
+		v.problems = append(v.problems, &Problem{Message: "machine has no name"})
+	}
+	if gen.stateObj.missing() {
+		v.problems = append(v.problems, &Problem{Message: "machine has no state object type"})
+	}
+	if gen.envObj.missing() {
+		v.problems = append(v.problems, &Problem{Message: "machine has no environment object type"})
+	}
+	if _, ok := concurrencyNames[gen.Concurrency]; !ok {
+		v.problems = append(v.problems, &Problem{Message: fmt.Sprintf("unknown concurrency mode %d", gen.Concurrency)})
+	}
+	if len(gen.States) == 0 {
+		v.problems = append(v.problems, &Problem{Message: "machine has no states, at least an initial state is required"})
+	}
+	states := v.validateStates()
+	v.validateSubstates(states)
+	v.validateFinalStates(states)
+	targets := v.validateHistories(states)
+	v.validateEvents(states, targets)
+	v.validateOverrides()
+	v.validateHooks(states)
+	v.validateTypeNames()
+}
+
+func (v *validator) validateHooks(states map[string]bool) {
+	identifiers := map[string]string{}
+	for i, hook := range v.gen.Hooks {
+		if hook.Name == "" {
+			v.problems = append(v.problems, &Problem{Message: fmt.Sprintf("transition hook at index %d has no name", i)})
+			continue
+		}
+		ident := exportedName(hook.Name)
+		if other, ok := identifiers[ident]; ok {
+			v.problems = append(v.problems, &Problem{Message: fmt.Sprintf("transition hook %q generates the same identifier %q as transition hook %q", hook.Name, ident, other)})
+		}
+		identifiers[ident] = hook.Name
+		for _, state := range []string{hook.FromState, hook.ToState} {
+			if state != "" && !states[state] {
+				v.problems = append(v.problems, &Problem{State: state, Message: fmt.Sprintf("transition hook %q refers to an unknown state", hook.Name)})
+			}
+		}
+	}
+}
+
+// validateTypeNames checks that none of the state, environment or event object types share a name with a type the
+// generator declares in the same package.
+func (v *validator) validateTypeNames() {
+	gen := v.gen
+	name := exportedName(gen.Name)
+	generated := map[string]bool{}
+	for _, suffix := range []string{"Machine", "MachineContext", "State", "Event", "Transition", "TransitionRule", "Snapshot", "TimerSnapshot", "Listener", "BaseListener"} {
+		generated[name+suffix] = true
+	}
+	collides := func(obj objType) bool {
+		if obj.typ == nil {
+			_, pkgPath, name := parseTypeName(obj.name)
+			return generated[name] && (pkgPath == "" || gen.isLocalPackage(pkgPath))
+		}
+		return generated[obj.typ.Name()] && gen.isLocalPackage(obj.typ.PkgPath())
+	}
+	if collides(gen.stateObj) {
+		v.problems = append(v.problems, &Problem{Message: "state object type collides with a generated type name"})
+	}
+	if collides(gen.envObj) {
+		v.problems = append(v.problems, &Problem{Message: "environment object type collides with a generated type name"})
+	}
+	for _, event := range gen.Events {
+		if event != nil && !event.objType().missing() && collides(event.objType()) {
+			v.eventProblem(event.Name, "event object type collides with a generated type name")
+		}
+	}
+}
+
+// validateStates checks the declared states and returns the set of known state names.
+func (v *validator) validateStates() map[string]bool {
+	states := map[string]bool{}
+	identifiers := map[string]string{}
+	for _, state := range v.gen.States {
+		if state == "" {
+			v.stateProblem(state, "state name is empty")
+			continue
+		}
+		if states[state] {
+			v.stateProblem(state, "state is declared more than once")
+			continue
+		}
+		states[state] = true
+		ident := exportedName(state)
+		if other, ok := identifiers[ident]; ok {
+			v.stateProblem(state, "state generates the same identifier %q as state %q", ident, other)
+			continue
+		}
+		identifiers[ident] = state
+	}
+	return states
+}
+
+// validateSubstates checks that compound states and their children are known states forming a tree.
+func (v *validator) validateSubstates(states map[string]bool) {
+	gen := v.gen
+	parents := map[string]string{}
+	for _, parent := range gen.States {
+		children, ok := gen.Substates[parent]
+		if !ok {
+			if gen.ParallelStates[parent] {
+				v.stateProblem(parent, "parallel state has no regions")
+			}
+			continue
+		}
+		if len(children) == 0 {
+			v.stateProblem(parent, "compound state has no child states")
+		}
+		for _, child := range children {
+			switch {
+			case !states[child]:
+				v.stateProblem(parent, "child state %q is not a known state", child)
+			case parents[child] != "":
+				v.stateProblem(child, "state is a child of both %q and %q", parents[child], parent)
+			default:
+				parents[child] = parent
+			}
+		}
+	}
+	unknown := []string{}
+	for parent := range gen.Substates {
+		if !states[parent] {
+			unknown = append(unknown, parent)
+		}
+	}
+	sort.Strings(unknown)
+	for _, parent := range unknown {
+		v.stateProblem(parent, "compound state is not a known state")
+	}
+	for _, state := range gen.States {
+		seen := map[string]bool{}
+		for s := parents[state]; s != ""; s = parents[s] {
+			if s == state {
+				v.stateProblem(state, "state is its own ancestor")
+				break
+			}
+			if seen[s] {
+				break
+			}
+			seen[s] = true
+		}
+	}
+}
+
+// validateFinalStates checks that final states are known states without children.
+func (v *validator) validateFinalStates(states map[string]bool) {
+	finals := []string{}
+	for state := range v.gen.FinalStates {
+		finals = append(finals, state)
+	}
+	sort.Strings(finals)
+	for _, state := range finals {
+		switch {
+		case !states[state]:
+			v.stateProblem(state, "final state is not a known state")
+		case len(v.gen.Substates[state]) > 0:
+			v.stateProblem(state, "final state cannot have child states")
+		}
+	}
+}
+
+// validateHistories checks the history pseudo-states and returns the set of valid transition targets, which is the
+// known states along with the history pseudo-states.
+func (v *validator) validateHistories(states map[string]bool) map[string]bool {
+	gen := v.gen
+	targets := map[string]bool{}
+	identifiers := map[string]string{}
+	for state := range states {
+		targets[state] = true
+		identifiers[exportedName(state)] = state
+	}
+	for i, history := range gen.Histories {
+		if history.Name == "" {
+			v.problems = append(v.problems, &Problem{Message: fmt.Sprintf("history at index %d has no name", i)})
+			continue
+		}
+		ident := exportedName(history.Name)
+		if other, ok := identifiers[ident]; ok {
+			v.stateProblem(history.Name, "history generates the same identifier %q as %q", ident, other)
+		} else {
+			identifiers[ident] = history.Name
+			targets[history.Name] = true
+		}
+		if len(gen.Substates[history.Parent]) == 0 {
+			v.stateProblem(history.Name, "history parent %q is not a compound state", history.Parent)
+			continue
+		}
+		if history.Default == "" {
+			v.stateProblem(history.Name, "history has no default state")
+			continue
+		}
+		descendant := false
+		for _, state := range gen.ancestors(history.Default) {
+			descendant = descendant || state == history.Parent
+		}
+		if !descendant {
+			v.stateProblem(history.Name, "history default state %q is not a descendant of %q", history.Default, history.Parent)
+		}
+	}
+	return targets
+}
+
+func (v *validator) validateEvents(states, targets map[string]bool) {
+	declared := map[string][]*Event{}
+	identifiers := map[string]string{}
+	for i, event := range v.gen.Events {
+		if event == nil {
+			v.problems = append(v.problems, &Problem{Message: fmt.Sprintf("event at index %d is nil", i)})
+			continue
+		}
+		if event.Name == "" {
+			v.eventProblem(event.Name, "event at index %d has no name", i)
+		} else if earlier := declared[event.Name]; len(earlier) > 0 {
+			v.validateRedeclaration(event, earlier)
+			declared[event.Name] = append(earlier, event)
+		} else {
+			declared[event.Name] = []*Event{event}
+			ident := exportedName(event.Name)
+			if other, ok := identifiers[ident]; ok {
+				v.eventProblem(event.Name, "event generates the same identifier %q as event %q", ident, other)
+			}
+			identifiers[ident] = event.Name
+		}
+		if event.objType().missing() {
+			v.eventProblem(event.Name, "event has no event object type")
+		}
+		for _, from := range event.FromStates {
+			switch {
+			case !states[from]:
+				v.eventProblem(event.Name, "source state %q is not a known state", from)
+			case v.gen.FinalStates[from]:
+				v.eventProblem(event.Name, "source state %q is a final state, which cannot have outgoing transitions", from)
+			case event.Completion && !v.gen.completable(from):
+				v.eventProblem(event.Name, "source state %q of completion event never completes", from)
+			}
+		}
+		switch {
+		case event.InternalTransition:
+			v.validateInternal(event)
+		case event.ToState == "" && event.ToGuard != "":
+			v.eventProblem(event.Name, "guard %q has no target state", event.ToGuard)
+		case event.ToState == "" && len(event.Branches) == 0:
+			v.eventProblem(event.Name, "event has no target state")
+		case event.ToState != "" && !targets[event.ToState]:
+			v.eventProblem(event.Name, "target state %q is not a known state", event.ToState)
+		}
+		switch {
+		case event.Delay < 0:
+			v.eventProblem(event.Name, "delay %s is negative", event.Delay)
+		case event.Delay > 0 && len(event.FromStates) == 0:
+			v.eventProblem(event.Name, "delayed event has no source states to schedule it from")
+		case event.Delay > 0 && event.Completion:
+			v.eventProblem(event.Name, "event cannot be both a delayed and a completion transition")
+		case event.Completion && len(event.FromStates) == 0:
+			v.eventProblem(event.Name, "completion event has no source states to complete")
+		}
+		for _, branch := range event.Branches {
+			if branch.Guard == "" {
+				v.eventProblem(event.Name, "branch to %q has no guard", branch.ToState)
+			}
+			if !targets[branch.ToState] {
+				v.eventProblem(event.Name, "branch target state %q is not a known state", branch.ToState)
+			}
+		}
+	}
+}
+
+// validateInternal checks that an internal event stays in its source states, having no target other than the state it
+// is declared on.
+func (v *validator) validateInternal(event *Event) {
+	if len(event.Branches) > 0 {
+		v.eventProblem(event.Name, "internal event cannot have branches")
+	}
+	if event.ToState == "" {
+		return
+	}
+	if len(event.FromStates) == 0 {
+		v.eventProblem(event.Name, "internal event from any state cannot have a target state")
+		return
+	}
+	for _, from := range event.FromStates {
+		if from != event.ToState {
+			v.eventProblem(event.Name, "internal event target %q is not its source state %q", event.ToState, from)
+			return
+		}
+	}
+}
+
+// validateRedeclaration checks that an event declared more than once has the same object type in every declaration,
+// and that no state has more than one transition for the event. Declarations may only both apply to a state if they
+// are for orthogonal regions, or if one overrides the other from more specific source states and is marked with
+// Event.Overrides, so that accidental overrides are reported.
+func (v *validator) validateRedeclaration(event *Event, earlier []*Event) {
+	if event.objType() != earlier[0].objType() {
+		v.eventProblem(event.Name, "event is declared with a different event object type than its first declaration")
+	}
+	for _, other := range earlier {
+		if !v.orthogonal(event, other) {
+			v.validateOverlap(event, other)
+		}
+	}
+}
+
+// validateOverlap checks that two declarations of an event share no source state, and that if a source state of one is
+// a descendant of a source state of the other, or the other is from any state, the more specific declaration is marked
+// as an override.
+func (v *validator) validateOverlap(a, b *Event) {
+	for _, from := range sources(a) {
+		for _, other := range sources(b) {
+			switch {
+			case from == other:
+				v.eventProblem(a.Name, "event is declared more than once from %s", sourceName(from))
+				return
+			case v.gen.within(from, other) && !a.Override:
+				v.eventProblem(a.Name, "declaration from %s overrides the declaration from %s without being marked as an override",
+					sourceName(from), sourceName(other))
+				return
+			case v.gen.within(other, from) && !b.Override:
+				v.eventProblem(a.Name, "declaration from %s overrides the declaration from %s without being marked as an override",
+					sourceName(other), sourceName(from))
+				return
+			}
+		}
+	}
+}
+
+// validateOverrides checks that every declaration marked as an override does override another declaration.
+func (v *validator) validateOverrides() {
+	for _, event := range v.gen.Events {
+		if event == nil || !event.Override {
+			continue
+		}
+		overrides := false
+		for _, other := range v.gen.Events {
+			if other != nil && other != event && other.Name == event.Name {
+				_, _, ok := v.gen.overrides(event, other)
+				overrides = overrides || ok
+			}
+		}
+		if !overrides {
+			v.eventProblem(event.Name, "declaration from %s is marked as an override but does not override another declaration",
+				sourceNames(event))
+		}
+	}
+}
+
+// orthogonal returns whether every source state of one event declaration is in a different region of a parallel state
+// to every source state of the other.
+func (v *validator) orthogonal(a, b *Event) bool {
+	if len(a.FromStates) == 0 || len(b.FromStates) == 0 {
+		return false
+	}
+	for _, from := range a.FromStates {
+		for _, other := range b.FromStates {
+			if !v.gen.orthogonal(from, other) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sourceName describes a source state, which is any state when empty.
+func sourceName(state string) string {
+	if state == "" {
+		return "any state"
+	}
+	return fmt.Sprintf("state %q", state)
+}
+
+// sourceNames describes every source state of an event declaration.
+func sourceNames(event *Event) string {
+	names := []string{}
+	for _, state := range sources(event) {
+		names = append(names, sourceName(state))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,216 @@
+package fsmgen
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type testState struct{}
+type testEnv struct{}
+type testEvent struct{}
+
+func TestValidateValid(t *testing.T) {
+	gen := New("valid", testState{}, testEnv{}, "init", "running", "final")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").To("running"))
+	gen.AddEvent(NewEvent("finish", testEvent{}).From("running").To("final"))
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("init"))
+	assert.NilError(t, gen.Validate())
+}
+
+func TestValidateNoStates(t *testing.T) {
+	gen := New("empty", testState{}, testEnv{})
+	err := gen.Validate()
+	var verr *ValidationError
+	assert.Assert(t, errors.As(err, &verr))
+	assert.Equal(t, 1, len(verr.Problems))
+	assert.ErrorContains(t, gen.Write(), "machine has no states")
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+	gen := New("broken", testState{}, testEnv{}, "init", "running", "init", "Running")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init", "unknown").To("missing"))
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").To("running"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("running"))
+	gen.AddEvent(NewEvent("untyped", nil).FromAny().To("init"))
+
+	err := gen.Validate()
+	var verr *ValidationError
+	assert.Assert(t, errors.As(err, &verr))
+	assert.DeepEqual(t, []*Problem{
+		{State: "init", Message: "state is declared more than once"},
+		{State: "Running", Message: `state generates the same identifier "Running" as state "running"`},
+		{Event: "run", Message: `source state "unknown" is not a known state`},
+		{Event: "run", Message: `target state "missing" is not a known state`},
+		{Event: "run", Message: `event is declared more than once from state "init"`},
+		{Event: "stop", Message: "event has no target state"},
+		{Event: "untyped", Message: "event has no event object type"},
+	}, verr.Problems)
+}
+
+func TestValidateGeneratedTypeNameCollision(t *testing.T) {
+	gen := New("test", testState{}, "TestState", "init")
+	assert.Error(t, gen.Validate(), `invalid state machine "test": 1 problem(s)
+	environment object type collides with a generated type name`)
+}
+
+func TestValidateBranches(t *testing.T) {
+	gen := New("branches", testState{}, testEnv{}, "init", "running")
+	gen.AddEvent(NewEvent("run", testEvent{}).From("init").Branch("ready", "running").Branch("", "missing"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("running").Guard("stoppable"))
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{Event: "run", Message: `branch to "missing" has no guard`},
+		{Event: "run", Message: `branch target state "missing" is not a known state`},
+		{Event: "stop", Message: `guard "stoppable" has no target state`},
+	}, verr.Problems)
+}
+
+func TestValidateSubstates(t *testing.T) {
+	gen := New("nested", testState{}, testEnv{}, "idle", "active", "running", "paused", "empty")
+	gen.AddSubstates("active", "running", "paused", "missing")
+	gen.AddSubstates("running", "active")
+	gen.AddSubstates("paused", "running")
+	gen.AddSubstates("empty")
+	gen.AddSubstates("unknown", "idle")
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{State: "active", Message: `child state "missing" is not a known state`},
+		{State: "running", Message: `state is a child of both "active" and "paused"`},
+		{State: "empty", Message: "compound state has no child states"},
+		{State: "unknown", Message: "compound state is not a known state"},
+		{State: "active", Message: "state is its own ancestor"},
+		{State: "running", Message: "state is its own ancestor"},
+	}, verr.Problems)
+}
+
+func TestValidateRegions(t *testing.T) {
+	gen := New("device", testState{}, testEnv{}, "on", "network", "offline", "online", "playback", "stopped", "playing")
+	gen.AddRegions("on", "network", "playback")
+	gen.AddSubstates("network", "offline", "online")
+	gen.AddSubstates("playback", "stopped", "playing")
+	gen.AddEvent(NewEvent("suspend", testEvent{}).From("online").To("offline"))
+	gen.AddEvent(NewEvent("suspend", testEvent{}).From("playing").To("stopped"))
+	assert.NilError(t, gen.Validate())
+
+	gen.AddEvent(NewEvent("suspend", testEvent{}).From("offline", "online").To("offline"))
+	gen.AddEvent(NewEvent("suspend", testState{}).From("stopped").To("playing"))
+	gen.ParallelStates["playing"] = true
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{State: "playing", Message: "parallel state has no regions"},
+		{Event: "suspend", Message: `event is declared more than once from state "online"`},
+		{Event: "suspend", Message: "event is declared with a different event object type than its first declaration"},
+	}, verr.Problems)
+}
+
+func TestValidateOverrides(t *testing.T) {
+	gen := New("player", testState{}, testEnv{}, "idle", "active", "playing", "paused")
+	gen.AddSubstates("active", "playing", "paused")
+	gen.AddEvent(NewEvent("stop", testEvent{}).FromAny().To("idle"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("active").To("paused").Overrides())
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("paused").To("idle").Overrides())
+	// Declarations from disjoint source states neither overlap nor override each other.
+	gen.AddEvent(NewEvent("play", testEvent{}).From("paused").To("playing"))
+	gen.AddEvent(NewEvent("play", testEvent{}).From("idle").To("active"))
+	assert.NilError(t, gen.Validate())
+
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("playing").To("paused"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("idle").To("active"))
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("playing").To("paused").Overrides())
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("idle"))
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("active"))
+	// A declaration from a child of another declaration's source state is an override too.
+	gen.AddEvent(NewEvent("skip", testEvent{}).From("active").To("idle"))
+	gen.AddEvent(NewEvent("skip", testEvent{}).From("paused").To("playing"))
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{Event: "stop", Message: `declaration from state "playing" overrides the declaration from any state without being marked as an override`},
+		{Event: "stop", Message: `declaration from state "playing" overrides the declaration from state "active" without being marked as an override`},
+		{Event: "stop", Message: `declaration from state "idle" overrides the declaration from any state without being marked as an override`},
+		{Event: "reset", Message: "event is declared more than once from any state"},
+		{Event: "skip", Message: `declaration from state "paused" overrides the declaration from state "active" without being marked as an override`},
+		{Event: "pause", Message: `declaration from state "playing" is marked as an override but does not override another declaration`},
+	}, verr.Problems)
+}
+
+func TestValidateInternal(t *testing.T) {
+	gen := New("player", testState{}, testEnv{}, "idle", "playing")
+	gen.AddEvent(NewEvent("seek", testEvent{}).From("playing").Internal())
+	gen.AddEvent(NewEvent("restart", testEvent{}).From("playing").To("playing").Internal())
+	gen.AddEvent(NewEvent("volume", testEvent{}).FromAny().Guard("unmuted").Internal())
+	assert.NilError(t, gen.Validate())
+
+	gen.AddEvent(NewEvent("play", testEvent{}).From("idle").To("playing").Internal())
+	gen.AddEvent(NewEvent("skip", testEvent{}).FromAny().To("idle").Internal())
+	gen.AddEvent(NewEvent("load", testEvent{}).From("idle").Branch("ready", "idle").Internal())
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{Event: "play", Message: `internal event target "playing" is not its source state "idle"`},
+		{Event: "skip", Message: "internal event from any state cannot have a target state"},
+		{Event: "load", Message: "internal event cannot have branches"},
+	}, verr.Problems)
+}
+
+func TestValidateHistories(t *testing.T) {
+	gen := New("player", testState{}, testEnv{}, "idle", "active", "playing", "paused")
+	gen.AddSubstates("active", "playing", "paused")
+	gen.ShallowHistory("active_history", "active", "playing")
+	gen.AddEvent(NewEvent("resume", testEvent{}).From("idle").To("active_history"))
+	assert.NilError(t, gen.Validate())
+
+	gen.DeepHistory("Idle", "active", "playing")
+	gen.DeepHistory("idle_history", "idle", "idle")
+	gen.ShallowHistory("no_default", "active", "")
+	gen.ShallowHistory("outside", "active", "idle")
+	gen.AddEvent(NewEvent("pause", testEvent{}).From("active_history").To("paused"))
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{State: "Idle", Message: `history generates the same identifier "Idle" as "idle"`},
+		{State: "idle_history", Message: `history parent "idle" is not a compound state`},
+		{State: "no_default", Message: "history has no default state"},
+		{State: "outside", Message: `history default state "idle" is not a descendant of "active"`},
+		{Event: "pause", Message: `source state "active_history" is not a known state`},
+	}, verr.Problems)
+}
+
+func TestValidateDelays(t *testing.T) {
+	gen := New("player", testState{}, testEnv{}, "init", "loading")
+	gen.AddEvent(NewEvent("timeout", testEvent{}).From("loading").To("init").After(30 * time.Second))
+	gen.AddEvent(NewEvent("reset", testEvent{}).FromAny().To("init").After(time.Second))
+	gen.AddEvent(NewEvent("rewind", testEvent{}).From("loading").To("init").After(-time.Second))
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{Event: "reset", Message: "delayed event has no source states to schedule it from"},
+		{Event: "rewind", Message: "delay -1s is negative"},
+	}, verr.Problems)
+}
+
+func TestValidateFinalStates(t *testing.T) {
+	gen := New("job", testState{}, testEnv{}, "init", "running", "working", "finished", "done", "stopped")
+	gen.AddSubstates("running", "working", "finished")
+	gen.Final("finished", "done", "running", "unknown")
+	gen.AddEvent(NewEvent("complete", testEvent{}).From("running").To("done").OnCompletion())
+	gen.AddEvent(NewEvent("restart", testEvent{}).From("done").To("init"))
+	gen.AddEvent(NewEvent("stop", testEvent{}).From("init").To("stopped").OnCompletion())
+	gen.AddEvent(NewEvent("abort", testEvent{}).FromAny().To("stopped").OnCompletion())
+	var verr *ValidationError
+	assert.Assert(t, errors.As(gen.Validate(), &verr))
+	assert.DeepEqual(t, []*Problem{
+		{State: "running", Message: "final state cannot have child states"},
+		{State: "unknown", Message: "final state is not a known state"},
+		{Event: "complete", Message: `source state "running" is a final state, which cannot have outgoing transitions`},
+		{Event: "restart", Message: `source state "done" is a final state, which cannot have outgoing transitions`},
+		{Event: "stop", Message: `source state "init" of completion event never completes`},
+		{Event: "abort", Message: "completion event has no source states to complete"},
+	}, verr.Problems)
+}